@@ -0,0 +1,140 @@
+package wtf
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Anomaly detection defaults.
+const (
+	// DefaultAnomalyAlpha is the weight given to each new value in the
+	// rolling baseline. Higher values adapt to changes more quickly.
+	DefaultAnomalyAlpha = 0.2
+
+	// DefaultAnomalyThreshold is the number of standard deviations a value
+	// must be from the baseline to be flagged.
+	DefaultAnomalyThreshold = 3.0
+
+	// DefaultAnomalyWarmup is the number of values observed before a
+	// baseline is trusted enough to flag anomalies.
+	DefaultAnomalyWarmup = 10
+
+	// DefaultAnomalyMinStdDev is the smallest standard deviation used to
+	// score values. This prevents small changes to a dial which has held a
+	// steady level from being flagged.
+	DefaultAnomalyMinStdDev = 5.0
+)
+
+// DialAnomaly represents a dial value which deviated abnormally from the
+// rolling baseline of the dial's own history.
+type DialAnomaly struct {
+	ID int `json:"id"`
+
+	// Dial which recorded the value.
+	DialID int `json:"dialID"`
+
+	// Value which was flagged.
+	Value int `json:"value"`
+
+	// Mean & standard deviation of the baseline before the value was seen.
+	Baseline float64 `json:"baseline"`
+	StdDev   float64 `json:"stddev"`
+
+	// Number of standard deviations between the value & the baseline.
+	// Negative if the value dropped below the baseline.
+	Score float64 `json:"score"`
+
+	// Time the value was recorded.
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// DialAnomalyService represents a service for finding detected anomalies.
+// Anomalies are detected & stored as dial values change.
+type DialAnomalyService interface {
+	// Retrieves a list of anomalies based on a filter, ordered by time. Only
+	// returns anomalies for dials that the user owns or is a member of. Also
+	// returns a count of total matching anomalies which may differ from the
+	// number of returned anomalies if the "Limit" field is set.
+	FindDialAnomalies(ctx context.Context, filter DialAnomalyFilter) ([]*DialAnomaly, int, error)
+}
+
+// DialAnomalyFilter represents a filter used by FindDialAnomalies().
+type DialAnomalyFilter struct {
+	// Filtering fields.
+	DialID *int `json:"dialID"`
+
+	// Restrict to anomalies recorded at or after a given time.
+	Since *time.Time `json:"since"`
+
+	// Restrict to subset of range.
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+}
+
+// DialBaseline represents the rolling baseline of a dial's values. The mean
+// & variance are exponentially weighted so recent values count for more.
+type DialBaseline struct {
+	Mean     float64
+	Variance float64
+
+	// Number of values observed by the baseline.
+	N int
+}
+
+// StdDev returns the standard deviation of the baseline.
+func (b *DialBaseline) StdDev() float64 {
+	return math.Sqrt(b.Variance)
+}
+
+// AnomalyDetector flags values which deviate from a dial's rolling baseline.
+// Values are scored by the number of standard deviations they are from the
+// exponentially weighted moving average (EWMA) of previous values.
+type AnomalyDetector struct {
+	// Weight given to each new value in the baseline, between 0 & 1.
+	Alpha float64
+
+	// Minimum absolute score for a value to be flagged.
+	Threshold float64
+
+	// Number of values observed before anomalies are flagged.
+	Warmup int
+
+	// Smallest standard deviation used when scoring values.
+	MinStdDev float64
+}
+
+// NewAnomalyDetector returns a new instance of AnomalyDetector with defaults.
+func NewAnomalyDetector() *AnomalyDetector {
+	return &AnomalyDetector{
+		Alpha:     DefaultAnomalyAlpha,
+		Threshold: DefaultAnomalyThreshold,
+		Warmup:    DefaultAnomalyWarmup,
+		MinStdDev: DefaultAnomalyMinStdDev,
+	}
+}
+
+// Observe scores value against the baseline and then adds the value to the
+// baseline. Returns true if the value is an anomaly. Values are never
+// flagged until the baseline has observed at least Warmup values.
+func (d *AnomalyDetector) Observe(b *DialBaseline, value int) (score float64, anomalous bool) {
+	diff := float64(value) - b.Mean
+
+	// The first value seeds the baseline.
+	if b.N == 0 {
+		b.Mean, b.Variance, b.N = float64(value), 0, 1
+		return 0, false
+	}
+
+	// Score against the baseline before it includes the new value.
+	score = diff / math.Max(b.StdDev(), d.MinStdDev)
+	anomalous = b.N >= d.Warmup && math.Abs(score) >= d.Threshold
+
+	// Update the exponentially weighted mean & variance.
+	incr := d.Alpha * diff
+	b.Mean += incr
+	b.Variance = (1 - d.Alpha) * (b.Variance + diff*incr)
+	b.N++
+
+	return score, anomalous
+}
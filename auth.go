@@ -104,4 +104,9 @@ type AuthFilter struct {
 	// Can be used for pagination.
 	Offset int `json:"offset"`
 	Limit  int `json:"limit"`
+
+	// Restrict to results after or before an opaque cursor returned from a
+	// previous page. Only one may be set.
+	After  string `json:"after"`
+	Before string `json:"before"`
 }
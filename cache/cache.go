@@ -0,0 +1,259 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// DefaultTTL is the default time that a cached result is kept.
+const DefaultTTL = 30 * time.Second
+
+// Cache metrics. The hit rate for each method can be computed by dividing
+// hits by the sum of hits & misses.
+var (
+	hitCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "wtf_cache_hit_count",
+		Help: "Total number of cache hits by method",
+	}, []string{"method"})
+
+	missCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "wtf_cache_miss_count",
+		Help: "Total number of cache misses by method",
+	}, []string{"method"})
+
+	invalidationCount = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "wtf_cache_invalidation_count",
+		Help: "Total number of cached results removed before expiring",
+	})
+)
+
+// Cache represents a store of service results for each user.
+//
+// Each result records the dials it was computed from so that changes to
+// a dial only remove results which could include the dial. Results such as
+// dial lists & reports which depend on every dial the user belongs to are
+// removed whenever any of those dials change.
+//
+// Cached results are shared between callers and must not be modified.
+type Cache struct {
+	mu          sync.Mutex
+	entries     map[key]*entry
+	users       map[int]map[key]struct{} // keys by user ID
+	dials       map[int]map[key]struct{} // keys by dial ID
+	memberships map[int]int              // dial IDs by membership ID
+	purgedAt    time.Time                // last removal of expired entries
+	stats       Stats
+
+	// Duration that results are cached for. Defaults to DefaultTTL.
+	TTL time.Duration
+
+	// Returns the current time. Defaults to time.Now().
+	// Can be mocked for tests.
+	Now func() time.Time
+}
+
+// NewCache returns a new instance of Cache.
+func NewCache() *Cache {
+	return &Cache{
+		entries:     make(map[key]*entry),
+		users:       make(map[int]map[key]struct{}),
+		dials:       make(map[int]map[key]struct{}),
+		memberships: make(map[int]int),
+
+		TTL: DefaultTTL,
+		Now: time.Now,
+	}
+}
+
+// Stats represents counters for cache usage.
+type Stats struct {
+	Hits          int
+	Misses        int
+	Invalidations int
+}
+
+// HitRate returns the fraction of lookups which were served from the cache.
+func (s Stats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// Stats returns the usage counters for the cache.
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// key represents a unique identifier for a cached result.
+type key struct {
+	userID int
+	method string
+	args   string
+}
+
+// entry represents a cached result.
+type entry struct {
+	value     interface{}
+	expiresAt time.Time
+	dialIDs   []int // dials the result was computed from
+	allDials  bool  // if true, depends on every dial the user belongs to
+}
+
+// userIDFromContext returns the user ID that results are cached under.
+// Returns zero if results should not be cached, such as for anonymous or
+// administrative requests which bypass the normal permission checks.
+func userIDFromContext(ctx context.Context) int {
+	if wtf.IsAdminContext(ctx) {
+		return 0
+	}
+	return wtf.UserIDFromContext(ctx)
+}
+
+// get returns the cached value for k, if it exists and has not expired.
+func (c *Cache) get(k key) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e := c.entries[k]; e != nil && c.Now().Before(e.expiresAt) {
+		c.stats.Hits++
+		hitCount.WithLabelValues(k.method).Inc()
+		return e.value, true
+	}
+
+	c.stats.Misses++
+	missCount.WithLabelValues(k.method).Inc()
+	return nil, false
+}
+
+// set adds a value to the cache which depends on the given dials. If allDials
+// is true then the value depends on every dial the user belongs to.
+func (c *Cache) set(k key, value interface{}, dialIDs []int, allDials bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.Now()
+	c.remove(k)
+	c.entries[k] = &entry{
+		value:     value,
+		expiresAt: now.Add(c.TTL),
+		dialIDs:   dialIDs,
+		allDials:  allDials,
+	}
+	addKey(c.users, k.userID, k)
+	for _, dialID := range dialIDs {
+		addKey(c.dials, dialID, k)
+	}
+
+	// Periodically remove expired entries. Report keys include their time
+	// range so they would otherwise accumulate as time moves forward.
+	if now.Sub(c.purgedAt) >= c.TTL {
+		for k, e := range c.entries {
+			if !now.Before(e.expiresAt) {
+				c.remove(k)
+			}
+		}
+		c.purgedAt = now
+	}
+}
+
+// setMembershipDialID records the dial that a membership belongs to so that
+// membership events can be traced back to the dial.
+func (c *Cache) setMembershipDialID(membershipID, dialID int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.memberships[membershipID] = dialID
+}
+
+// membershipDialID returns the dial ID of a previously seen membership.
+func (c *Cache) membershipDialID(membershipID int) (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	dialID, ok := c.memberships[membershipID]
+	return dialID, ok
+}
+
+// InvalidateUser removes all cached results for a user.
+func (c *Cache) InvalidateUser(userID int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k := range c.users[userID] {
+		c.invalidate(k)
+	}
+}
+
+// InvalidateUserDial removes a user's cached results which depend on a dial.
+// This includes results which depend on all of the user's dials.
+func (c *Cache) InvalidateUserDial(userID, dialID int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k := range c.users[userID] {
+		if e := c.entries[k]; e.allDials || containsInt(e.dialIDs, dialID) {
+			c.invalidate(k)
+		}
+	}
+}
+
+// InvalidateDial removes all cached results for any user which include a dial.
+func (c *Cache) InvalidateDial(dialID int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k := range c.dials[dialID] {
+		c.invalidate(k)
+	}
+}
+
+// invalidate removes an entry before it has expired.
+func (c *Cache) invalidate(k key) {
+	c.stats.Invalidations++
+	invalidationCount.Inc()
+	c.remove(k)
+}
+
+// remove deletes an entry and its references from the indexes.
+func (c *Cache) remove(k key) {
+	e := c.entries[k]
+	if e == nil {
+		return
+	}
+	delete(c.entries, k)
+	removeKey(c.users, k.userID, k)
+	for _, dialID := range e.dialIDs {
+		removeKey(c.dials, dialID, k)
+	}
+}
+
+// addKey adds k to the set of keys under id.
+func addKey(m map[int]map[key]struct{}, id int, k key) {
+	keys := m[id]
+	if keys == nil {
+		keys = make(map[key]struct{})
+		m[id] = keys
+	}
+	keys[k] = struct{}{}
+}
+
+// removeKey removes k from the set of keys under id.
+func removeKey(m map[int]map[key]struct{}, id int, k key) {
+	delete(m[id], k)
+	if len(m[id]) == 0 {
+		delete(m, id)
+	}
+}
+
+// containsInt returns true if v is in a.
+func containsInt(a []int, v int) bool {
+	for i := range a {
+		if a[i] == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,219 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// Ensure type implements interface.
+var _ wtf.DialService = (*DialService)(nil)
+
+// DialService wraps a wtf.DialService and caches read results per user.
+// Writes made through the service invalidate affected results immediately.
+// Changes made elsewhere are observed through the EventService wrapper.
+type DialService struct {
+	cache   *Cache
+	service wtf.DialService
+}
+
+// NewDialService returns a new instance of DialService which caches results
+// from service in c.
+func NewDialService(c *Cache, service wtf.DialService) *DialService {
+	return &DialService{cache: c, service: service}
+}
+
+// FindDialByID retrieves a single dial by ID along with associated memberships.
+func (s *DialService) FindDialByID(ctx context.Context, id int) (*wtf.Dial, error) {
+	userID := userIDFromContext(ctx)
+	if userID == 0 {
+		return s.service.FindDialByID(ctx, id)
+	}
+
+	// Callers may replace top-level fields, such as the memberships, so each
+	// caller receives its own copy of the cached dial.
+	k := key{userID: userID, method: "FindDialByID", args: fmt.Sprint(id)}
+	if v, ok := s.cache.get(k); ok {
+		other := *v.(*wtf.Dial)
+		return &other, nil
+	}
+
+	dial, err := s.service.FindDialByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, membership := range dial.Memberships {
+		s.cache.setMembershipDialID(membership.ID, dial.ID)
+	}
+	other := *dial
+	s.cache.set(k, &other, []int{dial.ID}, false)
+	return dial, nil
+}
+
+// findDialsResult represents the cached result of FindDials().
+type findDialsResult struct {
+	dials []*wtf.Dial
+	n     int
+}
+
+// FindDials retrieves a list of dials based on a filter. Lookups by invite
+// code, share token or badge token are not cached as they are performed by
+// users who are not members.
+func (s *DialService) FindDials(ctx context.Context, filter wtf.DialFilter) ([]*wtf.Dial, int, error) {
+	userID := userIDFromContext(ctx)
+	if userID == 0 || filter.InviteCode != nil || filter.ShareToken != nil || filter.BadgeToken != nil {
+		return s.service.FindDials(ctx, filter)
+	}
+
+	k := key{userID: userID, method: "FindDials", args: formatDialFilter(filter)}
+	if v, ok := s.cache.get(k); ok {
+		result := v.(*findDialsResult)
+		return result.dials, result.n, nil
+	}
+
+	dials, n, err := s.service.FindDials(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	dialIDs := make([]int, len(dials))
+	for i, dial := range dials {
+		dialIDs[i] = dial.ID
+	}
+	s.cache.set(k, &findDialsResult{dials: dials, n: n}, dialIDs, true)
+	return dials, n, nil
+}
+
+// CreateDial creates a new dial and invalidates the owner's dial lists.
+func (s *DialService) CreateDial(ctx context.Context, dial *wtf.Dial) error {
+	if err := s.service.CreateDial(ctx, dial); err != nil {
+		return err
+	}
+	s.cache.InvalidateUserDial(dial.UserID, dial.ID)
+	return nil
+}
+
+// UpdateDial updates an existing dial and invalidates results for all users
+// which include the dial.
+func (s *DialService) UpdateDial(ctx context.Context, id int, upd wtf.DialUpdate) (*wtf.Dial, error) {
+	dial, err := s.service.UpdateDial(ctx, id, upd)
+	s.cache.InvalidateDial(id)
+	return dial, err
+}
+
+// DeleteDial permanently removes a dial and invalidates the results of every
+// member. Members are looked up before deletion as they are removed with it.
+func (s *DialService) DeleteDial(ctx context.Context, id int) error {
+	var userIDs []int
+	if dial, err := s.service.FindDialByID(ctx, id); err == nil {
+		for _, membership := range dial.Memberships {
+			userIDs = append(userIDs, membership.UserID)
+		}
+	}
+
+	if err := s.service.DeleteDial(ctx, id); err != nil {
+		return err
+	}
+
+	s.cache.InvalidateDial(id)
+	for _, userID := range userIDs {
+		s.cache.InvalidateUserDial(userID, id)
+	}
+	return nil
+}
+
+// SetDialMembershipValue sets the value of the user's membership in a dial.
+//
+// Other members are notified through events but the current user's results
+// are invalidated here so the change is visible on their next request.
+func (s *DialService) SetDialMembershipValue(ctx context.Context, dialID, value int) error {
+	err := s.service.SetDialMembershipValue(ctx, dialID, value)
+	s.cache.InvalidateUserDial(wtf.UserIDFromContext(ctx), dialID)
+	return err
+}
+
+// AverageDialValueReport returns a report of the average dial value across
+// all dials that the user is a member of.
+func (s *DialService) AverageDialValueReport(ctx context.Context, start, end time.Time, interval wtf.ReportInterval) (*wtf.DialValueReport, error) {
+	userID := userIDFromContext(ctx)
+	if userID == 0 {
+		return s.service.AverageDialValueReport(ctx, start, end, interval)
+	}
+
+	k := key{userID: userID, method: "AverageDialValueReport", args: formatReportArgs(start, end, interval)}
+	if v, ok := s.cache.get(k); ok {
+		return v.(*wtf.DialValueReport), nil
+	}
+
+	report, err := s.service.AverageDialValueReport(ctx, start, end, interval)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.set(k, report, nil, true)
+	return report, nil
+}
+
+// DialValueReport returns a report of the value of a single dial.
+func (s *DialService) DialValueReport(ctx context.Context, id int, start, end time.Time, interval wtf.ReportInterval) (*wtf.DialValueReport, error) {
+	userID := userIDFromContext(ctx)
+	if userID == 0 {
+		return s.service.DialValueReport(ctx, id, start, end, interval)
+	}
+
+	k := key{userID: userID, method: "DialValueReport", args: fmt.Sprintf("%d %s", id, formatReportArgs(start, end, interval))}
+	if v, ok := s.cache.get(k); ok {
+		return v.(*wtf.DialValueReport), nil
+	}
+
+	report, err := s.service.DialValueReport(ctx, id, start, end, interval)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.set(k, report, []int{id}, false)
+	return report, nil
+}
+
+// ImportDials creates dials from a list of import rows and invalidates the
+// current user's results if any dials were created.
+func (s *DialService) ImportDials(ctx context.Context, rows []*wtf.DialImportRow, opt wtf.DialImportOptions) (*wtf.DialImportResult, error) {
+	result, err := s.service.ImportDials(ctx, rows, opt)
+	if !opt.DryRun {
+		s.cache.InvalidateUser(wtf.UserIDFromContext(ctx))
+	}
+	return result, err
+}
+
+// formatDialFilter returns a string representation of filter for use in a key.
+func formatDialFilter(filter wtf.DialFilter) string {
+	var updatedSince string
+	if filter.UpdatedSince != nil {
+		updatedSince = fmt.Sprint(filter.UpdatedSince.UnixNano())
+	}
+
+	var query string
+	if filter.Query != nil {
+		query = *filter.Query
+	}
+
+	return fmt.Sprintf("id=%s userID=%s query=%q minValue=%s maxValue=%s updatedSince=%s offset=%d limit=%d after=%q before=%q sortBy=%q",
+		formatIntPtr(filter.ID), formatIntPtr(filter.UserID), query,
+		formatIntPtr(filter.MinValue), formatIntPtr(filter.MaxValue), updatedSince,
+		filter.Offset, filter.Limit, filter.After, filter.Before, filter.SortBy,
+	)
+}
+
+// formatReportArgs returns a string representation of report arguments for
+// use in a key.
+func formatReportArgs(start, end time.Time, interval wtf.ReportInterval) string {
+	return fmt.Sprintf("%d %d %s %s", start.UnixNano(), end.UnixNano(), interval, interval.Location)
+}
+
+// formatIntPtr returns the value of v as a string or "nil" if v is nil.
+func formatIntPtr(v *int) string {
+	if v == nil {
+		return "nil"
+	}
+	return fmt.Sprint(*v)
+}
@@ -0,0 +1,164 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// Ensure type implements interface.
+var _ wtf.DialMembershipService = (*DialMembershipService)(nil)
+
+// DialMembershipService wraps a wtf.DialMembershipService and caches read
+// results per user. Writes made through the service invalidate affected
+// results immediately.
+type DialMembershipService struct {
+	cache   *Cache
+	service wtf.DialMembershipService
+}
+
+// NewDialMembershipService returns a new instance of DialMembershipService
+// which caches results from service in c.
+func NewDialMembershipService(c *Cache, service wtf.DialMembershipService) *DialMembershipService {
+	return &DialMembershipService{cache: c, service: service}
+}
+
+// FindDialMembershipByID retrieves a membership by ID.
+func (s *DialMembershipService) FindDialMembershipByID(ctx context.Context, id int) (*wtf.DialMembership, error) {
+	userID := userIDFromContext(ctx)
+	if userID == 0 {
+		return s.service.FindDialMembershipByID(ctx, id)
+	}
+
+	k := key{userID: userID, method: "FindDialMembershipByID", args: fmt.Sprint(id)}
+	if v, ok := s.cache.get(k); ok {
+		return v.(*wtf.DialMembership), nil
+	}
+
+	membership, err := s.service.FindDialMembershipByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.setMembershipDialID(membership.ID, membership.DialID)
+	s.cache.set(k, membership, []int{membership.DialID}, false)
+	return membership, nil
+}
+
+// findDialMembershipsResult represents the cached result of FindDialMemberships().
+type findDialMembershipsResult struct {
+	memberships []*wtf.DialMembership
+	n           int
+}
+
+// FindDialMemberships retrieves a list of matching memberships based on filter.
+//
+// Results depend on all of the user's dials since a change to any membership
+// can move it into a sorted or limited result.
+func (s *DialMembershipService) FindDialMemberships(ctx context.Context, filter wtf.DialMembershipFilter) ([]*wtf.DialMembership, int, error) {
+	userID := userIDFromContext(ctx)
+	if userID == 0 {
+		return s.service.FindDialMemberships(ctx, filter)
+	}
+
+	k := key{userID: userID, method: "FindDialMemberships", args: formatDialMembershipFilter(filter)}
+	if v, ok := s.cache.get(k); ok {
+		result := v.(*findDialMembershipsResult)
+		return result.memberships, result.n, nil
+	}
+
+	memberships, n, err := s.service.FindDialMemberships(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	dialIDs := make([]int, len(memberships))
+	for i, membership := range memberships {
+		s.cache.setMembershipDialID(membership.ID, membership.DialID)
+		dialIDs[i] = membership.DialID
+	}
+	s.cache.set(k, &findDialMembershipsResult{memberships: memberships, n: n}, dialIDs, true)
+	return memberships, n, nil
+}
+
+// CreateDialMembership creates a new membership on a dial for the current
+// user and invalidates the results of all members of the dial.
+func (s *DialMembershipService) CreateDialMembership(ctx context.Context, membership *wtf.DialMembership) error {
+	if err := s.service.CreateDialMembership(ctx, membership); err != nil {
+		return err
+	}
+	s.cache.setMembershipDialID(membership.ID, membership.DialID)
+
+	// The new member can now see the other members of the dial.
+	s.invalidateDialMembers(s.dialMemberIDs(ctx, membership.DialID), membership.DialID)
+	s.cache.InvalidateUserDial(membership.UserID, membership.DialID)
+	return nil
+}
+
+// UpdateDialMembership updates the value of a membership.
+//
+// Other members are notified through events but the current user's results
+// are invalidated here so the change is visible on their next request.
+func (s *DialMembershipService) UpdateDialMembership(ctx context.Context, id int, upd wtf.DialMembershipUpdate) (*wtf.DialMembership, error) {
+	membership, err := s.service.UpdateDialMembership(ctx, id, upd)
+	if membership != nil {
+		s.cache.InvalidateUserDial(wtf.UserIDFromContext(ctx), membership.DialID)
+	}
+	return membership, err
+}
+
+// DeleteDialMembership permanently deletes a membership by ID and invalidates
+// the results of all members of the dial, including the removed member.
+func (s *DialMembershipService) DeleteDialMembership(ctx context.Context, id int) error {
+	// Look up the dial & its members before they are no longer visible.
+	var dialID int
+	var userIDs []int
+	if membership, err := s.service.FindDialMembershipByID(ctx, id); err == nil {
+		dialID = membership.DialID
+		userIDs = s.dialMemberIDs(ctx, dialID)
+	}
+
+	if err := s.service.DeleteDialMembership(ctx, id); err != nil {
+		return err
+	}
+
+	if dialID == 0 {
+		s.cache.InvalidateUser(wtf.UserIDFromContext(ctx))
+		return nil
+	}
+	s.invalidateDialMembers(userIDs, dialID)
+	return nil
+}
+
+// dialMemberIDs returns the IDs of all users who are members of a dial.
+// Returns nil if the members cannot be read by the current user.
+func (s *DialMembershipService) dialMemberIDs(ctx context.Context, dialID int) []int {
+	memberships, _, err := s.service.FindDialMemberships(ctx, wtf.DialMembershipFilter{DialID: &dialID})
+	if err != nil {
+		return nil
+	}
+
+	userIDs := make([]int, len(memberships))
+	for i, membership := range memberships {
+		userIDs[i] = membership.UserID
+	}
+	return userIDs
+}
+
+// invalidateDialMembers removes results which depend on the dial for each
+// user, as well as results which include the dial for any user.
+func (s *DialMembershipService) invalidateDialMembers(userIDs []int, dialID int) {
+	s.cache.InvalidateDial(dialID)
+	for _, userID := range userIDs {
+		s.cache.InvalidateUserDial(userID, dialID)
+	}
+}
+
+// formatDialMembershipFilter returns a string representation of filter for
+// use in a key.
+func formatDialMembershipFilter(filter wtf.DialMembershipFilter) string {
+	return fmt.Sprintf("id=%s dialID=%s userID=%s offset=%d limit=%d after=%q before=%q sortBy=%q",
+		formatIntPtr(filter.ID), formatIntPtr(filter.DialID), formatIntPtr(filter.UserID),
+		filter.Offset, filter.Limit, filter.After, filter.Before, filter.SortBy,
+	)
+}
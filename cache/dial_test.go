@@ -0,0 +1,253 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/cache"
+	"github.com/benbjohnson/wtf/mock"
+)
+
+func TestDialService_FindDialByID(t *testing.T) {
+	// Ensure results are cached per user until they expire.
+	t.Run("OK", func(t *testing.T) {
+		c, now := NewCache()
+
+		var n int
+		var underlying mock.DialService
+		underlying.FindDialByIDFn = func(ctx context.Context, id int) (*wtf.Dial, error) {
+			n++
+			return &wtf.Dial{ID: id}, nil
+		}
+		s := cache.NewDialService(c, &underlying)
+
+		ctx0 := wtf.NewContextWithUser(context.Background(), &wtf.User{ID: 1})
+		ctx1 := wtf.NewContextWithUser(context.Background(), &wtf.User{ID: 2})
+
+		MustFindDialByID(t, ctx0, s, 100)
+		MustFindDialByID(t, ctx0, s, 100)
+		if n != 1 {
+			t.Fatalf("unexpected call count: %d", n)
+		}
+
+		// Results are not shared between users.
+		MustFindDialByID(t, ctx1, s, 100)
+		if n != 2 {
+			t.Fatalf("unexpected call count: %d", n)
+		}
+
+		// Results expire after the TTL.
+		*now = now.Add(c.TTL)
+		MustFindDialByID(t, ctx0, s, 100)
+		if n != 3 {
+			t.Fatalf("unexpected call count: %d", n)
+		}
+
+		if stats := c.Stats(); stats.Hits != 1 || stats.Misses != 3 {
+			t.Fatalf("unexpected stats: %#v", stats)
+		} else if got, want := stats.HitRate(), 0.25; got != want {
+			t.Fatalf("HitRate()=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure errors are not cached.
+	t.Run("ErrNotFound", func(t *testing.T) {
+		c, _ := NewCache()
+
+		var n int
+		var underlying mock.DialService
+		underlying.FindDialByIDFn = func(ctx context.Context, id int) (*wtf.Dial, error) {
+			n++
+			return nil, wtf.Errorf(wtf.ENOTFOUND, "Dial not found.")
+		}
+		s := cache.NewDialService(c, &underlying)
+
+		ctx := wtf.NewContextWithUser(context.Background(), &wtf.User{ID: 1})
+		for i := 0; i < 2; i++ {
+			if _, err := s.FindDialByID(ctx, 100); wtf.ErrorCode(err) != wtf.ENOTFOUND {
+				t.Fatalf("unexpected error: %#v", err)
+			}
+		}
+		if n != 2 {
+			t.Fatalf("unexpected call count: %d", n)
+		}
+	})
+
+	// Ensure administrative requests bypass the cache.
+	t.Run("Admin", func(t *testing.T) {
+		c, _ := NewCache()
+
+		var n int
+		var underlying mock.DialService
+		underlying.FindDialByIDFn = func(ctx context.Context, id int) (*wtf.Dial, error) {
+			n++
+			return &wtf.Dial{ID: id}, nil
+		}
+		s := cache.NewDialService(c, &underlying)
+
+		ctx := wtf.NewContextWithAdmin(wtf.NewContextWithUser(context.Background(), &wtf.User{ID: 1}))
+		MustFindDialByID(t, ctx, s, 100)
+		MustFindDialByID(t, ctx, s, 100)
+		if n != 2 {
+			t.Fatalf("unexpected call count: %d", n)
+		}
+	})
+}
+
+func TestDialService_UpdateDial(t *testing.T) {
+	// Ensure renaming a dial invalidates results for all users which include it.
+	t.Run("OK", func(t *testing.T) {
+		c, _ := NewCache()
+
+		var n int
+		var underlying mock.DialService
+		underlying.FindDialsFn = func(ctx context.Context, filter wtf.DialFilter) ([]*wtf.Dial, int, error) {
+			n++
+			return []*wtf.Dial{{ID: 100}}, 1, nil
+		}
+		underlying.FindDialByIDFn = func(ctx context.Context, id int) (*wtf.Dial, error) {
+			n++
+			return &wtf.Dial{ID: id}, nil
+		}
+		underlying.UpdateDialFn = func(ctx context.Context, id int, upd wtf.DialUpdate) (*wtf.Dial, error) {
+			return &wtf.Dial{ID: id}, nil
+		}
+		s := cache.NewDialService(c, &underlying)
+
+		ctx0 := wtf.NewContextWithUser(context.Background(), &wtf.User{ID: 1})
+		ctx1 := wtf.NewContextWithUser(context.Background(), &wtf.User{ID: 2})
+
+		MustFindDials(t, ctx0, s, wtf.DialFilter{})
+		MustFindDialByID(t, ctx1, s, 100)
+		MustFindDialByID(t, ctx1, s, 200)
+		if n != 3 {
+			t.Fatalf("unexpected call count: %d", n)
+		}
+
+		if _, err := s.UpdateDial(ctx0, 100, wtf.DialUpdate{}); err != nil {
+			t.Fatal(err)
+		}
+
+		// Results containing the dial are refetched. Unrelated results are not.
+		MustFindDials(t, ctx0, s, wtf.DialFilter{})
+		MustFindDialByID(t, ctx1, s, 100)
+		MustFindDialByID(t, ctx1, s, 200)
+		if n != 5 {
+			t.Fatalf("unexpected call count: %d", n)
+		}
+	})
+}
+
+func TestDialService_DeleteDial(t *testing.T) {
+	// Ensure deleting a dial invalidates the reports of all members.
+	t.Run("OK", func(t *testing.T) {
+		c, _ := NewCache()
+
+		var n int
+		var underlying mock.DialService
+		underlying.FindDialByIDFn = func(ctx context.Context, id int) (*wtf.Dial, error) {
+			return &wtf.Dial{ID: id, Memberships: []*wtf.DialMembership{{UserID: 1}, {UserID: 2}}}, nil
+		}
+		underlying.DeleteDialFn = func(ctx context.Context, id int) error {
+			return nil
+		}
+		underlying.AverageDialValueReportFn = func(ctx context.Context, start, end time.Time, interval wtf.ReportInterval) (*wtf.DialValueReport, error) {
+			n++
+			return &wtf.DialValueReport{}, nil
+		}
+		s := cache.NewDialService(c, &underlying)
+
+		ctx0 := wtf.NewContextWithUser(context.Background(), &wtf.User{ID: 1})
+		ctx1 := wtf.NewContextWithUser(context.Background(), &wtf.User{ID: 2})
+		ctx2 := wtf.NewContextWithUser(context.Background(), &wtf.User{ID: 3})
+		start, end := time.Unix(0, 0), time.Unix(3600, 0)
+
+		for _, ctx := range []context.Context{ctx0, ctx1, ctx2, ctx0, ctx1, ctx2} {
+			MustAverageDialValueReport(t, ctx, s, start, end, wtf.ReportInterval{Duration: time.Minute})
+		}
+		if n != 3 {
+			t.Fatalf("unexpected call count: %d", n)
+		}
+
+		if err := s.DeleteDial(ctx0, 100); err != nil {
+			t.Fatal(err)
+		}
+
+		// Only the members of the dial recompute their report.
+		for _, ctx := range []context.Context{ctx0, ctx1, ctx2} {
+			MustAverageDialValueReport(t, ctx, s, start, end, wtf.ReportInterval{Duration: time.Minute})
+		}
+		if n != 5 {
+			t.Fatalf("unexpected call count: %d", n)
+		}
+	})
+}
+
+func TestDialService_SetDialMembershipValue(t *testing.T) {
+	// Ensure the current user's results are invalidated immediately.
+	t.Run("OK", func(t *testing.T) {
+		c, _ := NewCache()
+
+		var n int
+		var underlying mock.DialService
+		underlying.FindDialByIDFn = func(ctx context.Context, id int) (*wtf.Dial, error) {
+			n++
+			return &wtf.Dial{ID: id}, nil
+		}
+		underlying.SetDialMembershipValueFn = func(ctx context.Context, dialID, value int) error {
+			return nil
+		}
+		s := cache.NewDialService(c, &underlying)
+
+		ctx := wtf.NewContextWithUser(context.Background(), &wtf.User{ID: 1})
+		MustFindDialByID(t, ctx, s, 100)
+		if err := s.SetDialMembershipValue(ctx, 100, 50); err != nil {
+			t.Fatal(err)
+		}
+		MustFindDialByID(t, ctx, s, 100)
+		if n != 2 {
+			t.Fatalf("unexpected call count: %d", n)
+		}
+	})
+}
+
+// NewCache returns a new cache with a mocked clock. The returned time can be
+// updated to move the clock forward.
+func NewCache() (*cache.Cache, *time.Time) {
+	now := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	c := cache.NewCache()
+	c.Now = func() time.Time { return now }
+	return c, &now
+}
+
+// MustFindDialByID finds a dial by ID. Fatal on error.
+func MustFindDialByID(tb testing.TB, ctx context.Context, s wtf.DialService, id int) *wtf.Dial {
+	tb.Helper()
+	dial, err := s.FindDialByID(ctx, id)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return dial
+}
+
+// MustFindDials finds a list of dials. Fatal on error.
+func MustFindDials(tb testing.TB, ctx context.Context, s wtf.DialService, filter wtf.DialFilter) []*wtf.Dial {
+	tb.Helper()
+	dials, _, err := s.FindDials(ctx, filter)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return dials
+}
+
+// MustAverageDialValueReport computes an average report. Fatal on error.
+func MustAverageDialValueReport(tb testing.TB, ctx context.Context, s wtf.DialService, start, end time.Time, interval wtf.ReportInterval) *wtf.DialValueReport {
+	tb.Helper()
+	report, err := s.AverageDialValueReport(ctx, start, end, interval)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return report
+}
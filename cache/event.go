@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// Ensure type implements interface.
+var _ wtf.EventService = (*EventService)(nil)
+
+// EventService wraps a wtf.EventService and invalidates cached results as
+// events are published. Events are published to each member of a dial so
+// only the results of the receiving user which depend on the dial are removed.
+//
+// Events must only be published once their change has been committed.
+// Otherwise a result read before the commit could be cached after it has
+// been invalidated.
+type EventService struct {
+	cache   *Cache
+	service wtf.EventService
+}
+
+// NewEventService returns a new instance of EventService which invalidates
+// results in c before forwarding events to service.
+func NewEventService(c *Cache, service wtf.EventService) *EventService {
+	return &EventService{cache: c, service: service}
+}
+
+// PublishEvent invalidates the user's affected results & publishes the event.
+func (s *EventService) PublishEvent(userID int, event wtf.Event) {
+	switch payload := event.Payload.(type) {
+	case *wtf.DialValueChangedPayload:
+		s.cache.InvalidateUserDial(userID, payload.ID)
+
+	case *wtf.DialMembershipValueChangedPayload:
+		// Remove all of the user's results if the membership's dial is unknown.
+		if dialID, ok := s.cache.membershipDialID(payload.ID); ok {
+			s.cache.InvalidateUserDial(userID, dialID)
+		} else {
+			s.cache.InvalidateUser(userID)
+		}
+	}
+
+	s.service.PublishEvent(userID, event)
+}
+
+// Subscribe creates a subscription on the underlying service.
+func (s *EventService) Subscribe(ctx context.Context) (wtf.Subscription, error) {
+	return s.service.Subscribe(ctx)
+}
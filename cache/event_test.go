@@ -0,0 +1,97 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/cache"
+	"github.com/benbjohnson/wtf/mock"
+)
+
+func TestEventService_PublishEvent(t *testing.T) {
+	// Ensure dial value events only invalidate the receiving user's results
+	// which depend on the dial.
+	t.Run("DialValueChanged", func(t *testing.T) {
+		c, _ := NewCache()
+
+		var n int
+		var dialService mock.DialService
+		dialService.FindDialByIDFn = func(ctx context.Context, id int) (*wtf.Dial, error) {
+			n++
+			return &wtf.Dial{ID: id}, nil
+		}
+		s := cache.NewDialService(c, &dialService)
+
+		var published int
+		eventService := cache.NewEventService(c, &mock.EventService{
+			PublishEventFn: func(userID int, event wtf.Event) { published++ },
+		})
+
+		ctx0 := wtf.NewContextWithUser(context.Background(), &wtf.User{ID: 1})
+		ctx1 := wtf.NewContextWithUser(context.Background(), &wtf.User{ID: 2})
+		MustFindDialByID(t, ctx0, s, 100)
+		MustFindDialByID(t, ctx0, s, 200)
+		MustFindDialByID(t, ctx1, s, 100)
+
+		eventService.PublishEvent(1, wtf.Event{
+			Type:    wtf.EventTypeDialValueChanged,
+			Payload: &wtf.DialValueChangedPayload{ID: 100, Value: 50},
+		})
+		if published != 1 {
+			t.Fatal("expected event to be forwarded")
+		}
+
+		MustFindDialByID(t, ctx0, s, 100)
+		MustFindDialByID(t, ctx0, s, 200)
+		MustFindDialByID(t, ctx1, s, 100)
+		if n != 4 {
+			t.Fatalf("unexpected call count: %d", n)
+		}
+	})
+
+	// Ensure membership value events are traced back to the membership's dial.
+	t.Run("DialMembershipValueChanged", func(t *testing.T) {
+		c, _ := NewCache()
+
+		var n int
+		var membershipService mock.DialMembershipService
+		membershipService.FindDialMembershipsFn = func(ctx context.Context, filter wtf.DialMembershipFilter) ([]*wtf.DialMembership, int, error) {
+			n++
+			return []*wtf.DialMembership{{ID: 10, DialID: *filter.DialID}}, 1, nil
+		}
+		s := cache.NewDialMembershipService(c, &membershipService)
+		eventService := cache.NewEventService(c, &mock.EventService{
+			PublishEventFn: func(userID int, event wtf.Event) {},
+		})
+
+		ctx := wtf.NewContextWithUser(context.Background(), &wtf.User{ID: 1})
+		dialID0, dialID1 := 100, 200
+		MustFindDialMemberships(t, ctx, s, wtf.DialMembershipFilter{DialID: &dialID0})
+		MustFindDialMemberships(t, ctx, s, wtf.DialMembershipFilter{DialID: &dialID1})
+		if n != 2 {
+			t.Fatalf("unexpected call count: %d", n)
+		}
+
+		// Lists depend on all of the user's dials so both are refetched.
+		eventService.PublishEvent(1, wtf.Event{
+			Type:    wtf.EventTypeDialMembershipValueChanged,
+			Payload: &wtf.DialMembershipValueChangedPayload{ID: 10, Value: 50},
+		})
+		MustFindDialMemberships(t, ctx, s, wtf.DialMembershipFilter{DialID: &dialID0})
+		MustFindDialMemberships(t, ctx, s, wtf.DialMembershipFilter{DialID: &dialID1})
+		if n != 4 {
+			t.Fatalf("unexpected call count: %d", n)
+		}
+	})
+}
+
+// MustFindDialMemberships finds a list of memberships. Fatal on error.
+func MustFindDialMemberships(tb testing.TB, ctx context.Context, s wtf.DialMembershipService, filter wtf.DialMembershipFilter) []*wtf.DialMembership {
+	tb.Helper()
+	memberships, _, err := s.FindDialMemberships(ctx, filter)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return memberships
+}
@@ -0,0 +1,325 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/http"
+)
+
+// CompletionCacheTTL is the amount of time that dials fetched for completion
+// are reused before being fetched from the server again.
+const CompletionCacheTTL = 30 * time.Second
+
+// Argument & flag value types which can be completed.
+const (
+	completeNone    = ""
+	completeDial    = "dial"
+	completeFormat  = "format"
+	completeProfile = "profile"
+	completeShell   = "shell"
+	completeValue   = "value" // flag requires a value but it cannot be completed
+)
+
+// completionNode represents a command in the command tree used for completion.
+type completionNode struct {
+	name        string
+	description string
+	flags       map[string]string // flag name to value type; completeNone for booleans
+	args        []string          // positional argument types
+	children    []*completionNode
+}
+
+// child returns the subcommand with the given name, if it exists.
+func (n *completionNode) child(name string) *completionNode {
+	for _, child := range n.children {
+		if child.name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// Flags shared by most commands.
+var (
+	configFlags = map[string]string{"config": completeValue, "profile": completeProfile}
+	formatFlags = map[string]string{"format": completeFormat, "template": completeValue}
+)
+
+// mergeFlags returns a new map with the flags from each set.
+func mergeFlags(sets ...map[string]string) map[string]string {
+	other := make(map[string]string)
+	for _, set := range sets {
+		for k, v := range set {
+			other[k] = v
+		}
+	}
+	return other
+}
+
+// completionTree describes the commands, flags & arguments of the CLI.
+// It must be kept in sync with the commands as they are added.
+var completionTree = &completionNode{
+	flags: formatFlags,
+	children: []*completionNode{
+		{
+			name:        "config",
+			description: "manage configuration profiles",
+			children: []*completionNode{
+				{name: "list", description: "list available profiles", flags: mergeFlags(configFlags, formatFlags)},
+				{name: "use-context", description: "set the profile used by default", flags: map[string]string{"config": completeValue}, args: []string{completeProfile}},
+			},
+		},
+		{
+			name:        "dial",
+			description: "manage your dial",
+			children: []*completionNode{
+				{name: "list", description: "list all available dials", flags: mergeFlags(configFlags, formatFlags, map[string]string{
+					"v": completeNone, "limit": completeValue, "after": completeValue, "before": completeValue,
+					"q": completeValue, "owner": completeValue, "min": completeValue, "max": completeValue,
+					"updated-since": completeValue, "sort": completeValue,
+				})},
+				{name: "create", description: "create a new dial", flags: mergeFlags(configFlags, formatFlags, map[string]string{"name": completeValue})},
+				{name: "delete", description: "remove an existing dial", flags: mergeFlags(configFlags, formatFlags), args: []string{completeDial}},
+				{name: "members", description: "view list of members of a dial", flags: mergeFlags(configFlags, formatFlags), args: []string{completeDial}},
+				{name: "set", description: "set your WTF level for a dial", flags: mergeFlags(configFlags, formatFlags), args: []string{completeDial}},
+				{name: "join", description: "join a dial using an invite URL", flags: mergeFlags(configFlags, formatFlags)},
+				{name: "leave", description: "leave a dial you are a member of", flags: mergeFlags(configFlags, formatFlags), args: []string{completeDial}},
+				{name: "rename", description: "change the name of a dial", flags: mergeFlags(configFlags, formatFlags), args: []string{completeDial}},
+				{name: "kick", description: "remove a member from a dial", flags: mergeFlags(configFlags, formatFlags), args: []string{completeDial}},
+				{name: "invite", description: "print the invite URL for a dial", flags: mergeFlags(configFlags, formatFlags), args: []string{completeDial}},
+			},
+		},
+		{name: "import", description: "create dials in bulk from a file", flags: mergeFlags(configFlags, formatFlags, map[string]string{"dry-run": completeNone})},
+		{name: "login", description: "authenticate using your browser", flags: mergeFlags(configFlags, map[string]string{"url": completeValue})},
+		{name: "report", description: "display WTF levels over time", flags: mergeFlags(configFlags, formatFlags, map[string]string{"dial": completeDial, "since": completeValue, "interval": completeValue, "tz": completeValue, "forecast": completeNone, "forecast-n": completeValue, "model": completeValue})},
+		{name: "completion", description: "print a shell completion script", args: []string{completeShell}},
+	},
+}
+
+// CompleteCommand is a hidden command used by the shell completion scripts.
+// It receives the words on the command line, excluding the program name, with
+// the last word being the one under the cursor. Each candidate is printed on
+// its own line with an optional tab-separated description.
+type CompleteCommand struct {
+	ConfigPath string
+	Profile    string
+}
+
+// Run executes the command. Errors are not reported since they would only
+// interfere with the user's shell.
+func (c *CompleteCommand) Run(ctx context.Context, args []string) error {
+	for _, candidate := range c.complete(ctx, args) {
+		fmt.Println(candidate)
+	}
+	return nil
+}
+
+// complete returns the completion candidates for the last word in args.
+func (c *CompleteCommand) complete(ctx context.Context, args []string) []string {
+	c.ConfigPath = DefaultConfigPath
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	words, cur := args[:len(args)-1], args[len(args)-1]
+
+	// Walk the preceding words to find the current command & argument
+	// position. Config flags are tracked so dials are fetched from the
+	// same server that the command would use.
+	node, pos, pending := completionTree, 0, completeNone
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		if !strings.HasPrefix(word, "-") || word == "-" {
+			if child := node.child(word); child != nil && pos == 0 {
+				node = child
+			} else {
+				pos++
+			}
+			continue
+		}
+
+		// Parse the flag name & value, if passed as "-name=value".
+		name := strings.TrimLeft(word, "-")
+		value, hasValue := "", false
+		if j := strings.Index(name, "="); j >= 0 {
+			name, value, hasValue = name[:j], name[j+1:], true
+		}
+
+		// Consume the next word as the value if the flag requires one.
+		typ, ok := node.flags[name]
+		if !ok || typ == completeNone {
+			continue
+		} else if !hasValue {
+			if i+1 >= len(words) {
+				pending = typ
+				break
+			}
+			i++
+			value = words[i]
+		}
+
+		switch name {
+		case "config":
+			c.ConfigPath = value
+		case "profile":
+			c.Profile = value
+		}
+	}
+
+	// Complete the value of the preceding flag.
+	if pending != completeNone {
+		return c.completeValue(ctx, pending)
+	}
+
+	// Complete flag names for the current command.
+	if strings.HasPrefix(cur, "-") {
+		prefix := "-"
+		if strings.HasPrefix(cur, "--") {
+			prefix = "--"
+		}
+
+		var candidates []string
+		for name := range node.flags {
+			candidates = append(candidates, prefix+name)
+		}
+		sort.Strings(candidates)
+		return candidates
+	}
+
+	// Complete subcommand names, if the command has subcommands.
+	if len(node.children) > 0 && pos == 0 {
+		var candidates []string
+		for _, child := range node.children {
+			candidates = append(candidates, child.name+"\t"+child.description)
+		}
+		return candidates
+	}
+
+	// Otherwise complete the positional argument.
+	if pos < len(node.args) {
+		return c.completeValue(ctx, node.args[pos])
+	}
+	return nil
+}
+
+// completeValue returns candidates for a given argument or flag value type.
+func (c *CompleteCommand) completeValue(ctx context.Context, typ string) []string {
+	switch typ {
+	case completeDial:
+		return c.completeDials(ctx)
+	case completeFormat:
+		return []string{FormatTable, FormatJSON, FormatCSV, FormatTemplate}
+	case completeProfile:
+		return c.completeProfiles()
+	case completeShell:
+		return []string{"bash", "zsh", "fish"}
+	default:
+		return nil
+	}
+}
+
+// completeProfiles returns the names of profiles in the config file.
+func (c *CompleteCommand) completeProfiles() []string {
+	config, err := ReadConfigFile(c.ConfigPath)
+	if err != nil {
+		return nil
+	}
+
+	candidates := []string{DefaultProfileName}
+	for name := range config.Profiles {
+		candidates = append(candidates, name)
+	}
+	sort.Strings(candidates[1:])
+	return candidates
+}
+
+// completeDials returns the IDs of the user's dials with their names as the
+// description. Dials are read from the local cache when it is fresh.
+func (c *CompleteCommand) completeDials(ctx context.Context) []string {
+	profile, err := LoadProfile(c.ConfigPath, c.Profile)
+	if err != nil {
+		return nil
+	}
+
+	dials, err := findCompletionDials(ctx, profile)
+	if err != nil {
+		return nil
+	}
+
+	candidates := make([]string, len(dials))
+	for i, dial := range dials {
+		candidates[i] = strconv.Itoa(dial.ID) + "\t" + dial.Name
+	}
+	return candidates
+}
+
+// completionDialCache represents the cached dials for a single profile.
+type completionDialCache struct {
+	Dials     []*wtf.Dial `json:"dials"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
+
+// findCompletionDials returns the user's dials from the cache, if fresh.
+// Otherwise the dials are fetched from the server and the cache is updated.
+func findCompletionDials(ctx context.Context, profile *Profile) ([]*wtf.Dial, error) {
+	filename, err := completionCachePath(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Use cached dials if they have not expired yet.
+	var cache completionDialCache
+	if buf, err := ioutil.ReadFile(filename); err == nil {
+		if err := json.Unmarshal(buf, &cache); err == nil && time.Since(cache.CreatedAt) < CompletionCacheTTL {
+			return cache.Dials, nil
+		}
+	}
+
+	// Fetch dials from the server. Time out quickly since the user is waiting.
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	ctx = wtf.NewContextWithUser(ctx, &wtf.User{APIKey: profile.APIKey})
+
+	dials, _, err := http.NewDialService(http.NewClient(profile.URL)).FindDials(ctx, wtf.DialFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	// Only cache the fields used for completion.
+	cache = completionDialCache{CreatedAt: time.Now()}
+	for _, dial := range dials {
+		cache.Dials = append(cache.Dials, &wtf.Dial{ID: dial.ID, Name: dial.Name})
+	}
+
+	// Failing to write the cache only makes the next completion slower.
+	if buf, err := json.Marshal(cache); err == nil {
+		if err := os.MkdirAll(filepath.Dir(filename), 0700); err == nil {
+			_ = ioutil.WriteFile(filename, buf, 0600)
+		}
+	}
+	return cache.Dials, nil
+}
+
+// completionCachePath returns the path to the dial cache for a profile. The
+// file name is derived from the URL & API key so that profiles & users never
+// share cached dials.
+func completionCachePath(profile *Profile) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.Sum256([]byte(profile.URL + "\n" + profile.APIKey))
+	return filepath.Join(dir, "wtf", "dials-"+hex.EncodeToString(h[:8])+".json"), nil
+}
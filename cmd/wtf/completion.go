@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+)
+
+// CompletionCommand is a command for printing shell completion scripts.
+//
+// The scripts are thin wrappers which call the hidden "wtf __complete" command
+// to generate candidates so that completion logic only lives in one place.
+type CompletionCommand struct{}
+
+// Run executes the command.
+func (c *CompletionCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("wtf-completion", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() == 0 {
+		c.usage()
+		return flag.ErrHelp
+	} else if fs.NArg() > 1 {
+		return fmt.Errorf("Only one shell allowed.")
+	}
+
+	switch shell := fs.Arg(0); shell {
+	case "bash":
+		fmt.Print(bashCompletionScript)
+	case "zsh":
+		fmt.Print(zshCompletionScript)
+	case "fish":
+		fmt.Print(fishCompletionScript)
+	default:
+		return fmt.Errorf("Unsupported shell: %q", shell)
+	}
+	return nil
+}
+
+// usage prints usage information for the command to STDOUT.
+func (c *CompletionCommand) usage() {
+	fmt.Println(`
+Print a shell completion script for bash, zsh or fish.
+
+Usage:
+
+	wtf completion bash|zsh|fish
+
+To load completions in the current shell:
+
+	bash:  source <(wtf completion bash)
+	zsh:   source <(wtf completion zsh)
+	fish:  wtf completion fish | source
+
+Dial IDs are completed by fetching your dials from the server. Results are
+cached for a short time so completion stays responsive.
+`[1:])
+}
+
+// bashCompletionScript is the completion script for bash. Descriptions are
+// not supported by bash so they are stripped from the candidates.
+const bashCompletionScript = `# bash completion for wtf
+_wtf() {
+	local IFS=$'\n'
+	local cur="${COMP_WORDS[COMP_CWORD]}"
+	COMPREPLY=($(compgen -W "$(wtf __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null | cut -f1)" -- "$cur"))
+}
+complete -o default -F _wtf wtf
+`
+
+// zshCompletionScript is the completion script for zsh. Candidates are
+// converted from "value<TAB>description" to the "value:description" format.
+const zshCompletionScript = `#compdef wtf
+_wtf() {
+	local -a completions
+	local line
+	for line in "${(@f)$(wtf __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}"; do
+		[[ -z "$line" ]] && continue
+		if [[ "$line" == *$'\t'* ]]; then
+			completions+=("${${line%%$'\t'*}//:/\\:}:${line#*$'\t'}")
+		else
+			completions+=("${line//:/\\:}")
+		fi
+	done
+	_describe 'wtf' completions
+}
+compdef _wtf wtf
+`
+
+// fishCompletionScript is the completion script for fish. Fish natively
+// supports the "value<TAB>description" candidate format.
+const fishCompletionScript = `# fish completion for wtf
+function __wtf_complete
+	set -l args (commandline -opc)
+	set -e args[1]
+	wtf __complete $args (commandline -ct) 2>/dev/null
+end
+complete -c wtf -f -a '(__wtf_complete)'
+`
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// ConfigCommand represents a collection of config-related subcommands.
+type ConfigCommand struct {
+	Output OutputOptions
+}
+
+// Run executes the command which delegates to other subcommands.
+func (c *ConfigCommand) Run(ctx context.Context, args []string) error {
+	// Shift off the subcommand name, if available.
+	var cmd string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd, args = args[0], args[1:]
+	}
+
+	// Delegete to the appropriate subcommand.
+	switch cmd {
+	case "", "list":
+		return (&ConfigListCommand{Output: c.Output}).Run(ctx, args)
+	case "use-context":
+		return (&ConfigUseContextCommand{}).Run(ctx, args)
+	case "help":
+		c.usage()
+		return flag.ErrHelp
+	default:
+		return fmt.Errorf("wtf config %s: unknown command", cmd)
+	}
+}
+
+// usage prints the subcommand usage to STDOUT.
+func (c *ConfigCommand) usage() {
+	fmt.Println(`
+Manage named profiles for connecting to different WTF Dial servers.
+
+Profiles are stored in the config file. The top-level "url" & "api-key"
+settings are available as the "default" profile:
+
+	url = "https://wtfdial.com"
+	api-key = "..."
+	current-context = "staging"
+
+	[profiles.staging]
+	url = "https://staging.wtfdial.com"
+	api-key = "..."
+
+Usage:
+
+	wtf config <command> [arguments]
+
+The commands are:
+
+	list           list available profiles
+	use-context    set the profile used by default
+`[1:])
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// ConfigListCommand represents a command for listing config profiles.
+// API keys are never printed.
+type ConfigListCommand struct {
+	ConfigPath string
+	Profile    string
+	Output     OutputOptions
+}
+
+// ConfigProfile represents a profile listed by the "config list" command.
+type ConfigProfile struct {
+	Name    string `json:"name"`
+	URL     string `json:"url"`
+	Current bool   `json:"current"`
+}
+
+// Run executes the command.
+func (c *ConfigListCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("wtf-config-list", flag.ContinueOnError)
+	attachConfigFlags(fs, &c.ConfigPath, &c.Profile)
+	attachFormatFlags(fs, &c.Output)
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if err := c.Output.Validate(); err != nil {
+		return err
+	}
+
+	// Load the configuration.
+	config, err := ReadConfigFile(c.ConfigPath)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("config file not found: %s", c.ConfigPath)
+	} else if err != nil {
+		return err
+	}
+
+	// Build a sorted list of profiles. The default profile is always first.
+	current := config.ProfileName(c.Profile)
+	names := make([]string, 0, len(config.Profiles))
+	for name := range config.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	profiles := make([]*ConfigProfile, 0, len(names)+1)
+	for _, name := range append([]string{DefaultProfileName}, names...) {
+		p, err := config.Profile(name)
+		if err != nil {
+			return err
+		}
+		profiles = append(profiles, &ConfigProfile{Name: name, URL: p.URL, Current: name == current})
+	}
+
+	// Write profiles in a machine-readable format, if requested.
+	if !c.Output.IsTable() {
+		return writeOutput(os.Stdout, c.Output, profiles)
+	}
+
+	// Print the profiles and mark the one currently in use.
+	for _, p := range profiles {
+		marker := " "
+		if p.Current {
+			marker = "*"
+		}
+		fmt.Printf("%s %s\t%s\n", marker, p.Name, p.URL)
+	}
+	return nil
+}
+
+// usage prints command usage information to STDOUT.
+func (c *ConfigListCommand) usage() {
+	fmt.Println(`
+List profiles in the config file. The profile in use is marked with a "*".
+
+Usage:
+
+	wtf config list
+
+Arguments:
+
+	-format FORMAT
+	    Output format: table, json, csv or template.
+
+	-template TEXT
+	    Go template executed for each profile. Requires "-format template".
+`[1:])
+}
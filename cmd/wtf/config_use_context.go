@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// ConfigUseContextCommand represents a command for changing the profile used
+// when one is not specified on the command line.
+type ConfigUseContextCommand struct {
+	ConfigPath string
+}
+
+// Run executes the command.
+func (c *ConfigUseContextCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("wtf-config-use-context", flag.ContinueOnError)
+	fs.StringVar(&c.ConfigPath, "config", DefaultConfigPath, "config path")
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() == 0 {
+		return fmt.Errorf("Profile name required.")
+	} else if fs.NArg() > 1 {
+		return fmt.Errorf("Only one profile name allowed.")
+	}
+	name := fs.Arg(0)
+
+	// Load the configuration.
+	config, err := ReadConfigFile(c.ConfigPath)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("config file not found: %s", c.ConfigPath)
+	} else if err != nil {
+		return err
+	}
+
+	// Ensure the profile exists before switching to it.
+	if _, err := config.Profile(name); err != nil {
+		return err
+	}
+
+	// Clear the setting for the default profile so the config stays minimal.
+	config.CurrentContext = name
+	if name == DefaultProfileName {
+		config.CurrentContext = ""
+	}
+	if err := WriteConfigFile(c.ConfigPath, config); err != nil {
+		return err
+	}
+
+	fmt.Printf("Switched to profile %q.\n", name)
+	return nil
+}
+
+// usage prints command usage information to STDOUT.
+func (c *ConfigUseContextCommand) usage() {
+	fmt.Println(`
+Set the profile used when one is not specified by the "-profile" flag or the
+WTF_PROFILE environment variable.
+
+Usage:
+
+	wtf config use-context NAME
+`[1:])
+}
@@ -8,7 +8,9 @@ import (
 )
 
 // DialCommand represents a collection of dial-related subcommands.
-type DialCommand struct{}
+type DialCommand struct {
+	Output OutputOptions
+}
 
 // Run executes the command which delegates to other subcommands.
 func (c *DialCommand) Run(ctx context.Context, args []string) error {
@@ -21,15 +23,25 @@ func (c *DialCommand) Run(ctx context.Context, args []string) error {
 	// Delegete to the appropriate subcommand.
 	switch cmd {
 	case "", "list":
-		return (&DialListCommand{}).Run(ctx, args)
+		return (&DialListCommand{Output: c.Output}).Run(ctx, args)
 	case "create":
-		return (&DialCreateCommand{}).Run(ctx, args)
+		return (&DialCreateCommand{Output: c.Output}).Run(ctx, args)
 	case "delete":
-		return (&DialDeleteCommand{}).Run(ctx, args)
+		return (&DialDeleteCommand{Output: c.Output}).Run(ctx, args)
 	case "members":
-		return (&DialMembersCommand{}).Run(ctx, args)
+		return (&DialMembersCommand{Output: c.Output}).Run(ctx, args)
 	case "set":
-		return (&DialSetCommand{}).Run(ctx, args)
+		return (&DialSetCommand{Output: c.Output}).Run(ctx, args)
+	case "join":
+		return (&DialJoinCommand{Output: c.Output}).Run(ctx, args)
+	case "leave":
+		return (&DialLeaveCommand{Output: c.Output}).Run(ctx, args)
+	case "rename":
+		return (&DialRenameCommand{Output: c.Output}).Run(ctx, args)
+	case "kick":
+		return (&DialKickCommand{Output: c.Output}).Run(ctx, args)
+	case "invite":
+		return (&DialInviteCommand{Output: c.Output}).Run(ctx, args)
 	case "help":
 		c.usage()
 		return flag.ErrHelp
@@ -54,5 +66,10 @@ The commands are:
 	delete      remove an existing dial
 	members     view list of members of a dial
 	set         set your WTF level for a dial
+	join        join a dial using an invite URL
+	leave       leave a dial you are a member of
+	rename      change the name of a dial
+	kick        remove a member from a dial
+	invite      print the invite URL for a dial
 `[1:])
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"os"
 
 	"github.com/benbjohnson/wtf"
 	"github.com/benbjohnson/wtf/http"
@@ -12,6 +13,8 @@ import (
 // DialCreateCommand is a command for creating dials.
 type DialCreateCommand struct {
 	ConfigPath string
+	Profile    string
+	Output     OutputOptions
 }
 
 // Run executes the command.
@@ -19,13 +22,16 @@ func (c *DialCreateCommand) Run(ctx context.Context, args []string) error {
 	// Create a flag set with parameters for the dial fields.
 	fs := flag.NewFlagSet("wtf-dial-create", flag.ContinueOnError)
 	name := fs.String("name", "", "dial name")
-	attachConfigFlags(fs, &c.ConfigPath)
+	attachConfigFlags(fs, &c.ConfigPath, &c.Profile)
+	attachFormatFlags(fs, &c.Output)
 	if err := fs.Parse(args); err != nil {
 		return err
+	} else if err := c.Output.Validate(); err != nil {
+		return err
 	}
 
 	// Load the configuration.
-	config, err := ReadConfigFile(c.ConfigPath)
+	config, err := LoadProfile(c.ConfigPath, c.Profile)
 	if err != nil {
 		return err
 	}
@@ -40,6 +46,11 @@ func (c *DialCreateCommand) Run(ctx context.Context, args []string) error {
 		return err
 	}
 
+	// Write the new dial for scripts, if a machine-readable format is requested.
+	if !c.Output.IsTable() {
+		return writeOutput(os.Stdout, c.Output, dial)
+	}
+
 	// Notify user of their new dial.
 	fmt.Printf("Your %q dial has been created!\n\n", dial.Name)
 	fmt.Printf("Please share this URL to invite others to contribute:\n\n")
@@ -61,5 +72,11 @@ Arguments:
 
 	-name NAME
 	    The name of the dial you are creating. Required.
+
+	-format FORMAT
+	    Output format: table, json, csv or template.
+
+	-template TEXT
+	    Go template used to format the new dial.
 `[1:])
 }
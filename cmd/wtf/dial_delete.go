@@ -13,15 +13,20 @@ import (
 // DialDeleteCommand represents a command for deleting dials.
 type DialDeleteCommand struct {
 	ConfigPath string
+	Profile    string
+	Output     OutputOptions
 }
 
 // Run executes the command.
 func (c *DialDeleteCommand) Run(ctx context.Context, args []string) error {
 	// Create flag set to parse the config path & read the ID.
 	fs := flag.NewFlagSet("wtf-dial-delete", flag.ContinueOnError)
-	attachConfigFlags(fs, &c.ConfigPath)
+	attachConfigFlags(fs, &c.ConfigPath, &c.Profile)
+	attachFormatFlags(fs, &c.Output)
 	if err := fs.Parse(args); err != nil {
 		return err
+	} else if err := c.Output.Validate(); err != nil {
+		return err
 	} else if fs.NArg() == 0 {
 		return fmt.Errorf("Dial ID required.")
 	} else if fs.NArg() > 1 {
@@ -35,7 +40,7 @@ func (c *DialDeleteCommand) Run(ctx context.Context, args []string) error {
 	}
 
 	// Load configuration file.
-	config, err := ReadConfigFile(c.ConfigPath)
+	config, err := LoadProfile(c.ConfigPath, c.Profile)
 	if err != nil {
 		return err
 	}
@@ -49,6 +54,12 @@ func (c *DialDeleteCommand) Run(ctx context.Context, args []string) error {
 		return err
 	}
 
+	// Only print a message for human-readable output. The exit status is
+	// enough to indicate success to scripts.
+	if !c.Output.IsTable() {
+		return nil
+	}
+
 	// Notify user that dial is gone.
 	fmt.Printf("Your dial has been deleted.\n")
 
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/http"
+)
+
+// DialInviteCommand represents a command for printing the invite URL of a dial.
+type DialInviteCommand struct {
+	ConfigPath string
+	Profile    string
+	Output     OutputOptions
+}
+
+// Run executes the command.
+func (c *DialInviteCommand) Run(ctx context.Context, args []string) error {
+	// Create a flag set to read the config path & read the dial ID.
+	fs := flag.NewFlagSet("wtf-dial-invite", flag.ContinueOnError)
+	attachConfigFlags(fs, &c.ConfigPath, &c.Profile)
+	attachFormatFlags(fs, &c.Output)
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if err := c.Output.Validate(); err != nil {
+		return err
+	} else if fs.NArg() == 0 {
+		return fmt.Errorf("Dial ID required.")
+	} else if fs.NArg() > 1 {
+		return fmt.Errorf("Only one dial ID allowed.")
+	}
+
+	// Parse dial ID from first arg.
+	id, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("Invalid dial ID.")
+	}
+
+	// Load configuration file.
+	config, err := LoadProfile(c.ConfigPath, c.Profile)
+	if err != nil {
+		return err
+	}
+
+	// Authenticate user with API key.
+	ctx = wtf.NewContextWithUser(ctx, &wtf.User{APIKey: config.APIKey})
+
+	// Fetch dial to read its invite code.
+	dial, err := http.NewDialService(http.NewClient(config.URL)).FindDialByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Print the shareable invite URL.
+	invite := &DialInvite{DialID: dial.ID, URL: config.URL + "/invite/" + dial.InviteCode}
+	if !c.Output.IsTable() {
+		return writeOutput(os.Stdout, c.Output, invite)
+	}
+	fmt.Println(invite.URL)
+
+	return nil
+}
+
+// DialInvite represents the output of the invite command.
+type DialInvite struct {
+	DialID int    `json:"dialID"`
+	URL    string `json:"url"`
+}
+
+// usage prints command usage information to STDOUT.
+func (c *DialInviteCommand) usage() {
+	fmt.Println(`
+Prints the URL used to invite others to a dial.
+
+Usage:
+
+	wtf dial invite DIAL_ID
+`[1:])
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/http"
+)
+
+// DialJoinCommand is a command for joining a dial via its invite URL or code.
+type DialJoinCommand struct {
+	ConfigPath string
+	Profile    string
+	Output     OutputOptions
+}
+
+// Run executes the command.
+func (c *DialJoinCommand) Run(ctx context.Context, args []string) error {
+	// Create a flag set to parse the config path & read the invite code.
+	fs := flag.NewFlagSet("wtf-dial-join", flag.ContinueOnError)
+	attachConfigFlags(fs, &c.ConfigPath, &c.Profile)
+	attachFormatFlags(fs, &c.Output)
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if err := c.Output.Validate(); err != nil {
+		return err
+	} else if fs.NArg() == 0 {
+		return fmt.Errorf("Invite URL or code required.")
+	} else if fs.NArg() > 1 {
+		return fmt.Errorf("Only one invite URL or code allowed.")
+	}
+
+	// Extract the invite code in case the user passed in the full URL.
+	code, err := parseInviteCode(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	// Load the configuration.
+	config, err := LoadProfile(c.ConfigPath, c.Profile)
+	if err != nil {
+		return err
+	}
+
+	// Authenticate the user with the API key from the config.
+	ctx = wtf.NewContextWithUser(ctx, &wtf.User{APIKey: config.APIKey})
+
+	// Issue request to join the dial associated with the invite code.
+	membership := &wtf.DialMembership{Dial: &wtf.Dial{InviteCode: code}}
+	svc := http.NewDialMembershipService(http.NewClient(config.URL))
+	if err := svc.CreateDialMembership(ctx, membership); err != nil {
+		return err
+	}
+
+	// Write the new membership for scripts, if requested.
+	if !c.Output.IsTable() {
+		return writeOutput(os.Stdout, c.Output, membership)
+	}
+
+	// Notify user of their new membership.
+	fmt.Printf("You have now joined the %q dial.\n", membership.Dial.Name)
+
+	return nil
+}
+
+// parseInviteCode returns the invite code from s. The value can be either the
+// code itself or a full invite URL (e.g. "https://wtfdial.com/invite/CODE").
+func parseInviteCode(s string) (string, error) {
+	if !strings.Contains(s, "/") {
+		return s, nil
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("Invalid invite URL.")
+	}
+
+	dir, code := path.Split(strings.TrimSuffix(u.Path, "/"))
+	if path.Base(dir) != "invite" || code == "" {
+		return "", fmt.Errorf("Invalid invite URL.")
+	}
+	return code, nil
+}
+
+// usage prints the command usage information to STDOUT.
+func (c *DialJoinCommand) usage() {
+	fmt.Println(`
+Join an existing dial using the invite URL shared by its owner.
+
+Usage:
+
+	wtf dial join INVITE_URL_OR_CODE
+`[1:])
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/http"
+)
+
+// DialKickCommand represents a command for removing a member from a dial.
+type DialKickCommand struct {
+	ConfigPath string
+	Profile    string
+	Output     OutputOptions
+}
+
+// Run executes the command.
+func (c *DialKickCommand) Run(ctx context.Context, args []string) error {
+	// Create a flag set to parse the config path, dial ID & user.
+	fs := flag.NewFlagSet("wtf-dial-kick", flag.ContinueOnError)
+	attachConfigFlags(fs, &c.ConfigPath, &c.Profile)
+	attachFormatFlags(fs, &c.Output)
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if err := c.Output.Validate(); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		return fmt.Errorf("Dial ID required.")
+	} else if fs.NArg() == 1 {
+		return fmt.Errorf("User required.")
+	} else if fs.NArg() > 2 {
+		return fmt.Errorf("Please only specify the dial ID and user.")
+	}
+
+	// Parse the dial ID from the first arg.
+	id, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("Invalid dial ID.")
+	}
+
+	// Load the configuration.
+	config, err := LoadProfile(c.ConfigPath, c.Profile)
+	if err != nil {
+		return err
+	}
+
+	// Authenticate the user with the API key from the config.
+	ctx = wtf.NewContextWithUser(ctx, &wtf.User{APIKey: config.APIKey})
+
+	// Fetch dial so we can find the membership. Members are automatically
+	// attached to the dial.
+	client := http.NewClient(config.URL)
+	dial, err := http.NewDialService(client).FindDialByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Find the member by user ID or by name.
+	membership := findMembershipByUser(dial.Memberships, fs.Arg(1))
+	if membership == nil {
+		return fmt.Errorf("User is not a member of this dial.")
+	}
+
+	// Remove the membership. Only the dial owner may remove other members.
+	if err := http.NewDialMembershipService(client).DeleteDialMembership(ctx, membership.ID); err != nil {
+		return err
+	}
+
+	// Only print a message for human-readable output. The exit status is
+	// enough to indicate success to scripts.
+	if !c.Output.IsTable() {
+		return nil
+	}
+
+	// Notify user of the successful removal.
+	fmt.Printf("%s has been removed from the dial.\n", membership.User.Name)
+
+	return nil
+}
+
+// findMembershipByUser returns the membership with a user matching either
+// the user ID or the user name. Returns nil if no membership matches.
+func findMembershipByUser(memberships []*wtf.DialMembership, user string) *wtf.DialMembership {
+	if userID, err := strconv.Atoi(user); err == nil {
+		for _, m := range memberships {
+			if m.UserID == userID {
+				return m
+			}
+		}
+	}
+
+	for _, m := range memberships {
+		if m.User != nil && m.User.Name == user {
+			return m
+		}
+	}
+	return nil
+}
+
+// usage prints command usage information to STDOUT.
+func (c *DialKickCommand) usage() {
+	fmt.Println(`
+Removes a member from a dial you own. USER can be the user's ID or name.
+
+Usage:
+
+	wtf dial kick DIAL_ID USER
+`[1:])
+}
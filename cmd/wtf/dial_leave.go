@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/http"
+)
+
+// DialLeaveCommand represents a command for leaving a dial you are a member of.
+type DialLeaveCommand struct {
+	ConfigPath string
+	Profile    string
+	Output     OutputOptions
+}
+
+// Run executes the command.
+func (c *DialLeaveCommand) Run(ctx context.Context, args []string) error {
+	// Create flag set to parse the config path & read the ID.
+	fs := flag.NewFlagSet("wtf-dial-leave", flag.ContinueOnError)
+	attachConfigFlags(fs, &c.ConfigPath, &c.Profile)
+	attachFormatFlags(fs, &c.Output)
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if err := c.Output.Validate(); err != nil {
+		return err
+	} else if fs.NArg() == 0 {
+		return fmt.Errorf("Dial ID required.")
+	} else if fs.NArg() > 1 {
+		return fmt.Errorf("Only one dial ID allowed.")
+	}
+
+	// Parse the dial ID from the first arg.
+	id, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("Invalid dial ID.")
+	}
+
+	// Load configuration file.
+	config, err := LoadProfile(c.ConfigPath, c.Profile)
+	if err != nil {
+		return err
+	}
+
+	// Authenticate user using the API key.
+	ctx = wtf.NewContextWithUser(ctx, &wtf.User{APIKey: config.APIKey})
+
+	// Instantiate HTTP service and remove the user's membership.
+	svc := http.NewDialService(http.NewClient(config.URL))
+	if err := svc.LeaveDial(ctx, id); err != nil {
+		return err
+	}
+
+	// Only print a message for human-readable output. The exit status is
+	// enough to indicate success to scripts.
+	if !c.Output.IsTable() {
+		return nil
+	}
+
+	// Notify user that they are no longer a member.
+	fmt.Printf("You have left the dial.\n")
+
+	return nil
+}
+
+// usage prints the command usage information to STDOUT.
+func (c *DialLeaveCommand) usage() {
+	fmt.Println(`
+Leave a dial you are a member of. Dial owners cannot leave their own dial.
+
+Usage:
+
+	wtf dial leave DIAL_ID
+`[1:])
+}
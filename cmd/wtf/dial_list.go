@@ -4,6 +4,8 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/benbjohnson/wtf"
 	"github.com/benbjohnson/wtf/http"
@@ -14,6 +16,8 @@ import (
 // which includes the id, name, & invite URL.
 type DialListCommand struct {
 	ConfigPath string
+	Profile    string
+	Output     OutputOptions
 }
 
 // Run executes the command.
@@ -21,13 +25,25 @@ func (c *DialListCommand) Run(ctx context.Context, args []string) error {
 	// Build a flag set to retrieve the config path & verbose flag.
 	fs := flag.NewFlagSet("wtf-dial-list", flag.ContinueOnError)
 	verbose := fs.Bool("v", false, "verbose")
-	attachConfigFlags(fs, &c.ConfigPath)
+	limit := fs.Int("limit", 0, "max number of dials")
+	after := fs.String("after", "", "cursor to list dials after")
+	before := fs.String("before", "", "cursor to list dials before")
+	query := fs.String("q", "", "search dial names")
+	owner := fs.Int("owner", 0, "owner user ID")
+	minValue := fs.Int("min", -1, "minimum WTF level")
+	maxValue := fs.Int("max", -1, "maximum WTF level")
+	updatedSince := fs.Duration("updated-since", 0, "only dials updated within duration")
+	sortBy := fs.String("sort", "", "sort order")
+	attachConfigFlags(fs, &c.ConfigPath, &c.Profile)
+	attachFormatFlags(fs, &c.Output)
 	if err := fs.Parse(args); err != nil {
 		return err
+	} else if err := c.Output.Validate(); err != nil {
+		return err
 	}
 
 	// Load the configuration.
-	config, err := ReadConfigFile(c.ConfigPath)
+	config, err := LoadProfile(c.ConfigPath, c.Profile)
 	if err != nil {
 		return err
 	}
@@ -35,13 +51,60 @@ func (c *DialListCommand) Run(ctx context.Context, args []string) error {
 	// Authenticate user with API key.
 	ctx = wtf.NewContextWithUser(ctx, &wtf.User{APIKey: config.APIKey})
 
-	// Build dial service and fetch list of dials user is a member of.
+	// Build dial service and fetch list of dials user is a member of. One
+	// extra dial is fetched to determine if another page exists.
+	filter := wtf.DialFilter{Limit: *limit, After: *after, Before: *before, SortBy: *sortBy}
+	if *query != "" {
+		filter.Query = query
+	}
+	if *owner != 0 {
+		filter.UserID = owner
+	}
+	if *minValue >= 0 {
+		filter.MinValue = minValue
+	}
+	if *maxValue >= 0 {
+		filter.MaxValue = maxValue
+	}
+	if *updatedSince > 0 {
+		t := time.Now().Add(-*updatedSince)
+		filter.UpdatedSince = &t
+	}
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+
+	fetch := filter
+	if fetch.Limit > 0 {
+		fetch.Limit++
+	}
 	dialService := http.NewDialService(http.NewClient(config.URL))
-	dials, _, err := dialService.FindDials(ctx, wtf.DialFilter{})
+	dials, _, err := dialService.FindDials(ctx, fetch)
 	if err != nil {
 		return err
 	}
 
+	// Trim the extra dial & generate cursors for the surrounding pages.
+	var prev, next string
+	start, end, hasPrev, hasNext := wtf.Paginate(len(dials), filter.Limit, filter.Offset, filter.After, filter.Before)
+	if dials = dials[start:end]; len(dials) > 0 {
+		if hasPrev {
+			prev = filter.Cursor(dials[0]).String()
+		}
+		if hasNext {
+			next = filter.Cursor(dials[len(dials)-1]).String()
+		}
+	}
+
+	// Write dials in a machine-readable format, if requested.
+	if !c.Output.IsTable() {
+		if err := writeOutput(os.Stdout, c.Output, dials); err != nil {
+			return err
+		}
+		c.printCursors(prev, next)
+		return nil
+	}
+
 	// Iterate over dials and print out information.
 	for _, dial := range dials {
 		// If we are not in verbose mode, just print the name.
@@ -58,10 +121,23 @@ func (c *DialListCommand) Run(ctx context.Context, args []string) error {
 			config.URL+"/invite/"+dial.InviteCode,
 		)
 	}
+	c.printCursors(prev, next)
 
 	return nil
 }
 
+// printCursors prints the flags for fetching the surrounding pages. They are
+// written to STDERR so they do not interfere with machine-readable output.
+// The cursors are only valid with the same search & sort flags.
+func (c *DialListCommand) printCursors(prev, next string) {
+	if prev != "" {
+		fmt.Fprintf(os.Stderr, "Previous page: -before %s\n", prev)
+	}
+	if next != "" {
+		fmt.Fprintf(os.Stderr, "Next page: -after %s\n", next)
+	}
+}
+
 // usage prints command usage information to STDOUT.
 func (c *DialListCommand) usage() {
 	fmt.Println(`
@@ -75,5 +151,37 @@ Arguments:
 
 	-v
 	    Enable verbose output.
+
+	-limit N
+	    Maximum number of dials to list. Defaults to all dials.
+
+	-after CURSOR
+	    List dials after a cursor printed by a previous page.
+
+	-before CURSOR
+	    List dials before a cursor printed by a previous page.
+
+	-q TEXT
+	    Only list dials with names containing TEXT.
+
+	-owner ID
+	    Only list dials created by the user with ID.
+
+	-min N
+	-max N
+	    Only list dials with a WTF level within a range.
+
+	-updated-since DURATION
+	    Only list dials updated within DURATION, e.g. 24h.
+
+	-sort ORDER
+	    Sort order: name_asc, name_desc, value_asc, value_desc,
+	    updated_at_asc or updated_at_desc. Defaults to creation order.
+
+	-format FORMAT
+	    Output format: table, json, csv or template.
+
+	-template TEXT
+	    Go template executed for each dial. Requires "-format template".
 `[1:])
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"os"
 	"strconv"
 
 	"github.com/benbjohnson/wtf"
@@ -13,15 +14,20 @@ import (
 // DialMembersCommand represents a command for listing members of a dial.
 type DialMembersCommand struct {
 	ConfigPath string
+	Profile    string
+	Output     OutputOptions
 }
 
 // Run executes the command.
 func (c *DialMembersCommand) Run(ctx context.Context, args []string) error {
 	// Create a flag set to read the config path & read the dial ID.
 	fs := flag.NewFlagSet("wtf-dial-members", flag.ContinueOnError)
-	attachConfigFlags(fs, &c.ConfigPath)
+	attachConfigFlags(fs, &c.ConfigPath, &c.Profile)
+	attachFormatFlags(fs, &c.Output)
 	if err := fs.Parse(args); err != nil {
 		return err
+	} else if err := c.Output.Validate(); err != nil {
+		return err
 	} else if fs.NArg() == 0 {
 		return fmt.Errorf("Dial ID required.")
 	} else if fs.NArg() > 1 {
@@ -35,7 +41,7 @@ func (c *DialMembersCommand) Run(ctx context.Context, args []string) error {
 	}
 
 	// Load configuration file.
-	config, err := ReadConfigFile(c.ConfigPath)
+	config, err := LoadProfile(c.ConfigPath, c.Profile)
 	if err != nil {
 		return err
 	}
@@ -51,6 +57,11 @@ func (c *DialMembersCommand) Run(ctx context.Context, args []string) error {
 		return err
 	}
 
+	// Write memberships in a machine-readable format, if requested.
+	if !c.Output.IsTable() {
+		return writeOutput(os.Stdout, c.Output, dial.Memberships)
+	}
+
 	// Iterate over membrships and print the name & value.
 	for _, membership := range dial.Memberships {
 		fmt.Printf(
@@ -71,5 +82,13 @@ List members of a dial and their WTF level.
 Usage:
 
 	wtf dial members DIAL_ID
+
+Arguments:
+
+	-format FORMAT
+	    Output format: table, json, csv or template.
+
+	-template TEXT
+	    Go template executed for each membership. Requires "-format template".
 `[1:])
 }
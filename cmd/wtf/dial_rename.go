@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/http"
+)
+
+// DialRenameCommand is a command for changing the name of a dial.
+type DialRenameCommand struct {
+	ConfigPath string
+	Profile    string
+	Output     OutputOptions
+}
+
+// Run executes the command.
+func (c *DialRenameCommand) Run(ctx context.Context, args []string) error {
+	// Create a flag set to parse the config path, dial ID & new name.
+	fs := flag.NewFlagSet("wtf-dial-rename", flag.ContinueOnError)
+	attachConfigFlags(fs, &c.ConfigPath, &c.Profile)
+	attachFormatFlags(fs, &c.Output)
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if err := c.Output.Validate(); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		return fmt.Errorf("Dial ID required.")
+	} else if fs.NArg() == 1 {
+		return fmt.Errorf("Dial name required.")
+	} else if fs.NArg() > 2 {
+		return fmt.Errorf("Please only specify the dial ID and name.")
+	}
+
+	// Parse the dial ID from the first arg.
+	id, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("Invalid dial ID.")
+	}
+	name := fs.Arg(1)
+
+	// Load the configuration.
+	config, err := LoadProfile(c.ConfigPath, c.Profile)
+	if err != nil {
+		return err
+	}
+
+	// Authenticate the user with the API key from the config.
+	ctx = wtf.NewContextWithUser(ctx, &wtf.User{APIKey: config.APIKey})
+
+	// Issue update request over HTTP.
+	svc := http.NewDialService(http.NewClient(config.URL))
+	dial, err := svc.UpdateDial(ctx, id, wtf.DialUpdate{Name: &name})
+	if err != nil {
+		return err
+	}
+
+	// Write the updated dial for scripts, if requested.
+	if !c.Output.IsTable() {
+		return writeOutput(os.Stdout, c.Output, dial)
+	}
+
+	// Notify user of the successful update.
+	fmt.Printf("Your dial has been renamed to %q.\n", dial.Name)
+
+	return nil
+}
+
+// usage print usage information for the command to STDOUT.
+func (c *DialRenameCommand) usage() {
+	fmt.Println(`
+Renames a dial you own.
+
+Usage:
+
+	wtf dial rename DIAL_ID NAME
+`[1:])
+}
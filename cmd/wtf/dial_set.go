@@ -13,15 +13,20 @@ import (
 // DialSetCommand is a command for setting the WTF value for a membership.
 type DialSetCommand struct {
 	ConfigPath string
+	Profile    string
+	Output     OutputOptions
 }
 
 // Run executes the command.
 func (c *DialSetCommand) Run(ctx context.Context, args []string) error {
 	// Create a flag set with parameters for the dial fields.
 	fs := flag.NewFlagSet("wtf-dial-set", flag.ContinueOnError)
-	attachConfigFlags(fs, &c.ConfigPath)
+	attachConfigFlags(fs, &c.ConfigPath, &c.Profile)
+	attachFormatFlags(fs, &c.Output)
 	if err := fs.Parse(args); err != nil {
 		return err
+	} else if err := c.Output.Validate(); err != nil {
+		return err
 	}
 
 	if fs.NArg() == 0 {
@@ -45,7 +50,7 @@ func (c *DialSetCommand) Run(ctx context.Context, args []string) error {
 	}
 
 	// Load the configuration.
-	config, err := ReadConfigFile(c.ConfigPath)
+	config, err := LoadProfile(c.ConfigPath, c.Profile)
 	if err != nil {
 		return err
 	}
@@ -59,6 +64,12 @@ func (c *DialSetCommand) Run(ctx context.Context, args []string) error {
 		return err
 	}
 
+	// Only print a message for human-readable output. The exit status is
+	// enough to indicate success to scripts.
+	if !c.Output.IsTable() {
+		return nil
+	}
+
 	// Notify user of the successful update.
 	fmt.Println("Your WTF level has been updated.")
 
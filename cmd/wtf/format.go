@@ -0,0 +1,182 @@
+package main
+
+import (
+	encodingcsv "encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/csv"
+)
+
+// Output formats supported by the "-format" flag.
+const (
+	FormatTable    = "table"
+	FormatJSON     = "json"
+	FormatCSV      = "csv"
+	FormatTemplate = "template"
+)
+
+// OutputOptions represents the output settings common to all subcommands.
+type OutputOptions struct {
+	// Output format. Defaults to a human-readable table.
+	Format string
+
+	// Go text/template used when Format is "template".
+	Template string
+}
+
+// IsTable returns true if output should be written in human-readable form.
+func (opt *OutputOptions) IsTable() bool {
+	return opt.Format == "" || opt.Format == FormatTable
+}
+
+// Validate returns an error if the format is unknown or if the template is
+// missing or cannot be parsed.
+func (opt *OutputOptions) Validate() error {
+	switch opt.Format {
+	case "", FormatTable, FormatJSON, FormatCSV:
+		return nil
+	case FormatTemplate:
+		if opt.Template == "" {
+			return fmt.Errorf("Template required when using the template format.")
+		} else if _, err := template.New("output").Parse(opt.Template); err != nil {
+			return fmt.Errorf("Invalid template: %s", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("Invalid format: %q", opt.Format)
+	}
+}
+
+// attachFormatFlags adds the common "-format" & "-template" flags to a flag set.
+// Options already set in opt, such as from flags passed before the subcommand,
+// are used as the defaults.
+func attachFormatFlags(fs *flag.FlagSet, opt *OutputOptions) {
+	if opt.Format == "" {
+		opt.Format = FormatTable
+	}
+	fs.StringVar(&opt.Format, "format", opt.Format, "output format (table, json, csv, template)")
+	fs.StringVar(&opt.Template, "template", opt.Template, "go template used by the template format")
+}
+
+// writeOutput writes v to w using the machine-readable format in opt. Table
+// output is formatted by each command so it is not handled here.
+//
+// For the template format, slices are executed once per element and each
+// result is written on its own line.
+func writeOutput(w io.Writer, opt OutputOptions, v interface{}) error {
+	switch opt.Format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		return enc.Encode(v)
+	case FormatCSV:
+		return writeCSV(w, v)
+	case FormatTemplate:
+		return writeTemplate(w, opt.Template, v)
+	default:
+		return fmt.Errorf("Invalid format: %q", opt.Format)
+	}
+}
+
+// writeCSV writes v using the encoders in the csv package.
+func writeCSV(w io.Writer, v interface{}) error {
+	switch v := v.(type) {
+	case *wtf.Dial:
+		return writeCSV(w, []*wtf.Dial{v})
+	case []*wtf.Dial:
+		enc := csv.NewDialEncoder(w)
+		for _, dial := range v {
+			if err := enc.EncodeDial(dial); err != nil {
+				return err
+			}
+		}
+		return enc.Close()
+
+	case *wtf.DialMembership:
+		return writeCSV(w, []*wtf.DialMembership{v})
+	case []*wtf.DialMembership:
+		enc := csv.NewDialMembershipEncoder(w)
+		for _, membership := range v {
+			if err := enc.EncodeDialMembership(membership); err != nil {
+				return err
+			}
+		}
+		return enc.Close()
+
+	case *DialInvite:
+		enc := encodingcsv.NewWriter(w)
+		_ = enc.Write([]string{"dial_id", "url"})
+		_ = enc.Write([]string{strconv.Itoa(v.DialID), v.URL})
+		enc.Flush()
+		return enc.Error()
+
+	case []*wtf.DialValueRecord:
+		enc := csv.NewDialValueReportEncoder(w)
+		if err := enc.EncodeDialValueReport(&wtf.DialValueReport{Records: v}); err != nil {
+			return err
+		}
+		return enc.Close()
+
+	case []*wtf.DialValueForecastRecord:
+		enc := encodingcsv.NewWriter(w)
+		_ = enc.Write([]string{"timestamp", "value", "lower", "upper"})
+		for _, r := range v {
+			_ = enc.Write([]string{
+				r.Timestamp.UTC().Format(time.RFC3339),
+				strconv.FormatFloat(r.Value, 'f', -1, 64),
+				strconv.FormatFloat(r.Lower, 'f', -1, 64),
+				strconv.FormatFloat(r.Upper, 'f', -1, 64),
+			})
+		}
+		enc.Flush()
+		return enc.Error()
+
+	case []*ConfigProfile:
+		enc := encodingcsv.NewWriter(w)
+		_ = enc.Write([]string{"name", "url", "current"})
+		for _, p := range v {
+			_ = enc.Write([]string{p.Name, p.URL, strconv.FormatBool(p.Current)})
+		}
+		enc.Flush()
+		return enc.Error()
+
+	default:
+		return fmt.Errorf("CSV format not supported for this command.")
+	}
+}
+
+// writeTemplate executes the Go template text against v.
+func writeTemplate(w io.Writer, text string, v interface{}) error {
+	tmpl, err := template.New("output").Parse(text)
+	if err != nil {
+		return fmt.Errorf("Invalid template: %s", err)
+	}
+
+	// Execute a single value once.
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		if err := tmpl.Execute(w, v); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintln(w)
+		return err
+	}
+
+	// Otherwise execute against each element so lists can be easily scripted.
+	for i := 0; i < rv.Len(); i++ {
+		if err := tmpl.Execute(w, rv.Index(i).Interface()); err != nil {
+			return err
+		} else if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/csv"
+	"github.com/benbjohnson/wtf/http"
+)
+
+// ImportCommand is a command for creating dials in bulk from a file.
+type ImportCommand struct {
+	ConfigPath string
+	Profile    string
+	Output     OutputOptions
+}
+
+// Run executes the command.
+func (c *ImportCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("wtf-import", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "validate without creating dials")
+	attachConfigFlags(fs, &c.ConfigPath, &c.Profile)
+	attachFormatFlags(fs, &c.Output)
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if err := c.Output.Validate(); err != nil {
+		return err
+	} else if fs.NArg() == 0 {
+		return fmt.Errorf("File path required.")
+	} else if fs.NArg() > 1 {
+		return fmt.Errorf("Only one file path allowed.")
+	}
+	filename := fs.Arg(0)
+
+	// Decode rows locally so that decoding errors can be reported along with
+	// the validation errors from the server.
+	rows, decodeErrs, err := readDialImportFile(filename)
+	if err != nil {
+		return err
+	}
+
+	// Load the configuration.
+	config, err := LoadProfile(c.ConfigPath, c.Profile)
+	if err != nil {
+		return err
+	}
+
+	// Authenticate the user with the API key from the config.
+	ctx = wtf.NewContextWithUser(ctx, &wtf.User{APIKey: config.APIKey})
+
+	// Issue import request & merge in decoding errors.
+	svc := http.NewDialService(http.NewClient(config.URL))
+	result, err := svc.ImportDials(ctx, rows, wtf.DialImportOptions{DryRun: *dryRun})
+	if err != nil {
+		return err
+	}
+	result.Errors = append(result.Errors, decodeErrs...)
+	result.SortErrors()
+
+	// Write the result for scripts, if a machine-readable format is requested.
+	if !c.Output.IsTable() {
+		if err := writeOutput(os.Stdout, c.Output, result); err != nil {
+			return err
+		}
+	} else {
+		for _, e := range result.Errors {
+			fmt.Printf("row %d: %s\n", e.Row, e.Message)
+		}
+		if len(result.Errors) > 0 {
+			fmt.Println()
+		}
+
+		if result.DryRun {
+			fmt.Printf("Dry run: %d dials and %d values would be imported.\n", len(result.Dials), result.ValueN)
+		} else {
+			fmt.Printf("Imported %d dials and %d values.\n", len(result.Dials), result.ValueN)
+		}
+	}
+
+	// Exit with an error so scripts can detect partial imports.
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("%d rows could not be imported.", len(result.Errors))
+	}
+	return nil
+}
+
+// readDialImportFile reads import rows from filename. JSON files must contain
+// a list of rows. All other files are read as CSV. Rows which cannot be
+// decoded are returned as errors. A filename of "-" reads CSV from STDIN.
+func readDialImportFile(filename string) (rows []*wtf.DialImportRow, errs []*wtf.DialImportError, err error) {
+	var r io.Reader = os.Stdin
+	if filename != "-" {
+		f, err := os.Open(filename)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	// Decode a JSON list of rows. Number rows by position if unset.
+	if strings.EqualFold(filepath.Ext(filename), ".json") {
+		if err := json.NewDecoder(r).Decode(&rows); err != nil {
+			return nil, nil, fmt.Errorf("Invalid JSON file: %s", err)
+		}
+		for i, row := range rows {
+			if row.Row == 0 {
+				row.Row = i + 1
+			}
+		}
+		return rows, nil, nil
+	}
+
+	// Otherwise decode CSV & collect errors for malformed rows.
+	dec := csv.NewDialImportDecoder(r)
+	for {
+		var row wtf.DialImportRow
+		if err := dec.Decode(&row); err == io.EOF {
+			return rows, errs, nil
+		} else if err != nil && row.Row != 0 && wtf.ErrorCode(err) == wtf.EINVALID {
+			errs = append(errs, &wtf.DialImportError{Row: row.Row, Code: wtf.EINVALID, Message: wtf.ErrorMessage(err)})
+			continue
+		} else if err != nil {
+			return nil, nil, err
+		}
+		rows = append(rows, &row)
+	}
+}
+
+// usage prints usage information for the command to STDOUT.
+func (c *ImportCommand) usage() {
+	fmt.Println(`
+Create dials in bulk from a CSV or JSON file.
+
+Usage:
+
+	wtf import [arguments] FILE
+
+CSV files require a header with a "name" column. Optional "value" and
+"timestamp" columns backfill historical values for the dial. Rows with the
+same name are imported into the same dial. Files exported with
+"wtf dial list -format csv" can be imported directly.
+
+JSON files contain a list of objects with "name", "value" and "timestamp"
+fields. Timestamps use the RFC 3339 format.
+
+Invalid rows are reported and skipped. The remaining rows are still imported.
+
+Arguments:
+
+	-dry-run
+	    Validate the file without creating any dials.
+
+	-format FORMAT
+	    Output format: table, json or template.
+
+	-template TEXT
+	    Go template used to format the import result.
+`[1:])
+}
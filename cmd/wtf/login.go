@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/http"
+)
+
+// LoginCommand is a command for authenticating the CLI using a browser.
+//
+// The CLI requests a device code from the server and displays a URL & short
+// code to the user. It then polls the server until the user approves the
+// request on the web site, at which point the device's token is saved to the
+// config. The token is separate from the user's API key so that it can be
+// revoked from the settings page.
+type LoginCommand struct {
+	ConfigPath string
+	Profile    string
+}
+
+// Run executes the command.
+func (c *LoginCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("wtf-login", flag.ContinueOnError)
+	url := fs.String("url", "", "server URL")
+	attachConfigFlags(fs, &c.ConfigPath, &c.Profile)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// Load the existing configuration, if one exists. Logging in is typically
+	// the first command a user runs so the config file is created if missing.
+	config, err := ReadConfigFile(c.ConfigPath)
+	if os.IsNotExist(err) {
+		config = DefaultConfig()
+	} else if err != nil {
+		return err
+	}
+
+	// Log in to the selected profile. A new profile is created if it does not
+	// exist yet, in which case a URL is required.
+	name := config.ProfileName(c.Profile)
+	profile, err := config.Profile(name)
+	if err != nil && *url == "" {
+		return fmt.Errorf("Profile %q not found. Use -url to create it.", name)
+	} else if err != nil {
+		profile = &Profile{}
+	}
+
+	// Override the server URL, if specified.
+	if *url != "" {
+		profile.URL = *url
+	}
+
+	// Request a new pair of codes from the server. The device is named after
+	// the host so the user can recognize it when revoking access.
+	auth := wtf.DeviceAuthorization{DeviceName: "wtf CLI"}
+	if hostname, err := os.Hostname(); err == nil {
+		auth.DeviceName += " on " + hostname
+	}
+	svc := http.NewDeviceAuthorizationService(http.NewClient(profile.URL))
+	if err := svc.CreateDeviceAuthorization(ctx, &auth); err != nil {
+		return err
+	}
+
+	// Ask the user to approve the request in their browser.
+	fmt.Printf("Open the following URL in your browser to log in:\n\n")
+	fmt.Printf("\t%s\n\n", profile.URL+"/device?code="+auth.UserCode)
+	fmt.Printf("Confirm that the code displayed matches: %s\n\n", auth.UserCode)
+	fmt.Printf("Waiting for approval...\n")
+
+	// Poll until the request is approved. The server reports expired codes
+	// as not found so those are reported back to the user with a hint.
+	ticker := time.NewTicker(wtf.DeviceAuthorizationInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		other, err := svc.ExchangeDeviceCode(ctx, auth.DeviceCode)
+		if wtf.ErrorCode(err) == wtf.ENOTFOUND {
+			return wtf.Errorf(wtf.ENOTFOUND, "Login request expired. Please run 'wtf login' again.")
+		} else if err != nil {
+			return err
+		} else if other.Device == nil {
+			continue
+		}
+
+		// Save the device token so that subsequent commands are authenticated.
+		profile.APIKey = other.Device.Token
+		config.SetProfile(name, profile)
+		if err := WriteConfigFile(c.ConfigPath, config); err != nil {
+			return err
+		}
+		fmt.Printf("\nYou have successfully logged in. A device token has been saved to the %q profile.\n", name)
+		return nil
+	}
+}
+
+// usage prints usage information for the command to STDOUT.
+func (c *LoginCommand) usage() {
+	fmt.Println(`
+Log in using your browser and save a device token to the config file.
+The token can be revoked from the settings page on the web site.
+
+Usage:
+
+	wtf login [arguments]
+
+Arguments:
+
+	-url URL
+	    Server URL. Defaults to the URL in the selected profile.
+
+	-profile NAME
+	    Profile to save the token to. Created if it does not exist.
+
+	-config PATH
+	    Path to the config file. It is created if it does not exist.
+`[1:])
+}
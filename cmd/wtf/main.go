@@ -53,6 +53,17 @@ func main() {
 
 // Run executes the main program.
 func Run(ctx context.Context, args []string) error {
+	// Parse output flags passed before the subcommand. These are passed down
+	// as defaults so they can also be overridden after the subcommand.
+	var output OutputOptions
+	fs := flag.NewFlagSet("wtf", flag.ContinueOnError)
+	attachFormatFlags(fs, &output)
+	fs.Usage = usage
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	args = fs.Args()
+
 	// Shift off subcommand from the argument list, if available.
 	var cmd string
 	if len(args) > 0 {
@@ -61,9 +72,21 @@ func Run(ctx context.Context, args []string) error {
 
 	// Delegate subcommands to their own Run() methods.
 	switch cmd {
+	case "completion":
+		return (&CompletionCommand{}).Run(ctx, args)
+	case "__complete":
+		return (&CompleteCommand{}).Run(ctx, args)
+	case "config":
+		return (&ConfigCommand{Output: output}).Run(ctx, args)
 	case "dial":
-		return (&DialCommand{}).Run(ctx, args)
-	case "", "-h", "help":
+		return (&DialCommand{Output: output}).Run(ctx, args)
+	case "import":
+		return (&ImportCommand{Output: output}).Run(ctx, args)
+	case "login":
+		return (&LoginCommand{}).Run(ctx, args)
+	case "report":
+		return (&ReportCommand{Output: output}).Run(ctx, args)
+	case "", "help":
 		usage()
 		return flag.ErrHelp
 	default:
@@ -78,14 +101,40 @@ Command line utility for interacting with the WTF Dial service.
 
 Usage:
 
-	wtf <command> [arguments]
+	wtf [-format FORMAT] [-template TEXT] <command> [arguments]
 
 The commands are:
 
+	completion  print a shell completion script
+	config      manage configuration profiles
 	dial        manage your dial
+	import      create dials in bulk from a file
+	login       authenticate using your browser
+	report      display WTF levels over time
+
+All commands accept a "-format" flag to write output as a table (default),
+json, csv, or using a Go template passed with the "-template" flag. These
+flags may be passed before the command to apply to it, for example:
+
+	wtf -format json dial list
+
+All commands accept a "-profile" flag to select a named profile from the
+config file. The profile may also be set with the WTF_PROFILE environment
+variable. The WTF_URL and WTF_API_KEY variables override profile settings.
 `[1:])
 }
 
+// Environment variables used to override configuration settings.
+const (
+	EnvProfile = "WTF_PROFILE"
+	EnvURL     = "WTF_URL"
+	EnvAPIKey  = "WTF_API_KEY"
+)
+
+// DefaultProfileName is the name used to refer to the top-level settings
+// in the config file which are used when no other profile is selected.
+const DefaultProfileName = "default"
+
 // Config represents a configuration file common to all subcommands.
 type Config struct {
 	// Base URL of the server. This should be changed for local development.
@@ -93,6 +142,19 @@ type Config struct {
 
 	// API key used for authentication. Users can find this key on the /settings page.
 	APIKey string `toml:"api-key"`
+
+	// Name of the profile used when one is not specified on the command line.
+	CurrentContext string `toml:"current-context,omitempty"`
+
+	// Additional named profiles for connecting to other servers, such as a
+	// staging or local development server.
+	Profiles map[string]*Profile `toml:"profiles,omitempty"`
+}
+
+// Profile represents the settings used to connect to a single server.
+type Profile struct {
+	URL    string `toml:"url"`
+	APIKey string `toml:"api-key"`
 }
 
 // DefaultConfig returns a new instance of Config with defaults set.
@@ -102,26 +164,98 @@ func DefaultConfig() Config {
 	}
 }
 
+// ProfileName returns the name of the profile to use. The name passed on the
+// command line takes precedence, then the WTF_PROFILE environment variable and
+// finally the current context stored in the config file.
+func (c *Config) ProfileName(name string) string {
+	if name != "" {
+		return name
+	} else if name = os.Getenv(EnvProfile); name != "" {
+		return name
+	} else if c.CurrentContext != "" {
+		return c.CurrentContext
+	}
+	return DefaultProfileName
+}
+
+// Profile returns the settings for the named profile. Returns an error if the
+// profile does not exist.
+func (c *Config) Profile(name string) (*Profile, error) {
+	if name == DefaultProfileName {
+		return &Profile{URL: c.URL, APIKey: c.APIKey}, nil
+	}
+
+	p := c.Profiles[name]
+	if p == nil {
+		return nil, fmt.Errorf("Profile not found: %q", name)
+	}
+
+	// Copy profile so the config is not affected by changes. Fall back to the
+	// default URL if one is not set for the profile.
+	other := *p
+	if other.URL == "" {
+		other.URL = DefaultURL
+	}
+	return &other, nil
+}
+
+// SetProfile assigns settings to the named profile. Creates it if needed.
+func (c *Config) SetProfile(name string, p *Profile) {
+	if name == DefaultProfileName {
+		c.URL, c.APIKey = p.URL, p.APIKey
+		return
+	}
+
+	if c.Profiles == nil {
+		c.Profiles = make(map[string]*Profile)
+	}
+	other := *p
+	c.Profiles[name] = &other
+}
+
+// LoadProfile reads the config file and returns the settings for the selected
+// profile with any environment variable overrides applied.
+//
+// The config file is optional if an API key is provided by the environment
+// so that the CLI can be used in scripts without a config file.
+func LoadProfile(filename, name string) (*Profile, error) {
+	config, err := ReadConfigFile(filename)
+	if os.IsNotExist(err) && os.Getenv(EnvAPIKey) != "" {
+		config, err = DefaultConfig(), nil
+	} else if os.IsNotExist(err) {
+		return nil, fmt.Errorf("config file not found: %s", filename)
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Look up the active profile & apply environment overrides.
+	p, err := config.Profile(config.ProfileName(name))
+	if err != nil {
+		return nil, err
+	}
+	if v := os.Getenv(EnvURL); v != "" {
+		p.URL = v
+	}
+	if v := os.Getenv(EnvAPIKey); v != "" {
+		p.APIKey = v
+	}
+	return p, nil
+}
+
 // ReadConfigFile unmarshals config from filename. Expands path if needed.
+// Returns an error that can be checked with os.IsNotExist() if the file does
+// not exist.
 func ReadConfigFile(filename string) (Config, error) {
 	config := DefaultConfig()
 
-	// Expand filename, if necessary. This means substituting a "~" prefix
-	// with the user's home directory, if available.
-	if prefix := "~" + string(os.PathSeparator); strings.HasPrefix(filename, prefix) {
-		u, err := user.Current()
-		if err != nil {
-			return config, err
-		} else if u.HomeDir == "" {
-			return config, fmt.Errorf("home directory unset")
-		}
-		filename = filepath.Join(u.HomeDir, strings.TrimPrefix(filename, prefix))
+	// Expand filename, if necessary.
+	filename, err := expand(filename)
+	if err != nil {
+		return config, err
 	}
 
 	// Read & deserialize configuration.
-	if buf, err := ioutil.ReadFile(filename); os.IsNotExist(err) {
-		return config, fmt.Errorf("config file not found: %s", filename)
-	} else if err != nil {
+	if buf, err := ioutil.ReadFile(filename); err != nil {
 		return config, err
 	} else if err := toml.Unmarshal(buf, &config); err != nil {
 		return config, err
@@ -129,7 +263,40 @@ func ReadConfigFile(filename string) (Config, error) {
 	return config, nil
 }
 
-// attachConfigFlags adds a common "-config" flag to a flag set.
-func attachConfigFlags(fs *flag.FlagSet, p *string) {
-	fs.StringVar(p, "config", DefaultConfigPath, "config path")
+// WriteConfigFile marshals config to filename. Expands path if needed.
+// The file is only readable by the current user since it contains an API key.
+func WriteConfigFile(filename string, config Config) error {
+	filename, err := expand(filename)
+	if err != nil {
+		return err
+	}
+
+	buf, err := toml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, buf, 0600)
+}
+
+// expand returns path using tilde expansion. This means substituting a "~"
+// prefix with the user's home directory, if available.
+func expand(path string) (string, error) {
+	prefix := "~" + string(os.PathSeparator)
+	if !strings.HasPrefix(path, prefix) {
+		return path, nil
+	}
+
+	u, err := user.Current()
+	if err != nil {
+		return path, err
+	} else if u.HomeDir == "" {
+		return path, fmt.Errorf("home directory unset")
+	}
+	return filepath.Join(u.HomeDir, strings.TrimPrefix(path, prefix)), nil
+}
+
+// attachConfigFlags adds the common "-config" & "-profile" flags to a flag set.
+func attachConfigFlags(fs *flag.FlagSet, path, profile *string) {
+	fs.StringVar(path, "config", DefaultConfigPath, "config path")
+	fs.StringVar(profile, "profile", "", "config profile name")
 }
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/http"
+)
+
+// Chart dimensions used when rendering reports to the terminal.
+const (
+	ChartWidth  = 60
+	ChartHeight = 10
+)
+
+// ReportCommand is a command for displaying dial values over time.
+type ReportCommand struct {
+	ConfigPath string
+	Profile    string
+	Output     OutputOptions
+}
+
+// Run executes the command.
+func (c *ReportCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("wtf-report", flag.ContinueOnError)
+	dialID := fs.Int("dial", 0, "dial ID")
+	since := fs.Duration("since", 24*time.Hour, "report period")
+	interval := fs.String("interval", "15m", "interval size or calendar unit")
+	tz := fs.String("tz", "", "time zone for calendar intervals")
+	forecast := fs.Bool("forecast", false, "project the dial's values forward")
+	forecastN := fs.Int("forecast-n", wtf.DefaultForecastN, "number of intervals to forecast")
+	model := fs.String("model", "", "forecast model: linear or holt-winters")
+	attachConfigFlags(fs, &c.ConfigPath, &c.Profile)
+	attachFormatFlags(fs, &c.Output)
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if err := c.Output.Validate(); err != nil {
+		return err
+	} else if *since <= 0 {
+		return fmt.Errorf("Report period must be positive.")
+	} else if *forecast && *dialID == 0 {
+		return fmt.Errorf("Forecasts require a dial (-dial).")
+	}
+
+	// Parse the interval. Calendar intervals use the time zone from the
+	// user's profile unless one is specified.
+	var loc *time.Location
+	if *tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(*tz); err != nil {
+			return fmt.Errorf("Invalid time zone: %s", *tz)
+		}
+	}
+	reportInterval, err := wtf.ParseReportInterval(*interval, loc)
+	if err != nil {
+		return err
+	} else if err := reportInterval.Validate(); err != nil {
+		return err
+	}
+
+	// Load the configuration.
+	config, err := LoadProfile(c.ConfigPath, c.Profile)
+	if err != nil {
+		return err
+	}
+
+	// Authenticate the user with the API key from the config.
+	ctx = wtf.NewContextWithUser(ctx, &wtf.User{APIKey: config.APIKey})
+
+	// Leave the end time unset so the server includes the current interval,
+	// aligned to the time zone, and the latest value is shown.
+	start, end := time.Now().Add(-*since), time.Time{}
+
+	// Fetch the per-dial report if a dial is specified. Otherwise fetch the
+	// average across all the user's dials.
+	title := "Average WTF level"
+	svc := http.NewDialService(http.NewClient(config.URL))
+	var report *wtf.DialValueReport
+	var dialForecast *wtf.DialValueForecast
+	if *dialID != 0 {
+		dial, err := svc.FindDialByID(ctx, *dialID)
+		if err != nil {
+			return err
+		} else if report, err = svc.DialValueReport(ctx, *dialID, start, end, reportInterval); err != nil {
+			return err
+		}
+		title = fmt.Sprintf("WTF level for %q", dial.Name)
+
+		// The forecast is projected from the same history as the report.
+		if *forecast {
+			if dialForecast, err = svc.DialValueForecast(ctx, *dialID, start, end, reportInterval, *model, *forecastN); err != nil {
+				return err
+			}
+		}
+	} else if report, err = svc.AverageDialValueReport(ctx, start, end, reportInterval); err != nil {
+		return err
+	}
+
+	// Write the raw records in a machine-readable format, if requested.
+	// Forecast records replace the report records when forecasting.
+	if !c.Output.IsTable() {
+		if dialForecast != nil {
+			return writeOutput(os.Stdout, c.Output, dialForecast.Records)
+		}
+		return writeOutput(os.Stdout, c.Output, report.Records)
+	} else if len(report.Records) == 0 {
+		fmt.Println("No data available.")
+		return nil
+	}
+
+	// Render the chart with a summary of the values.
+	fmt.Printf("%s over the last %s (%s intervals)\n\n", title, *since, *interval)
+	writeChart(os.Stdout, report.Records, dialForecast)
+	fmt.Println()
+
+	values := recordValues(report.Records)
+	min, max, mean := summarize(values)
+	fmt.Printf("%s\n\n", sparkline(values))
+	fmt.Printf("min: %d  max: %d  mean: %.1f\n", min, max, mean)
+
+	// Summarize the forecast with the final projected value.
+	if dialForecast != nil && len(dialForecast.Records) > 0 {
+		last := dialForecast.Records[len(dialForecast.Records)-1]
+		fmt.Printf("forecast (%s): %.1f by %s (95%% range %.1f-%.1f)\n",
+			dialForecast.Model, last.Value, last.Timestamp.Local().Format("Jan 02 15:04"), last.Lower, last.Upper)
+	}
+
+	return nil
+}
+
+// recordValues returns the values of each record.
+func recordValues(records []*wtf.DialValueRecord) []int {
+	values := make([]int, len(records))
+	for i, r := range records {
+		values[i] = r.Value
+	}
+	return values
+}
+
+// summarize returns the minimum, maximum & mean of values.
+func summarize(values []int) (min, max int, mean float64) {
+	if len(values) == 0 {
+		return 0, 0, 0
+	}
+
+	min, max = values[0], values[0]
+	var sum int
+	for _, v := range values {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+		sum += v
+	}
+	return min, max, float64(sum) / float64(len(values))
+}
+
+// sparkBlocks are the characters used to draw a sparkline, from lowest to highest.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline returns values as a single line of block characters. Values are
+// scaled against the full 0-100 range so lines are comparable between reports.
+func sparkline(values []int) string {
+	var sb strings.Builder
+	for _, v := range downsample(values, ChartWidth) {
+		i := v * (len(sparkBlocks) - 1) / 100
+		if i < 0 {
+			i = 0
+		} else if i >= len(sparkBlocks) {
+			i = len(sparkBlocks) - 1
+		}
+		sb.WriteRune(sparkBlocks[i])
+	}
+	return sb.String()
+}
+
+// writeChart writes an ASCII line chart of the records to w. The y-axis always
+// covers the 0-100 range of WTF levels. If forecast is set then its values are
+// plotted with dots after the records & the chart width is shared between them.
+func writeChart(w io.Writer, records []*wtf.DialValueRecord, forecast *wtf.DialValueForecast) {
+	var projected []int
+	if forecast != nil {
+		for _, record := range forecast.Records {
+			projected = append(projected, int(math.Round(record.Value)))
+		}
+	}
+
+	// Split the width in proportion to the number of values in each part.
+	width := ChartWidth
+	if total := len(records) + len(projected); total > ChartWidth && len(projected) > 0 {
+		width = ChartWidth * len(records) / total
+	}
+	values := downsample(recordValues(records), width)
+	projected = downsample(projected, ChartWidth-len(values))
+	historyN := len(values)
+	values = append(values, projected...)
+
+	// Plot a point in each column at the row closest to its value.
+	for row := ChartHeight; row >= 0; row-- {
+		label := "    "
+		if row%(ChartHeight/2) == 0 {
+			label = fmt.Sprintf("%3d ", row*100/ChartHeight)
+		}
+
+		line := make([]rune, len(values))
+		for i, v := range values {
+			line[i] = ' '
+			if (v*ChartHeight+50)/100 == row {
+				line[i] = '*'
+				if i >= historyN {
+					line[i] = '.'
+				}
+			}
+		}
+		fmt.Fprintf(w, "%s|%s\n", label, strings.TrimRight(string(line), " "))
+	}
+
+	// Draw x-axis with the first & last timestamps.
+	fmt.Fprintf(w, "    +%s\n", strings.Repeat("-", len(values)))
+
+	first := records[0].Timestamp.Local().Format("Jan 02 15:04")
+	last := records[len(records)-1].Timestamp.Local().Format("Jan 02 15:04")
+	if len(projected) > 0 {
+		last = forecast.Records[len(forecast.Records)-1].Timestamp.Local().Format("Jan 02 15:04")
+	}
+	if pad := len(values) - len(first) - len(last); pad > 0 {
+		fmt.Fprintf(w, "     %s%s%s\n", first, strings.Repeat(" ", pad), last)
+	} else {
+		fmt.Fprintf(w, "     %s - %s\n", first, last)
+	}
+}
+
+// downsample reduces values to at most n points by averaging adjacent values.
+func downsample(values []int, n int) []int {
+	if len(values) <= n {
+		return values
+	}
+
+	other := make([]int, n)
+	for i := range other {
+		lo, hi := i*len(values)/n, (i+1)*len(values)/n
+		var sum int
+		for _, v := range values[lo:hi] {
+			sum += v
+		}
+		other[i] = sum / (hi - lo)
+	}
+	return other
+}
+
+// usage prints usage information for the command to STDOUT.
+func (c *ReportCommand) usage() {
+	fmt.Println(`
+Display a chart of WTF levels over time. By default, the average level across
+all of your dials is displayed.
+
+Usage:
+
+	wtf report [arguments]
+
+Arguments:
+
+	-dial ID
+	    Display the level of a single dial instead of the average.
+
+	-since DURATION
+	    Length of the report period. Defaults to 24h.
+
+	-interval INTERVAL
+	    Size of each interval in the report. Either a duration, such as 1h,
+	    or a calendar unit: day, week or month. Defaults to 15m.
+
+	-tz NAME
+	    IANA time zone used to align calendar intervals, such as
+	    America/New_York. Defaults to the time zone in your profile.
+
+	-forecast
+	    Project the dial's values forward from the report's history.
+	    Requires -dial. Projected values are plotted with dots.
+
+	-forecast-n N
+	    Number of intervals to forecast. Defaults to 24.
+
+	-model MODEL
+	    Forecast model: linear or holt-winters. Holt-Winters follows a
+	    daily cycle and requires two days of history. Defaults to
+	    holt-winters when there is enough history, otherwise linear.
+
+	-format FORMAT
+	    Output format: table, json, csv or template.
+
+	-template TEXT
+	    Go template executed for each record. Requires "-format template".
+`[1:])
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/benbjohnson/wtf/sqlite"
+)
+
+// BackupCommand writes a snapshot of the database while the server is running.
+type BackupCommand struct {
+	ConfigPath string
+}
+
+// Run executes the command.
+func (c *BackupCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("wtfd-backup", flag.ContinueOnError)
+	fs.StringVar(&c.ConfigPath, "config", DefaultConfigPath, "config path")
+	fs.Usage = c.usage
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() > 1 {
+		return fmt.Errorf("too many arguments")
+	}
+
+	config, dsn, err := loadCommandConfig(c.ConfigPath)
+	if err != nil {
+		return err
+	}
+
+	// Write to the given path. Otherwise use a timestamped file in the
+	// configured backup directory.
+	filename := fs.Arg(0)
+	if filename == "" {
+		if config.Backup.Dir == "" {
+			return fmt.Errorf("backup path required")
+		}
+		dir, err := expand(config.Backup.Dir)
+		if err != nil {
+			return err
+		} else if err := os.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+		filename = sqlite.SnapshotPath(dir, time.Now())
+	}
+
+	if err := sqlite.Backup(ctx, dsn, filename); err != nil {
+		return fmt.Errorf("backup: %w", err)
+	}
+	fmt.Printf("Database backed up to %s\n", filename)
+	return nil
+}
+
+// usage prints usage information for the command to STDOUT.
+func (c *BackupCommand) usage() {
+	fmt.Println(`
+Write a consistent snapshot of the database using the SQLite online backup
+API. This is safe to run while the server is running.
+
+Usage:
+
+	wtfd backup [-config PATH] [FILE]
+
+If FILE is not specified then a timestamped snapshot is written to the
+backup directory set in the config file.
+`[1:])
+}
+
+// RestoreCommand replaces the database with a snapshot.
+type RestoreCommand struct {
+	ConfigPath string
+}
+
+// Run executes the command.
+func (c *RestoreCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("wtfd-restore", flag.ContinueOnError)
+	fs.StringVar(&c.ConfigPath, "config", DefaultConfigPath, "config path")
+	fs.Usage = c.usage
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() == 0 {
+		return fmt.Errorf("backup path required")
+	} else if fs.NArg() > 1 {
+		return fmt.Errorf("too many arguments")
+	}
+
+	_, dsn, err := loadCommandConfig(c.ConfigPath)
+	if err != nil {
+		return err
+	}
+
+	if err := sqlite.Restore(ctx, fs.Arg(0), dsn); err != nil {
+		return fmt.Errorf("restore: %w", err)
+	}
+
+	// Report any migrations which will be applied on the next server start.
+	migrations, err := sqlite.Migrations(ctx, dsn)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Database restored from %s\n", fs.Arg(0))
+	if n := countPendingMigrations(migrations); n > 0 {
+		fmt.Printf("%d pending migration(s) will run when the server starts.\n", n)
+	}
+	return nil
+}
+
+// usage prints usage information for the command to STDOUT.
+func (c *RestoreCommand) usage() {
+	fmt.Println(`
+Replace the database with a snapshot created by "wtfd backup". The server
+must be stopped before restoring.
+
+The snapshot is rejected if it was migrated by a newer version of wtfd.
+
+Usage:
+
+	wtfd restore [-config PATH] FILE
+`[1:])
+}
+
+// startSnapshots validates the backup configuration and starts a goroutine to
+// periodically snapshot the database until ctx is canceled.
+func (m *Main) startSnapshots(ctx context.Context) error {
+	dir, err := expand(m.Config.Backup.Dir)
+	if err != nil {
+		return err
+	}
+
+	interval, err := time.ParseDuration(m.Config.Backup.Interval)
+	if err != nil {
+		return fmt.Errorf("invalid backup interval: %w", err)
+	} else if interval <= 0 {
+		return fmt.Errorf("backup interval must be greater than zero")
+	} else if m.Config.Backup.Retain <= 0 {
+		return fmt.Errorf("backup retention must be greater than zero")
+	}
+
+	go m.monitorSnapshots(ctx, dir, interval, m.Config.Backup.Retain)
+
+	log.Printf("snapshots enabled: dir=%q interval=%s retain=%d", dir, interval, m.Config.Backup.Retain)
+	return nil
+}
+
+// monitorSnapshots runs in a goroutine and snapshots the database on every
+// interval. Old snapshots are removed so that only retain snapshots remain.
+func (m *Main) monitorSnapshots(ctx context.Context, dir string, interval time.Duration, retain int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		filename, err := m.DB.Snapshot(ctx, dir)
+		if err != nil {
+			log.Printf("snapshot error: %s", err)
+			continue
+		}
+
+		removed, err := sqlite.PruneSnapshots(dir, retain)
+		if err != nil {
+			log.Printf("snapshot prune error: %s", err)
+		}
+		log.Printf("snapshot written: path=%q pruned=%d", filename, len(removed))
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/benbjohnson/wtf/sqlite"
+)
+
+// RunCommand executes an administrative subcommand by name.
+func RunCommand(ctx context.Context, cmd string, args []string) error {
+	switch cmd {
+	case "backup":
+		return (&BackupCommand{}).Run(ctx, args)
+	case "dial":
+		return (&DialCommand{}).Run(ctx, args)
+	case "gen-keys":
+		return (&GenKeysCommand{}).Run(ctx, args)
+	case "migrate":
+		return (&MigrateCommand{}).Run(ctx, args)
+	case "restore":
+		return (&RestoreCommand{}).Run(ctx, args)
+	case "user":
+		return (&UserCommand{}).Run(ctx, args)
+	case "help":
+		usage()
+		return flag.ErrHelp
+	default:
+		return fmt.Errorf("wtfd %s: unknown command", cmd)
+	}
+}
+
+// usage prints the top-level usage message for wtfd.
+func usage() {
+	fmt.Println(`
+WTF Dial server.
+
+Usage:
+
+	wtfd [-config PATH]
+	wtfd <command> [arguments]
+
+The commands are:
+
+	backup      write a consistent snapshot of the database
+	dial        list dials
+	gen-keys    generate secure cookie keys for the config file
+	migrate     show or apply database migrations
+	restore     replace the database with a snapshot
+	user        manage users
+
+Commands other than gen-keys operate directly on the database set in the
+config file and bypass per-user permission checks.
+`[1:])
+}
+
+// loadCommandConfig reads the config file for an administrative command and
+// returns the config along with the expanded database path.
+func loadCommandConfig(path string) (Config, string, error) {
+	configPath, err := expand(path)
+	if err != nil {
+		return Config{}, "", err
+	}
+
+	config, err := ReadConfigFile(configPath)
+	if os.IsNotExist(err) {
+		return config, "", fmt.Errorf("config file not found: %s", path)
+	} else if err != nil {
+		return config, "", err
+	}
+
+	dsn, err := expandDSN(config.DB.DSN)
+	if err != nil {
+		return config, "", fmt.Errorf("cannot expand dsn: %w", err)
+	} else if dsn == ":memory:" {
+		return config, "", fmt.Errorf("cannot use in-memory database")
+	}
+	return config, dsn, nil
+}
+
+// openCommandDB opens the database from the config file at path. Pending
+// migrations are applied when the database is opened.
+func openCommandDB(path string) (*sqlite.DB, error) {
+	_, dsn, err := loadCommandConfig(path)
+	if err != nil {
+		return nil, err
+	} else if _, err := os.Stat(dsn); os.IsNotExist(err) {
+		return nil, fmt.Errorf("database not found: %s", dsn)
+	}
+
+	db := sqlite.NewDB(dsn)
+	if err := db.Open(); err != nil {
+		return nil, fmt.Errorf("cannot open db: %w", err)
+	}
+	return db, nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/sqlite"
+)
+
+// DialCommand is a command for inspecting dials.
+type DialCommand struct{}
+
+// Run executes the command.
+func (c *DialCommand) Run(ctx context.Context, args []string) error {
+	var cmd string
+	if len(args) > 0 {
+		cmd, args = args[0], args[1:]
+	}
+
+	switch cmd {
+	case "list":
+		return (&DialListCommand{}).Run(ctx, args)
+	case "", "-h", "help":
+		c.usage()
+		return flag.ErrHelp
+	default:
+		return fmt.Errorf("wtfd dial %s: unknown command", cmd)
+	}
+}
+
+// usage prints usage information for the command to STDOUT.
+func (c *DialCommand) usage() {
+	fmt.Println(`
+Inspect dials.
+
+Usage:
+
+	wtfd dial list [-config PATH] (-all | -user ID)
+
+Arguments:
+
+	-all
+	    List every dial in the database.
+
+	-user ID
+	    List the dials that a user owns or is a member of.
+`[1:])
+}
+
+// DialListCommand lists dials for a user or for the whole database.
+type DialListCommand struct {
+	ConfigPath string
+}
+
+// Run executes the command.
+func (c *DialListCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("wtfd-dial-list", flag.ContinueOnError)
+	fs.StringVar(&c.ConfigPath, "config", DefaultConfigPath, "config path")
+	all := fs.Bool("all", false, "list all dials")
+	userID := fs.Int("user", 0, "list dials for user id")
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if *all == (*userID != 0) {
+		return fmt.Errorf("either -all or -user is required")
+	}
+
+	db, err := openCommandDB(c.ConfigPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// Either list everything with admin privileges or impersonate the user
+	// so that only the dials they can see are returned.
+	if *all {
+		ctx = wtf.NewContextWithAdmin(ctx)
+	} else {
+		user, err := sqlite.NewUserService(db).FindUserByID(ctx, *userID)
+		if err != nil {
+			return err
+		}
+		ctx = wtf.NewContextWithUser(ctx, user)
+	}
+
+	dials, _, err := sqlite.NewDialService(db).FindDials(ctx, wtf.DialFilter{})
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tOWNER\tVALUE")
+	for _, dial := range dials {
+		fmt.Fprintf(w, "%d\t%s\t%d\t%d\n",
+			dial.ID,
+			dial.Name,
+			dial.UserID,
+			dial.Value,
+		)
+	}
+	return w.Flush()
+}
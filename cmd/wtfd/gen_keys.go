@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+)
+
+// Secure cookie key sizes, in bytes. The hash key is used for HMAC-SHA512
+// authentication and the block key selects AES-256 encryption.
+const (
+	HashKeySize  = 64
+	BlockKeySize = 32
+)
+
+// GenKeysCommand generates random hash & block keys for the [http] section
+// of the config file.
+type GenKeysCommand struct{}
+
+// Run executes the command.
+func (c *GenKeysCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("wtfd-gen-keys", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	hashKey, err := generateKey(HashKeySize)
+	if err != nil {
+		return err
+	}
+	blockKey, err := generateKey(BlockKeySize)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("[http]")
+	fmt.Printf("hash-key  = %q\n", hashKey)
+	fmt.Printf("block-key = %q\n", blockKey)
+	return nil
+}
+
+// generateKey returns n random bytes encoded as hex.
+func generateKey(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(rand.Reader, buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
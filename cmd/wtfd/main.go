@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io/ioutil"
@@ -11,8 +12,10 @@ import (
 	"os/user"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/cache"
 	"github.com/benbjohnson/wtf/http"
 	"github.com/benbjohnson/wtf/http/html"
 	"github.com/benbjohnson/wtf/inmem"
@@ -40,6 +43,22 @@ func main() {
 	signal.Notify(c, os.Interrupt)
 	go func() { <-c; cancel() }()
 
+	// Execute an administrative subcommand, if one is specified. These run
+	// against the database directly & exit instead of starting the server.
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		var e *wtf.Error
+		if err := RunCommand(ctx, os.Args[1], os.Args[2:]); err == flag.ErrHelp {
+			os.Exit(1)
+		} else if errors.As(err, &e) {
+			fmt.Fprintln(os.Stderr, e.Message)
+			os.Exit(1)
+		} else if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Instantiate a new type to represent our application.
 	// This type lets us shared setup code with our end-to-end tests.
 	m := NewMain()
@@ -167,22 +186,62 @@ func (m *Main) Run(ctx context.Context) (err error) {
 	// Attach our event service to the SQLite database so it can publish events.
 	m.DB.EventService = eventService
 
+	// Cache service results per user, if enabled. Events published by the
+	// database pass through the cache so changes invalidate cached results.
+	var resultCache *cache.Cache
+	if v := m.Config.Cache.TTL; v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid cache ttl: %w", err)
+		} else if ttl > 0 {
+			resultCache = cache.NewCache()
+			resultCache.TTL = ttl
+			m.DB.EventService = cache.NewEventService(resultCache, eventService)
+		}
+	}
+
 	// Expand the DSN (in case it is in the user home directory ("~")).
 	// Then open the database. This will instantiate the SQLite connection
 	// and execute any pending migration files.
 	if m.DB.DSN, err = expandDSN(m.Config.DB.DSN); err != nil {
 		return fmt.Errorf("cannot expand dsn: %w", err)
 	}
+	if v := m.Config.DB.RawRetention; v != "" {
+		if m.DB.RawRetention, err = time.ParseDuration(v); err != nil {
+			return fmt.Errorf("invalid raw retention: %w", err)
+		}
+	}
+	if m.Config.Anomaly.Threshold > 0 {
+		if v := m.Config.Anomaly.Alpha; v <= 0 || v > 1 {
+			return fmt.Errorf("invalid anomaly alpha: %v", v)
+		}
+		m.DB.AnomalyDetector.Threshold = m.Config.Anomaly.Threshold
+		m.DB.AnomalyDetector.Alpha = m.Config.Anomaly.Alpha
+		m.DB.AnomalyDetector.Warmup = m.Config.Anomaly.Warmup
+	} else {
+		m.DB.AnomalyDetector = nil
+	}
 	if err := m.DB.Open(); err != nil {
 		return fmt.Errorf("cannot open db: %w", err)
 	}
 
 	// Instantiate SQLite-backed services.
 	authService := sqlite.NewAuthService(m.DB)
-	dialService := sqlite.NewDialService(m.DB)
-	dialMembershipService := sqlite.NewDialMembershipService(m.DB)
+	deviceAuthorizationService := sqlite.NewDeviceAuthorizationService(m.DB)
+	dialAnomalyService := sqlite.NewDialAnomalyService(m.DB)
+	sqliteDialService := sqlite.NewDialService(m.DB)
+	sqliteDialMembershipService := sqlite.NewDialMembershipService(m.DB)
+	idempotencyKeyService := sqlite.NewIdempotencyKeyService(m.DB)
 	userService := sqlite.NewUserService(m.DB)
 
+	// Wrap dial services with caching decorators.
+	var dialService wtf.DialService = sqliteDialService
+	var dialMembershipService wtf.DialMembershipService = sqliteDialMembershipService
+	if resultCache != nil {
+		dialService = cache.NewDialService(resultCache, dialService)
+		dialMembershipService = cache.NewDialMembershipService(resultCache, dialMembershipService)
+	}
+
 	// Attach user service to Main for testing.
 	m.UserService = userService
 
@@ -199,9 +258,12 @@ func (m *Main) Run(ctx context.Context) (err error) {
 
 	// Attach underlying services to the HTTP server.
 	m.HTTPServer.AuthService = authService
+	m.HTTPServer.DeviceAuthorizationService = deviceAuthorizationService
+	m.HTTPServer.DialAnomalyService = dialAnomalyService
 	m.HTTPServer.DialService = dialService
 	m.HTTPServer.DialMembershipService = dialMembershipService
 	m.HTTPServer.EventService = eventService
+	m.HTTPServer.IdempotencyKeyService = idempotencyKeyService
 	m.HTTPServer.UserService = userService
 
 	// Start the HTTP server.
@@ -219,6 +281,13 @@ func (m *Main) Run(ctx context.Context) (err error) {
 	// Enable internal debug endpoints.
 	go func() { http.ListenAndServeDebug() }()
 
+	// Take periodic snapshots of the database, if enabled.
+	if m.Config.Backup.Dir != "" {
+		if err := m.startSnapshots(ctx); err != nil {
+			return fmt.Errorf("cannot start snapshots: %w", err)
+		}
+	}
+
 	log.Printf("running: url=%q debug=http://localhost:6060 dsn=%q", m.HTTPServer.URL(), m.Config.DB.DSN)
 
 	return nil
@@ -230,12 +299,25 @@ const (
 
 	// DefaultDSN is the default datasource name.
 	DefaultDSN = "~/.wtfd/db"
+
+	// DefaultBackupInterval is the default time between scheduled snapshots.
+	DefaultBackupInterval = "1h"
+
+	// DefaultBackupRetain is the default number of snapshots to keep.
+	DefaultBackupRetain = 24
+
+	// DefaultCacheTTL is the default time that service results are cached.
+	DefaultCacheTTL = "30s"
 )
 
 // Config represents the CLI configuration file.
 type Config struct {
 	DB struct {
 		DSN string `toml:"dsn"`
+
+		// Duration to keep per-minute dial values, such as "720h". Older
+		// values are only kept as hourly & daily rollups. Empty keeps forever.
+		RawRetention string `toml:"raw-retention"`
 	} `toml:"db"`
 
 	HTTP struct {
@@ -245,6 +327,25 @@ type Config struct {
 		BlockKey string `toml:"block-key"`
 	} `toml:"http"`
 
+	// Scheduled database snapshots. Disabled unless a directory is set.
+	Backup struct {
+		Dir      string `toml:"dir"`
+		Interval string `toml:"interval"`
+		Retain   int    `toml:"retain"`
+	} `toml:"backup"`
+
+	// Per-user caching of dial results. A blank or "0" TTL disables caching.
+	Cache struct {
+		TTL string `toml:"ttl"`
+	} `toml:"cache"`
+
+	// Detection of abnormal dial values. A threshold of "0" disables detection.
+	Anomaly struct {
+		Threshold float64 `toml:"threshold"`
+		Alpha     float64 `toml:"alpha"`
+		Warmup    int     `toml:"warmup"`
+	} `toml:"anomaly"`
+
 	GoogleAnalytics struct {
 		MeasurementID string `toml:"measurement-id"`
 	} `toml:"google-analytics"`
@@ -263,6 +364,12 @@ type Config struct {
 func DefaultConfig() Config {
 	var config Config
 	config.DB.DSN = DefaultDSN
+	config.Backup.Interval = DefaultBackupInterval
+	config.Backup.Retain = DefaultBackupRetain
+	config.Cache.TTL = DefaultCacheTTL
+	config.Anomaly.Threshold = wtf.DefaultAnomalyThreshold
+	config.Anomaly.Alpha = wtf.DefaultAnomalyAlpha
+	config.Anomaly.Warmup = wtf.DefaultAnomalyWarmup
 	return config
 }
 
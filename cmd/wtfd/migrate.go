@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/benbjohnson/wtf/sqlite"
+)
+
+// MigrateCommand is a command for inspecting & applying database migrations.
+type MigrateCommand struct{}
+
+// Run executes the command.
+func (c *MigrateCommand) Run(ctx context.Context, args []string) error {
+	var cmd string
+	if len(args) > 0 {
+		cmd, args = args[0], args[1:]
+	}
+
+	switch cmd {
+	case "down":
+		return (&MigrateDownCommand{}).Run(ctx, args)
+	case "status":
+		return (&MigrateStatusCommand{}).Run(ctx, args)
+	case "up":
+		return (&MigrateUpCommand{}).Run(ctx, args)
+	case "", "-h", "help":
+		c.usage()
+		return flag.ErrHelp
+	default:
+		return fmt.Errorf("wtfd migrate %s: unknown command", cmd)
+	}
+}
+
+// usage prints usage information for the command to STDOUT.
+func (c *MigrateCommand) usage() {
+	fmt.Println(`
+Show or apply database migrations.
+
+Usage:
+
+	wtfd migrate <command> [-config PATH] [arguments]
+
+The commands are:
+
+	down        roll back migrations newer than a version
+	status      list migrations and whether they have been applied
+	up          apply all pending migrations
+
+The "up" & "down" commands accept a "-dry-run" flag to list the migrations
+which would be executed without changing the database.
+`[1:])
+}
+
+// MigrateStatusCommand lists embedded migrations and their applied state.
+type MigrateStatusCommand struct {
+	ConfigPath string
+}
+
+// Run executes the command.
+func (c *MigrateStatusCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("wtfd-migrate-status", flag.ContinueOnError)
+	fs.StringVar(&c.ConfigPath, "config", DefaultConfigPath, "config path")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	_, dsn, err := loadCommandConfig(c.ConfigPath)
+	if err != nil {
+		return err
+	} else if _, err := os.Stat(dsn); os.IsNotExist(err) {
+		return fmt.Errorf("database not found: %s", dsn)
+	}
+
+	migrations, err := sqlite.Migrations(ctx, dsn)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		status := "pending"
+		if m.Modified {
+			status = "modified"
+		} else if m.Applied {
+			status = "applied"
+		}
+
+		var note string
+		if !m.Reversible {
+			note = " (irreversible)"
+		}
+		fmt.Printf("%-8s %s%s\n", status, m.Version, note)
+	}
+	fmt.Printf("\n%d of %d migrations pending.\n", countPendingMigrations(migrations), len(migrations))
+	return nil
+}
+
+// MigrateUpCommand applies all pending migrations.
+type MigrateUpCommand struct {
+	ConfigPath string
+}
+
+// Run executes the command.
+func (c *MigrateUpCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("wtfd-migrate-up", flag.ContinueOnError)
+	fs.StringVar(&c.ConfigPath, "config", DefaultConfigPath, "config path")
+	dryRun := fs.Bool("dry-run", false, "list pending migrations only")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	_, dsn, err := loadCommandConfig(c.ConfigPath)
+	if err != nil {
+		return err
+	}
+
+	// Determine pending migrations before opening since the database is
+	// migrated as part of the open.
+	migrations, err := sqlite.Migrations(ctx, dsn)
+	if err != nil {
+		return err
+	}
+
+	status := "applied"
+	if *dryRun {
+		status = "pending"
+	} else {
+		db := sqlite.NewDB(dsn)
+		if err := db.Open(); err != nil {
+			return fmt.Errorf("cannot open db: %w", err)
+		}
+		defer db.Close()
+	}
+
+	for _, m := range migrations {
+		if !m.Applied {
+			fmt.Printf("%-8s %s\n", status, m.Version)
+		}
+	}
+	fmt.Printf("\n%d migrations %s.\n", countPendingMigrations(migrations), status)
+	return nil
+}
+
+// MigrateDownCommand rolls back migrations newer than a given version.
+type MigrateDownCommand struct {
+	ConfigPath string
+}
+
+// Run executes the command.
+func (c *MigrateDownCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("wtfd-migrate-down", flag.ContinueOnError)
+	fs.StringVar(&c.ConfigPath, "config", DefaultConfigPath, "config path")
+	to := fs.String("to", "", "version to roll back to")
+	dryRun := fs.Bool("dry-run", false, "list migrations to roll back only")
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if *to == "" {
+		return fmt.Errorf("target version required, use -to")
+	}
+
+	_, dsn, err := loadCommandConfig(c.ConfigPath)
+	if err != nil {
+		return err
+	} else if _, err := os.Stat(dsn); os.IsNotExist(err) {
+		return fmt.Errorf("database not found: %s", dsn)
+	}
+
+	migrations, err := sqlite.Rollback(ctx, dsn, *to, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	status := "reverted"
+	if *dryRun {
+		status = "pending"
+	}
+	for _, m := range migrations {
+		fmt.Printf("%-8s %s\n", status, m.Version)
+	}
+	fmt.Printf("\n%d migrations %s.\n", len(migrations), status)
+	return nil
+}
+
+// countPendingMigrations returns the number of migrations not yet applied.
+func countPendingMigrations(migrations []*sqlite.Migration) int {
+	var n int
+	for _, m := range migrations {
+		if !m.Applied {
+			n++
+		}
+	}
+	return n
+}
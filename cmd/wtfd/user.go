@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/sqlite"
+)
+
+// UserCommand is a command for managing users.
+type UserCommand struct{}
+
+// Run executes the command.
+func (c *UserCommand) Run(ctx context.Context, args []string) error {
+	var cmd string
+	if len(args) > 0 {
+		cmd, args = args[0], args[1:]
+	}
+
+	switch cmd {
+	case "delete":
+		return (&UserDeleteCommand{}).Run(ctx, args)
+	case "list":
+		return (&UserListCommand{}).Run(ctx, args)
+	case "reset-api-key":
+		return (&UserResetAPIKeyCommand{}).Run(ctx, args)
+	case "show":
+		return (&UserShowCommand{}).Run(ctx, args)
+	case "", "-h", "help":
+		c.usage()
+		return flag.ErrHelp
+	default:
+		return fmt.Errorf("wtfd user %s: unknown command", cmd)
+	}
+}
+
+// usage prints usage information for the command to STDOUT.
+func (c *UserCommand) usage() {
+	fmt.Println(`
+Manage users.
+
+Usage:
+
+	wtfd user <command> [-config PATH] [arguments]
+
+The commands are:
+
+	delete          permanently delete a user and their dials
+	list            list all users
+	reset-api-key   replace a user's API key
+	show            display a single user
+`[1:])
+}
+
+// UserListCommand lists all users.
+type UserListCommand struct {
+	ConfigPath string
+}
+
+// Run executes the command.
+func (c *UserListCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("wtfd-user-list", flag.ContinueOnError)
+	fs.StringVar(&c.ConfigPath, "config", DefaultConfigPath, "config path")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	db, err := openCommandDB(c.ConfigPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	users, _, err := sqlite.NewUserService(db).FindUsers(wtf.NewContextWithAdmin(ctx), wtf.UserFilter{})
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tEMAIL\tCREATED")
+	for _, user := range users {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n",
+			user.ID,
+			user.Name,
+			user.Email,
+			user.CreatedAt.Format(time.RFC3339),
+		)
+	}
+	return w.Flush()
+}
+
+// UserShowCommand displays a single user.
+type UserShowCommand struct {
+	ConfigPath string
+}
+
+// Run executes the command.
+func (c *UserShowCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("wtfd-user-show", flag.ContinueOnError)
+	fs.StringVar(&c.ConfigPath, "config", DefaultConfigPath, "config path")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	id, err := parseUserIDArg(fs)
+	if err != nil {
+		return err
+	}
+
+	db, err := openCommandDB(c.ConfigPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	user, err := sqlite.NewUserService(db).FindUserByID(wtf.NewContextWithAdmin(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	sources := make([]string, len(user.Auths))
+	for i, auth := range user.Auths {
+		sources[i] = auth.Source
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "ID:\t%d\n", user.ID)
+	fmt.Fprintf(w, "Name:\t%s\n", user.Name)
+	fmt.Fprintf(w, "Email:\t%s\n", user.Email)
+	fmt.Fprintf(w, "Auth:\t%s\n", strings.Join(sources, ", "))
+	fmt.Fprintf(w, "Created:\t%s\n", user.CreatedAt.Format(time.RFC3339))
+	fmt.Fprintf(w, "Updated:\t%s\n", user.UpdatedAt.Format(time.RFC3339))
+	return w.Flush()
+}
+
+// UserDeleteCommand permanently deletes a user.
+type UserDeleteCommand struct {
+	ConfigPath string
+}
+
+// Run executes the command.
+func (c *UserDeleteCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("wtfd-user-delete", flag.ContinueOnError)
+	fs.StringVar(&c.ConfigPath, "config", DefaultConfigPath, "config path")
+	yes := fs.Bool("yes", false, "confirm deletion")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	id, err := parseUserIDArg(fs)
+	if err != nil {
+		return err
+	} else if !*yes {
+		return fmt.Errorf("deleting a user also deletes their dials; pass -yes to confirm")
+	}
+
+	db, err := openCommandDB(c.ConfigPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := sqlite.NewUserService(db).DeleteUser(wtf.NewContextWithAdmin(ctx), id); err != nil {
+		return err
+	}
+	fmt.Printf("User %d deleted.\n", id)
+	return nil
+}
+
+// UserResetAPIKeyCommand replaces a user's API key.
+type UserResetAPIKeyCommand struct {
+	ConfigPath string
+}
+
+// Run executes the command.
+func (c *UserResetAPIKeyCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("wtfd-user-reset-api-key", flag.ContinueOnError)
+	fs.StringVar(&c.ConfigPath, "config", DefaultConfigPath, "config path")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	id, err := parseUserIDArg(fs)
+	if err != nil {
+		return err
+	}
+
+	db, err := openCommandDB(c.ConfigPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	user, err := sqlite.NewUserService(db).ResetAPIKey(wtf.NewContextWithAdmin(ctx), id)
+	if err != nil {
+		return err
+	}
+	fmt.Println(user.APIKey)
+	return nil
+}
+
+// parseUserIDArg returns the user ID passed as the only positional argument.
+func parseUserIDArg(fs *flag.FlagSet) (int, error) {
+	if fs.NArg() == 0 {
+		return 0, fmt.Errorf("user id required")
+	} else if fs.NArg() > 1 {
+		return 0, fmt.Errorf("too many arguments")
+	}
+
+	id, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return 0, fmt.Errorf("invalid user id: %q", fs.Arg(0))
+	}
+	return id, nil
+}
@@ -19,6 +19,11 @@ const (
 	// related but both the "http" and "http/html" packages use it so it is
 	// easier to move it to the root.
 	flashContextKey
+
+	// Marks the context as being used for administrative tasks, such as
+	// managing users from the server command line. Permission checks which
+	// restrict access to the current user are skipped.
+	adminContextKey
 )
 
 // NewContextWithUser returns a new context with the given user.
@@ -41,6 +46,18 @@ func UserIDFromContext(ctx context.Context) int {
 	return 0
 }
 
+// NewContextWithAdmin returns a new context with administrative privileges.
+// This should only be used by trusted code such as server commands.
+func NewContextWithAdmin(ctx context.Context) context.Context {
+	return context.WithValue(ctx, adminContextKey, true)
+}
+
+// IsAdminContext returns true if the context has administrative privileges.
+func IsAdminContext(ctx context.Context) bool {
+	v, _ := ctx.Value(adminContextKey).(bool)
+	return v
+}
+
 // NewContextWithFlash returns a new context with the given flash value.
 func NewContextWithFlash(ctx context.Context, v string) context.Context {
 	return context.WithValue(ctx, flashContextKey, v)
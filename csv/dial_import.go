@@ -0,0 +1,128 @@
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// DialImportDecoder decodes dial import rows in CSV format from a reader.
+//
+// The first record must be a header. The "name" column is required and the
+// "value" & "timestamp" columns are optional. If there is no "timestamp"
+// column then "updated_at" is used so that files written by DialEncoder can
+// be imported directly. Columns may appear in any order & unknown columns are
+// ignored.
+type DialImportDecoder struct {
+	r *csv.Reader
+
+	// Column indexes, or -1 if unused. Set after reading the header.
+	columns map[string]int
+
+	// Current row number. The header is row 1.
+	row int
+}
+
+// NewDialImportDecoder returns a new instance of DialImportDecoder that reads from r.
+func NewDialImportDecoder(r io.Reader) *DialImportDecoder {
+	dec := &DialImportDecoder{r: csv.NewReader(r)}
+	dec.r.FieldsPerRecord = -1
+	dec.r.TrimLeadingSpace = true
+	return dec
+}
+
+// Decode reads the next row into row. Returns io.EOF when no rows remain.
+//
+// If the row is malformed then an EINVALID error is returned with row.Row set
+// so the caller can report it & continue decoding. Errors reading the header
+// are returned with row.Row set to zero and decoding cannot continue.
+func (dec *DialImportDecoder) Decode(row *wtf.DialImportRow) error {
+	if dec.columns == nil {
+		if err := dec.readHeader(); err != nil {
+			return err
+		}
+	}
+
+	// Read next record & skip blank lines.
+	record, err := dec.r.Read()
+	dec.row++
+	var perr *csv.ParseError
+	if err == io.EOF {
+		return err
+	} else if errors.As(err, &perr) {
+		row.Row = dec.row
+		return wtf.Errorf(wtf.EINVALID, "Invalid CSV: %s", perr.Err)
+	} else if err != nil {
+		return err
+	}
+
+	*row = wtf.DialImportRow{Row: dec.row, Name: dec.field(record, "name")}
+
+	// Parse optional value & timestamp.
+	if s := dec.field(record, "value"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			return wtf.Errorf(wtf.EINVALID, "Invalid value: %q", s)
+		}
+		row.Value = &v
+	}
+	if s := dec.field(record, "timestamp"); s != "" {
+		t, err := parseTime(s)
+		if err != nil {
+			return wtf.Errorf(wtf.EINVALID, "Invalid timestamp: %q", s)
+		}
+		row.Timestamp = &t
+	}
+	return nil
+}
+
+// readHeader reads the column names from the first record.
+func (dec *DialImportDecoder) readHeader() error {
+	header, err := dec.r.Read()
+	dec.row++
+	if err == io.EOF {
+		return wtf.Errorf(wtf.EINVALID, "CSV header required.")
+	} else if err != nil {
+		return wtf.Errorf(wtf.EINVALID, "Invalid CSV header: %s", err)
+	}
+
+	dec.columns = map[string]int{"name": -1, "value": -1, "timestamp": -1}
+	updatedAt := -1
+	for i, name := range header {
+		switch name = strings.ToLower(strings.TrimSpace(name)); name {
+		case "name", "value", "timestamp":
+			dec.columns[name] = i
+		case "updated_at":
+			updatedAt = i
+		}
+	}
+
+	if dec.columns["name"] == -1 {
+		return wtf.Errorf(wtf.EINVALID, "CSV header must include a name column.")
+	} else if dec.columns["timestamp"] == -1 && dec.columns["value"] != -1 {
+		dec.columns["timestamp"] = updatedAt
+	}
+	return nil
+}
+
+// field returns the trimmed value of a named column in record. Returns a
+// blank string if the column is not used or is missing from the record.
+func (dec *DialImportDecoder) field(record []string, name string) string {
+	if i := dec.columns[name]; i >= 0 && i < len(record) {
+		return strings.TrimSpace(record[i])
+	}
+	return ""
+}
+
+// parseTime parses s as an RFC 3339 timestamp or as a date in UTC.
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
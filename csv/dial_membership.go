@@ -0,0 +1,63 @@
+package csv
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// DialMembershipEncoder encodes dial membership information in CSV format to a writer.
+type DialMembershipEncoder struct {
+	w *csv.Writer
+}
+
+// NewDialMembershipEncoder returns a new instance of DialMembershipEncoder that writes to w.
+func NewDialMembershipEncoder(w io.Writer) *DialMembershipEncoder {
+	enc := &DialMembershipEncoder{w: csv.NewWriter(w)}
+
+	// Write header to underlying writer.
+	_ = enc.w.Write([]string{
+		"id",
+		"dial_id",
+		"dial_name",
+		"user_id",
+		"user_name",
+		"value",
+		"created_at",
+		"updated_at",
+	})
+
+	return enc
+}
+
+// Close flushes the underlying writer.
+func (enc *DialMembershipEncoder) Close() error {
+	enc.w.Flush()
+	return enc.w.Error()
+}
+
+// EncodeDialMembership encodes a membership row to the underlying CSV writer.
+// The dial & user names are left blank if the associations are not attached.
+func (enc *DialMembershipEncoder) EncodeDialMembership(membership *wtf.DialMembership) error {
+	var dialName, userName string
+	if membership.Dial != nil {
+		dialName = membership.Dial.Name
+	}
+	if membership.User != nil {
+		userName = membership.User.Name
+	}
+
+	return enc.w.Write([]string{
+		strconv.Itoa(membership.ID),
+		strconv.Itoa(membership.DialID),
+		dialName,
+		strconv.Itoa(membership.UserID),
+		userName,
+		strconv.Itoa(membership.Value),
+		membership.CreatedAt.Format(time.RFC3339),
+		membership.UpdatedAt.Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,64 @@
+package csv
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// DialValueReportEncoder encodes dial value report records in CSV format to a writer.
+type DialValueReportEncoder struct {
+	w *csv.Writer
+}
+
+// NewDialValueReportEncoder returns a new instance of DialValueReportEncoder that writes to w.
+func NewDialValueReportEncoder(w io.Writer) *DialValueReportEncoder {
+	enc := &DialValueReportEncoder{w: csv.NewWriter(w)}
+
+	// Write header to underlying writer.
+	_ = enc.w.Write([]string{
+		"timestamp",
+		"value",
+		"min",
+		"max",
+		"median",
+		"stddev",
+		"count",
+		"members",
+	})
+
+	return enc
+}
+
+// Close flushes the underlying writer.
+func (enc *DialValueReportEncoder) Close() error {
+	enc.w.Flush()
+	return enc.w.Error()
+}
+
+// EncodeDialValueReport encodes each record in the report to the underlying CSV writer.
+func (enc *DialValueReportEncoder) EncodeDialValueReport(report *wtf.DialValueReport) error {
+	for _, record := range report.Records {
+		if err := enc.EncodeDialValueRecord(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EncodeDialValueRecord encodes a single record row to the underlying CSV writer.
+func (enc *DialValueReportEncoder) EncodeDialValueRecord(record *wtf.DialValueRecord) error {
+	return enc.w.Write([]string{
+		record.Timestamp.UTC().Format(time.RFC3339),
+		strconv.Itoa(record.Value),
+		strconv.Itoa(record.Min),
+		strconv.Itoa(record.Max),
+		strconv.FormatFloat(record.Median, 'f', -1, 64),
+		strconv.FormatFloat(record.StdDev, 'f', -1, 64),
+		strconv.Itoa(record.Count),
+		strconv.Itoa(record.Members),
+	})
+}
@@ -0,0 +1,49 @@
+package csv
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// UserEncoder encodes user information in CSV format to a writer.
+// API keys are never encoded.
+type UserEncoder struct {
+	w *csv.Writer
+}
+
+// NewUserEncoder returns a new instance of UserEncoder that writes to w.
+func NewUserEncoder(w io.Writer) *UserEncoder {
+	enc := &UserEncoder{w: csv.NewWriter(w)}
+
+	// Write header to underlying writer.
+	_ = enc.w.Write([]string{
+		"id",
+		"name",
+		"email",
+		"created_at",
+		"updated_at",
+	})
+
+	return enc
+}
+
+// Close flushes the underlying writer.
+func (enc *UserEncoder) Close() error {
+	enc.w.Flush()
+	return enc.w.Error()
+}
+
+// EncodeUser encodes a user row to the underlying CSV writer.
+func (enc *UserEncoder) EncodeUser(user *wtf.User) error {
+	return enc.w.Write([]string{
+		strconv.Itoa(user.ID),
+		user.Name,
+		user.Email,
+		user.CreatedAt.Format(time.RFC3339),
+		user.UpdatedAt.Format(time.RFC3339),
+	})
+}
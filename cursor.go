@@ -0,0 +1,83 @@
+package wtf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+)
+
+// Cursor represents a position within an ordered list of results. Cursors are
+// passed to clients as opaque tokens and are used with the "After" & "Before"
+// fields of a filter to fetch the page of results following or preceding it.
+//
+// Unlike offsets, cursors remain stable when rows are inserted or removed
+// from earlier pages.
+type Cursor struct {
+	// Values of the sort columns for the row, excluding the ID. Empty when
+	// results are only ordered by ID.
+	Keys []interface{} `json:"k,omitempty"`
+
+	// ID of the row. Used to break ties between rows with equal sort keys.
+	ID int `json:"id"`
+}
+
+// String returns the opaque token for the cursor.
+func (c Cursor) String() string {
+	buf, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// ParseCursor parses an opaque token into a cursor. Returns EINVALID if the
+// token is not a valid cursor.
+func ParseCursor(s string) (*Cursor, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, Errorf(EINVALID, "Invalid cursor.")
+	}
+
+	// Decode numbers so that integer keys are not converted to floats.
+	var c Cursor
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+	if err := dec.Decode(&c); err != nil || c.ID <= 0 {
+		return nil, Errorf(EINVALID, "Invalid cursor.")
+	}
+
+	for i, key := range c.Keys {
+		switch key := key.(type) {
+		case string:
+		case json.Number:
+			if c.Keys[i], err = key.Int64(); err != nil {
+				return nil, Errorf(EINVALID, "Invalid cursor.")
+			}
+		default:
+			return nil, Errorf(EINVALID, "Invalid cursor.")
+		}
+	}
+	return &c, nil
+}
+
+// Paginate determines the page of results to keep from a list of n results
+// which was fetched with one more than limit. The extra result is only used
+// to detect if another page exists in the direction being paged.
+//
+// Returns the range of results to keep & whether pages exist before & after
+// the kept results. Cursors for these pages can be generated from the first
+// & last kept results.
+func Paginate(n, limit, offset int, after, before string) (start, end int, hasPrev, hasNext bool) {
+	start, end = 0, n
+
+	// When paging backward, the extra result is the earliest one and the
+	// page after is the one the cursor came from.
+	if before != "" {
+		if limit > 0 && n > limit {
+			start, hasPrev = n-limit, true
+		}
+		return start, end, hasPrev, true
+	}
+
+	if limit > 0 && n > limit {
+		end, hasNext = limit, true
+	}
+	return start, end, after != "" || offset > 0, hasNext
+}
@@ -0,0 +1,150 @@
+package wtf
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Device authorization constants.
+const (
+	// DeviceAuthorizationExpiry is the time a user has to approve a device
+	// before the codes are no longer valid.
+	DeviceAuthorizationExpiry = 10 * time.Minute
+
+	// DeviceAuthorizationInterval is the minimum time a device should wait
+	// between polling requests.
+	DeviceAuthorizationInterval = 5 * time.Second
+)
+
+// DeviceAuthorization represents a pending login request from a device that
+// cannot perform a browser-based login itself, such as the CLI.
+//
+// The device requests a pair of codes. The short user code is displayed to the
+// user who enters it into the web site while logged in to approve the request.
+// Meanwhile, the device polls using the secret device code until the request
+// is approved and a device token can be issued.
+type DeviceAuthorization struct {
+	ID int `json:"id"`
+
+	// Name of the device, such as its host name. Shown to the user when
+	// managing their devices.
+	DeviceName string `json:"deviceName"`
+
+	// Secret code used by the device to poll for approval.
+	DeviceCode string `json:"deviceCode"`
+
+	// Short, human-readable code entered by the user to approve the device.
+	UserCode string `json:"userCode"`
+
+	// User who approved the request. Zero until the request is approved.
+	UserID int   `json:"userID,omitempty"`
+	User   *User `json:"user,omitempty"`
+
+	// Device issued when an approved request is exchanged. The device's
+	// token is only available at this point.
+	Device *Device `json:"device,omitempty"`
+
+	// Time after which the request can no longer be approved or exchanged.
+	ExpiresAt time.Time `json:"expiresAt"`
+
+	// Timestamps for creation & last update.
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Approved returns true if a user has approved the request.
+func (a *DeviceAuthorization) Approved() bool {
+	return a.UserID != 0
+}
+
+// Expired returns true if the request has expired as of now.
+func (a *DeviceAuthorization) Expired(now time.Time) bool {
+	return !now.Before(a.ExpiresAt)
+}
+
+// Device represents a device, such as the CLI, which has been approved to
+// access a user's account. Each device has its own token so it can be
+// revoked without affecting the user's API key or their other devices.
+type Device struct {
+	ID int `json:"id"`
+
+	// Owner of the device.
+	UserID int   `json:"userID"`
+	User   *User `json:"user,omitempty"`
+
+	// Name of the device, as reported when it requested authorization.
+	Name string `json:"name"`
+
+	// Secret token used by the device in place of an API key. Only the hash
+	// of the token is stored so it is only set when the device is created.
+	Token string `json:"token,omitempty"`
+
+	// Timestamp of when the device was approved.
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// DeviceFilter represents a filter used by FindDevices().
+type DeviceFilter struct {
+	// Restrict to subset of range.
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+}
+
+// FormatUserCode normalizes a user-entered code into its canonical format.
+// Codes are case insensitive & any separators the user types are ignored.
+func FormatUserCode(code string) string {
+	code = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return -1
+		}
+	}, code)
+
+	// Split code in half with a dash for readability.
+	if len(code) == 8 {
+		code = code[:4] + "-" + code[4:]
+	}
+	return code
+}
+
+// DeviceAuthorizationService represents a service for managing device logins.
+type DeviceAuthorizationService interface {
+	// Creates a new device authorization request. Generates the device code,
+	// user code & expiration time and assigns them to auth. Does not require
+	// the user to be logged in.
+	CreateDeviceAuthorization(ctx context.Context, auth *DeviceAuthorization) error
+
+	// Retrieves a pending request by its user code. Returns ENOTFOUND if the
+	// code does not exist or has expired.
+	FindDeviceAuthorizationByUserCode(ctx context.Context, userCode string) (*DeviceAuthorization, error)
+
+	// Approves a pending request on behalf of the current user. Returns
+	// EUNAUTHORIZED if no user is logged in. Returns ENOTFOUND if the code
+	// does not exist or has expired.
+	ApproveDeviceAuthorization(ctx context.Context, userCode string) error
+
+	// Exchanges a device code for a new device token. If the request has
+	// not been approved yet, the returned authorization has no user or device
+	// attached. Approved requests can only be exchanged once. Returns
+	// ENOTFOUND if the code does not exist or has expired.
+	ExchangeDeviceCode(ctx context.Context, deviceCode string) (*DeviceAuthorization, error)
+
+	// Retrieves a list of the current user's devices. Also returns the total
+	// count of devices which may differ if filter.Limit is set.
+	FindDevices(ctx context.Context, filter DeviceFilter) ([]*Device, int, error)
+
+	// Retrieves a device by its token with its user attached. This is used
+	// to authenticate devices. Returns ENOTFOUND if the token is invalid or
+	// the device has been revoked.
+	FindDeviceByToken(ctx context.Context, token string) (*Device, error)
+
+	// Revokes a device so its token can no longer be used. Returns
+	// EUNAUTHORIZED if the device is not owned by the current user. Returns
+	// ENOTFOUND if the device does not exist.
+	DeleteDevice(ctx context.Context, id int) error
+}
@@ -36,10 +36,26 @@ type Dial struct {
 	// It allows the creation of a shareable link without explicitly inviting users.
 	InviteCode string `json:"inviteCode,omitempty"`
 
+	// Token used in the dial's public read-only link. The dial is not public
+	// if the token is blank. Only returned to the dial owner.
+	ShareToken string `json:"shareToken,omitempty"`
+
+	// If true, the public view lists member names & values. Otherwise only
+	// the aggregate value is shown.
+	ShareMembers bool `json:"shareMembers"`
+
+	// Token used in the URL of the dial's embeddable status badge. The dial
+	// has no badge if the token is blank. Only returned to the dial owner.
+	BadgeToken string `json:"badgeToken,omitempty"`
+
 	// Aggregate WTF level for the dial. This is a computed field based on the
 	// average value of each member's WTF level.
 	Value int `json:"value"`
 
+	// Incremented each time the dial is updated. Used to detect concurrent
+	// edits when passed back as DialUpdate.Version.
+	Version int `json:"version"`
+
 	// Timestamps for dial creation & last update.
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
@@ -60,6 +76,16 @@ func (d *Dial) MembershipByUserID(userID int) *DialMembership {
 	return nil
 }
 
+// IsShared returns true if the dial can be viewed through a public link.
+func (d *Dial) IsShared() bool {
+	return d.ShareToken != ""
+}
+
+// HasBadge returns true if the dial's status badge can be embedded.
+func (d *Dial) HasBadge() bool {
+	return d.BadgeToken != ""
+}
+
 // Validate returns an error if dial has invalid fields. Only performs basic validation.
 func (d *Dial) Validate() error {
 	if d.Name == "" {
@@ -99,7 +125,8 @@ type DialService interface {
 	// Returns the new dial state even if there was an error during update.
 	//
 	// Returns ENOTFOUND if dial does not exist. Returns EUNAUTHORIZED if user
-	// is not the dial owner.
+	// is not the dial owner. Returns ECONFLICT if upd.Version is set and does
+	// not match the current version of the dial.
 	UpdateDial(ctx context.Context, id int, upd DialUpdate) (*Dial, error)
 
 	// Permanently removes a dial by ID. Only the dial owner may delete a dial.
@@ -116,42 +143,170 @@ type DialService interface {
 
 	// AverageDialValueReport returns a report of the average dial value across
 	// all dials that the user is a member of. Average values are computed
-	// between start & end time and are slotted into given intervals. The
-	// minimum interval size is one minute.
-	AverageDialValueReport(ctx context.Context, start, end time.Time, interval time.Duration) (*DialValueReport, error)
+	// between start & end time and are slotted into given intervals. Returns
+	// EINVALID if the interval is invalid.
+	AverageDialValueReport(ctx context.Context, start, end time.Time, interval ReportInterval) (*DialValueReport, error)
+
+	// DialValueReport returns a report of the value of a single dial between
+	// start & end time, slotted into the given intervals.
+	//
+	// Returns ENOTFOUND if dial does not exist or the user is not a member.
+	// Returns EINVALID if the interval is invalid.
+	DialValueReport(ctx context.Context, id int, start, end time.Time, interval ReportInterval) (*DialValueReport, error)
+
+	// Creates dials from a list of import rows on behalf of the current user.
+	// Invalid rows are reported in the result instead of aborting the import.
+	// No changes are made if opt.DryRun is set.
+	//
+	// Returns EUNAUTHORIZED if the user is not logged in.
+	ImportDials(ctx context.Context, rows []*DialImportRow, opt DialImportOptions) (*DialImportResult, error)
 }
 
+// Dial sort options. Dials are sorted by ID if no option is specified.
+const (
+	DialSortByNameAsc       = "name_asc"
+	DialSortByNameDesc      = "name_desc"
+	DialSortByValueAsc      = "value_asc"
+	DialSortByValueDesc     = "value_desc"
+	DialSortByUpdatedAtAsc  = "updated_at_asc"
+	DialSortByUpdatedAtDesc = "updated_at_desc"
+)
+
 // DialFilter represents a filter used by FindDials().
 type DialFilter struct {
 	// Filtering fields.
 	ID         *int    `json:"id"`
 	InviteCode *string `json:"inviteCode"`
 
+	// Restrict to the dial with a public share token. Like InviteCode, this
+	// does not require the user to be a member of the dial.
+	ShareToken *string `json:"shareToken"`
+
+	// Restrict to the dial with a badge token. This also does not require
+	// the user to be a member of the dial.
+	BadgeToken *string `json:"badgeToken"`
+
+	// Restrict to dials owned by a user.
+	UserID *int `json:"userID"`
+
+	// Restrict to dials whose name contains the query. Case-insensitive.
+	Query *string `json:"query"`
+
+	// Restrict to dials with a value in an inclusive range.
+	MinValue *int `json:"minValue"`
+	MaxValue *int `json:"maxValue"`
+
+	// Restrict to dials updated at or after a given time.
+	UpdatedSince *time.Time `json:"updatedSince"`
+
 	// Restrict to subset of range.
 	Offset int `json:"offset"`
 	Limit  int `json:"limit"`
+
+	// Restrict to results after or before an opaque cursor returned from a
+	// previous page. Only one may be set.
+	After  string `json:"after"`
+	Before string `json:"before"`
+
+	// Sorting option for results.
+	SortBy string `json:"sortBy"`
+}
+
+// Validate returns an error if the filter contains invalid fields.
+func (f DialFilter) Validate() error {
+	switch f.SortBy {
+	case "", DialSortByNameAsc, DialSortByNameDesc,
+		DialSortByValueAsc, DialSortByValueDesc,
+		DialSortByUpdatedAtAsc, DialSortByUpdatedAtDesc:
+	default:
+		return Errorf(EINVALID, "Invalid dial sort option.")
+	}
+
+	if f.MinValue != nil && f.MaxValue != nil && *f.MinValue > *f.MaxValue {
+		return Errorf(EINVALID, "Minimum value must not be greater than maximum value.")
+	}
+	return nil
+}
+
+// Cursor returns the position of dial within the results of the filter.
+func (f DialFilter) Cursor(dial *Dial) Cursor {
+	switch f.SortBy {
+	case DialSortByNameAsc, DialSortByNameDesc:
+		return Cursor{Keys: []interface{}{dial.Name}, ID: dial.ID}
+	case DialSortByValueAsc, DialSortByValueDesc:
+		return Cursor{Keys: []interface{}{dial.Value}, ID: dial.ID}
+	case DialSortByUpdatedAtAsc, DialSortByUpdatedAtDesc:
+		return Cursor{Keys: []interface{}{dial.UpdatedAt.UTC().Format(time.RFC3339)}, ID: dial.ID}
+	default:
+		return Cursor{ID: dial.ID}
+	}
 }
 
 // DialUpdate represents a set of fields to update on a dial.
 type DialUpdate struct {
 	Name *string `json:"name"`
+
+	// Enables or disables the public link. Enabling an already shared dial
+	// keeps its current token.
+	Shared *bool `json:"shared"`
+
+	// Replaces the token of a shared dial so that the previous link stops
+	// working. Returns EINVALID if the dial is not shared.
+	RotateShareToken bool `json:"rotateShareToken"`
+
+	// Sets whether member names are shown on the public view.
+	ShareMembers *bool `json:"shareMembers"`
+
+	// Enables or disables the status badge. Enabling a dial which already
+	// has a badge keeps its current token.
+	Badge *bool `json:"badge"`
+
+	// Replaces the badge token so that previously embedded badges stop
+	// working. Returns EINVALID if the dial has no badge.
+	RotateBadgeToken bool `json:"rotateBadgeToken"`
+
+	// If set, the update is only applied if the dial is still at this
+	// version. Otherwise ECONFLICT is returned.
+	Version *int `json:"version"`
 }
 
-// DialValueReport represents a report generated by AverageDialValueReport().
-// Each record represents the average value within an interval of time.
+// DialValueReport represents a report generated by AverageDialValueReport()
+// or DialValueReport(). Each record represents the value within an interval
+// of time.
 type DialValueReport struct {
-	Records []*DialValueRecord
+	Records []*DialValueRecord `json:"records"`
 }
 
 // DialValueRecord represents an average dial value at a given point in time
 // for the DialValueReport.
+//
+// Statistics are computed from the values observed within the slot: the value
+// at the start of the slot followed by each value recorded within it. Values
+// from every dial are combined for the average report. Slots aligned to hours
+// or days are read from hourly or daily rollups, as are slots older than the
+// raw value retention window. Rollups are added as their average weighted by
+// their number of values so the median & standard deviation are approximate.
 type DialValueRecord struct {
 	Value     int       `json:"value"`
 	Timestamp time.Time `json:"timestamp"`
+
+	// Range & distribution of the values within the slot.
+	Min    int     `json:"min"`
+	Max    int     `json:"max"`
+	Median float64 `json:"median"`
+	StdDev float64 `json:"stddev"`
+
+	// Number of values the statistics were computed from.
+	Count int `json:"count"`
+
+	// Number of distinct members who set their value within the slot,
+	// including members who have since left the dial.
+	Members int `json:"members"`
 }
 
 // GoString prints a more easily readable representation for debugging.
 // The timestamp field is represented as an RFC 3339 string instead of a pointer.
 func (r *DialValueRecord) GoString() string {
-	return fmt.Sprintf("&wtf.DialValueRecord{Value:%d, Timestamp:%q}", r.Value, r.Timestamp.Format(time.RFC3339))
+	return fmt.Sprintf("&wtf.DialValueRecord{Value:%d, Timestamp:%q, Min:%d, Max:%d, Median:%g, StdDev:%g, Count:%d, Members:%d}",
+		r.Value, r.Timestamp.Format(time.RFC3339), r.Min, r.Max, r.Median, r.StdDev, r.Count, r.Members)
 }
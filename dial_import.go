@@ -0,0 +1,88 @@
+package wtf
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DialImportRow represents a single row of a dial import file.
+//
+// Rows with the same name are grouped into a single dial. If a row includes a
+// value & timestamp then the value is backfilled into the dial's history. The
+// most recent backfilled value becomes the importing user's current value.
+type DialImportRow struct {
+	// Position of the row in the source file. Used for reporting errors.
+	Row int `json:"row"`
+
+	// Name of the dial to create.
+	Name string `json:"name"`
+
+	// Optional historical value & the time it was recorded.
+	Value     *int       `json:"value,omitempty"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+}
+
+// Validate returns an error if the row contains invalid fields.
+// The timestamp must be before now.
+func (r *DialImportRow) Validate(now time.Time) error {
+	if r.Name == "" {
+		return Errorf(EINVALID, "Dial name required.")
+	} else if len(r.Name) > MaxDialNameLen {
+		return Errorf(EINVALID, "Dial name too long.")
+	} else if r.Value == nil && r.Timestamp != nil {
+		return Errorf(EINVALID, "Value required when timestamp is set.")
+	} else if r.Value != nil && r.Timestamp == nil {
+		return Errorf(EINVALID, "Timestamp required when value is set.")
+	} else if r.Value != nil && (*r.Value < 0 || *r.Value > 100) {
+		return Errorf(EINVALID, "Value must be between 0 & 100.")
+	} else if r.Timestamp != nil && r.Timestamp.After(now) {
+		return Errorf(EINVALID, "Timestamp cannot be in the future.")
+	}
+	return nil
+}
+
+// DialImportOptions represents options passed to ImportDials().
+type DialImportOptions struct {
+	// If true, rows are validated but no dials are created.
+	DryRun bool `json:"dryRun"`
+}
+
+// DialImportResult represents the outcome of an import.
+type DialImportResult struct {
+	// Dials created by the import. During a dry run, these are the dials that
+	// would have been created and they do not have an ID set.
+	Dials []*Dial `json:"dials"`
+
+	// Rows which could not be imported. Other rows are still imported.
+	Errors []*DialImportError `json:"errors"`
+
+	// Number of historical values backfilled.
+	ValueN int `json:"valueN"`
+
+	DryRun bool `json:"dryRun"`
+}
+
+// AddError appends an error for a given row to the result. The code & message
+// are extracted from err so that internal details are not exposed.
+func (r *DialImportResult) AddError(row int, err error) {
+	r.Errors = append(r.Errors, &DialImportError{Row: row, Code: ErrorCode(err), Message: ErrorMessage(err)})
+}
+
+// SortErrors sorts errors by row. This is used after merging errors which
+// were found while decoding with errors found during import.
+func (r *DialImportResult) SortErrors() {
+	sort.SliceStable(r.Errors, func(i, j int) bool { return r.Errors[i].Row < r.Errors[j].Row })
+}
+
+// DialImportError represents a validation error for a single import row.
+type DialImportError struct {
+	Row     int    `json:"row"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (e *DialImportError) Error() string {
+	return fmt.Sprintf("row %d: %s", e.Row, e.Message)
+}
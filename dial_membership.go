@@ -25,6 +25,10 @@ type DialMembership struct {
 	// Updating this value will cause the parent dial's WTF level to be recomputed.
 	Value int `json:"value"`
 
+	// Incremented each time the membership is updated. Used to detect
+	// concurrent edits when passed back as DialMembershipUpdate.Version.
+	Version int `json:"version"`
+
 	// Timestamps for membership creation & last update.
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
@@ -80,7 +84,8 @@ type DialMembershipService interface {
 
 	// Updates the value of a membership. Only the owner of the membership can
 	// update the value. Returns EUNAUTHORIZED if user is not the owner. Returns
-	// ENOTFOUND if the membership does not exist.
+	// ENOTFOUND if the membership does not exist. Returns ECONFLICT if
+	// upd.Version is set and does not match the current membership version.
 	UpdateDialMembership(ctx context.Context, id int, upd DialMembershipUpdate) (*DialMembership, error)
 
 	// Permanently deletes a membership by ID. Only the membership owner and
@@ -103,11 +108,41 @@ type DialMembershipFilter struct {
 	Offset int `json:"offset"`
 	Limit  int `json:"limit"`
 
+	// Restrict to results after or before an opaque cursor returned from a
+	// previous page. Only one may be set.
+	After  string `json:"after"`
+	Before string `json:"before"`
+
 	// Sorting option for results.
 	SortBy string `json:"sortBy"`
 }
 
+// Cursor returns the position of membership within the results of the filter.
+// The default order lists the current user's membership first followed by the
+// other members by name so the user in ctx is required to build the cursor.
+func (f DialMembershipFilter) Cursor(ctx context.Context, membership *DialMembership) Cursor {
+	switch f.SortBy {
+	case DialMembershipSortByUpdatedAtDesc:
+		return Cursor{Keys: []interface{}{membership.UpdatedAt.UTC().Format(time.RFC3339)}, ID: membership.ID}
+	default:
+		var rank int
+		if membership.UserID != UserIDFromContext(ctx) {
+			rank = 1
+		}
+
+		var name string
+		if membership.User != nil {
+			name = membership.User.Name
+		}
+		return Cursor{Keys: []interface{}{rank, name}, ID: membership.ID}
+	}
+}
+
 // DialMembershipUpdate represents a set of fields to update on a membership.
 type DialMembershipUpdate struct {
 	Value *int `json:"value"`
+
+	// If set, the update is only applied if the membership is still at this
+	// version. Otherwise ECONFLICT is returned.
+	Version *int `json:"version"`
 }
@@ -7,13 +7,15 @@ import (
 // Event type constants.
 const (
 	EventTypeDialValueChanged           = "dial:value_changed"
+	EventTypeDialAnomalyDetected        = "dial:anomaly_detected"
 	EventTypeDialMembershipValueChanged = "dial_membership:value_changed"
 )
 
 // Event represents an event that occurs in the system. Currently there are only
-// events for changes to a dial value or membership value. These events are
-// eventually propagated out to connected users via WebSockets whenever changes
-// occur so that the UI can update in real-time.
+// events for changes to a dial value or membership value and for anomalies
+// detected in a dial's value. These events are eventually propagated out to
+// connected users via WebSockets whenever changes occur so that the UI can
+// update in real-time.
 type Event struct {
 	// Specifies the type of event that is occurring.
 	Type string `json:"type"`
@@ -29,6 +31,12 @@ type DialValueChangedPayload struct {
 	Value int `json:"value"`
 }
 
+// DialAnomalyDetectedPayload represents the payload for an Event object with a
+// type of EventTypeDialAnomalyDetected.
+type DialAnomalyDetectedPayload struct {
+	Anomaly *DialAnomaly `json:"anomaly"`
+}
+
 // DialMembershipValueChangedPayload represents the payload for an Event object
 // with a type of EventTypeDialMembershipValueChanged.
 type DialMembershipValueChangedPayload struct {
@@ -0,0 +1,221 @@
+package wtf
+
+import (
+	"math"
+	"time"
+)
+
+// Forecast models.
+const (
+	// ForecastModelLinear fits a straight line through the history using
+	// least squares regression.
+	ForecastModelLinear = "linear"
+
+	// ForecastModelHoltWinters uses additive Holt-Winters exponential
+	// smoothing with a seasonal cycle of one day.
+	ForecastModelHoltWinters = "holt-winters"
+)
+
+// Forecast defaults & limits.
+const (
+	// DefaultForecastN is the number of intervals forecast when none is given.
+	DefaultForecastN = 24
+
+	// MaxForecastN is the maximum number of intervals that can be forecast.
+	MaxForecastN = 1000
+
+	// ForecastZ is the number of standard errors between a forecast value &
+	// its bounds. This gives a 95% confidence interval.
+	ForecastZ = 1.96
+)
+
+// Holt-Winters smoothing factors for the level, trend & seasonal components.
+// The trend is smoothed heavily so that short bursts do not project forever.
+const (
+	holtWintersAlpha = 0.3
+	holtWintersBeta  = 0.05
+	holtWintersGamma = 0.3
+)
+
+// DialValueForecast represents the projected values of a dial for the
+// intervals following a DialValueReport.
+type DialValueForecast struct {
+	// Model used to generate the forecast.
+	Model string `json:"model"`
+
+	Records []*DialValueForecastRecord `json:"records"`
+}
+
+// DialValueForecastRecord represents the projected value of a dial for a
+// single interval. Lower & Upper bound the 95% confidence interval. All
+// values are limited to the 0-100 range of a dial.
+type DialValueForecastRecord struct {
+	Value     float64   `json:"value"`
+	Lower     float64   `json:"lower"`
+	Upper     float64   `json:"upper"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ForecastDialValueReport projects the values of report for the next n
+// intervals after its last record. If model is blank then Holt-Winters is
+// used when there are at least two days of history & linear regression is
+// used otherwise.
+//
+// Returns EINVALID if the model is unknown, n is out of range, or there is
+// not enough history for the model.
+func ForecastDialValueReport(report *DialValueReport, interval ReportInterval, model string, n int) (*DialValueForecast, error) {
+	if n < 1 || n > MaxForecastN {
+		return nil, Errorf(EINVALID, "Forecast must contain between 1 and %d intervals.", MaxForecastN)
+	} else if len(report.Records) < 2 {
+		return nil, Errorf(EINVALID, "Not enough history to forecast.")
+	}
+
+	values := make([]float64, len(report.Records))
+	for i, record := range report.Records {
+		values[i] = float64(record.Value)
+	}
+
+	// Determine the length of a daily cycle & choose a model, if needed.
+	season := dailySeasonLength(interval)
+	if model == "" {
+		model = ForecastModelLinear
+		if season > 0 && len(values) >= 2*season {
+			model = ForecastModelHoltWinters
+		}
+	}
+
+	var points, errs []float64
+	switch model {
+	case ForecastModelLinear:
+		points, errs = forecastLinear(values, n)
+	case ForecastModelHoltWinters:
+		if season == 0 {
+			return nil, Errorf(EINVALID, "Holt-Winters forecasts require an interval which evenly divides a day.")
+		} else if len(values) < 2*season {
+			return nil, Errorf(EINVALID, "Holt-Winters forecasts require at least two days of history.")
+		}
+		points, errs = forecastHoltWinters(values, season, n)
+	default:
+		return nil, Errorf(EINVALID, "Invalid forecast model.")
+	}
+
+	// Attach timestamps to each point, continuing from the last record.
+	forecast := &DialValueForecast{Model: model, Records: make([]*DialValueForecastRecord, n)}
+	t := report.Records[len(report.Records)-1].Timestamp
+	for i := range points {
+		t = interval.Next(t)
+		forecast.Records[i] = &DialValueForecastRecord{
+			Value:     clampForecast(points[i]),
+			Lower:     clampForecast(points[i] - ForecastZ*errs[i]),
+			Upper:     clampForecast(points[i] + ForecastZ*errs[i]),
+			Timestamp: t,
+		}
+	}
+	return forecast, nil
+}
+
+// dailySeasonLength returns the number of slots in a day for interval.
+// Returns zero if the interval does not evenly divide a day.
+func dailySeasonLength(interval ReportInterval) int {
+	const day = 24 * time.Hour
+	if interval.Unit != "" || interval.Duration <= 0 || interval.Duration >= day || day%interval.Duration != 0 {
+		return 0
+	}
+	return int(day / interval.Duration)
+}
+
+// forecastLinear returns n projected values from a least squares fit of
+// values against their index. Also returns the standard error of each
+// prediction, which widens further away from the history.
+func forecastLinear(values []float64, n int) (points, errs []float64) {
+	// Compute means & sums of squares.
+	size := float64(len(values))
+	var xmean, ymean float64
+	for i, y := range values {
+		xmean += float64(i)
+		ymean += y
+	}
+	xmean, ymean = xmean/size, ymean/size
+
+	var sxx, sxy float64
+	for i, y := range values {
+		dx := float64(i) - xmean
+		sxx += dx * dx
+		sxy += dx * (y - ymean)
+	}
+	slope := sxy / sxx
+	intercept := ymean - slope*xmean
+
+	// Estimate the residual standard error. At least one degree of freedom
+	// is needed beyond the slope & intercept.
+	var se float64
+	if len(values) > 2 {
+		var sse float64
+		for i, y := range values {
+			r := y - (intercept + slope*float64(i))
+			sse += r * r
+		}
+		se = math.Sqrt(sse / (size - 2))
+	}
+
+	points, errs = make([]float64, n), make([]float64, n)
+	for h := range points {
+		x := size + float64(h)
+		points[h] = intercept + slope*x
+		errs[h] = se * math.Sqrt(1+1/size+(x-xmean)*(x-xmean)/sxx)
+	}
+	return points, errs
+}
+
+// forecastHoltWinters returns n projected values using additive Holt-Winters
+// smoothing with a season of the given length. Values must cover at least
+// two seasons. Also returns the approximate standard error of each
+// prediction based on the one-step errors within the history.
+func forecastHoltWinters(values []float64, season, n int) (points, errs []float64) {
+	// Initialize the level from the first season & the trend from the change
+	// between the first two seasons. Seasonal components start as deviations
+	// from the first season's level.
+	var mean0, mean1 float64
+	for i := 0; i < season; i++ {
+		mean0 += values[i]
+		mean1 += values[season+i]
+	}
+	mean0, mean1 = mean0/float64(season), mean1/float64(season)
+
+	level, trend := mean0, (mean1-mean0)/float64(season)
+	seasonal := make([]float64, season)
+	for i := range seasonal {
+		seasonal[i] = values[i] - mean0
+	}
+
+	// Smooth over the history. One-step errors are only counted after the
+	// first season since it was used for initialization.
+	var sse float64
+	var errN int
+	for t, y := range values {
+		s := seasonal[t%season]
+		if t >= season {
+			r := y - (level + trend + s)
+			sse, errN = sse+r*r, errN+1
+		}
+
+		prev := level
+		level = holtWintersAlpha*(y-s) + (1-holtWintersAlpha)*(level+trend)
+		trend = holtWintersBeta*(level-prev) + (1-holtWintersBeta)*trend
+		seasonal[t%season] = holtWintersGamma*(y-level) + (1-holtWintersGamma)*s
+	}
+	se := math.Sqrt(sse / float64(errN))
+
+	// Each step further out adds the uncertainty of the smoothed level.
+	points, errs = make([]float64, n), make([]float64, n)
+	for h := range points {
+		points[h] = level + float64(h+1)*trend + seasonal[(len(values)+h)%season]
+		errs[h] = se * math.Sqrt(1+float64(h)*holtWintersAlpha*holtWintersAlpha)
+	}
+	return points, errs
+}
+
+// clampForecast limits v to the range of a dial & rounds to two decimals.
+func clampForecast(v float64) float64 {
+	return math.Round(math.Max(0, math.Min(100, v))*100) / 100
+}
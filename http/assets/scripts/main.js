@@ -1,5 +1,7 @@
-function connect() {
-	const socket = new ReconnectingWebSocket((location.protocol == 'https:' ? 'wss:' : 'ws:') + '//' + location.host + '/events');
+// connect opens a websocket to the event stream at path. Defaults to the
+// stream of the current user.
+function connect(path) {
+	const socket = new ReconnectingWebSocket((location.protocol == 'https:' ? 'wss:' : 'ws:') + '//' + location.host + (path || '/events'));
 	socket.addEventListener('message', function (event) {
 		const e = JSON.parse(event.data)
 		console.log(e)
@@ -22,6 +24,12 @@ function connect() {
 				window.ondialmembershipvaluechanged(e.payload)
 			}
 			break;
+
+		case "dial:anomaly_detected":
+			if (window.ondialanomalydetected !== undefined) {
+				window.ondialanomalydetected(e.payload)
+			}
+			break;
 		}
 	});
 }
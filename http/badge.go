@@ -0,0 +1,101 @@
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/http/html"
+	"github.com/gorilla/mux"
+)
+
+// BadgeMaxAge is the time that clients & proxies may cache a badge. Image
+// proxies, such as GitHub's, honor this so it is kept short.
+const BadgeMaxAge = 60 * time.Second
+
+// registerBadgeRoutes is a helper function for registering badge routes.
+// Badges are embedded in other sites so they do not require authentication.
+func (s *Server) registerBadgeRoutes(r *mux.Router) {
+	r.HandleFunc("/badge/{token}.svg", s.handleBadge).Methods("GET")
+}
+
+// registerBadgeOwnerRoutes is a helper function for registering the routes
+// used by dial owners to manage badges.
+func (s *Server) registerBadgeOwnerRoutes(r *mux.Router) {
+	r.HandleFunc("/dials/{id}/badge", s.handleDialBadgeUpdate).Methods("POST")
+}
+
+// handleBadge handles the "GET /badge/:token.svg" route. It renders the dial's
+// name & current value as an SVG badge. The ETag is computed from the image
+// so unchanged badges can be revalidated cheaply.
+func (s *Server) handleBadge(w http.ResponseWriter, r *http.Request) {
+	token := mux.Vars(r)["token"]
+	dials, _, err := s.DialService.FindDials(r.Context(), wtf.DialFilter{BadgeToken: &token})
+	if err != nil {
+		Error(w, r, err)
+		return
+	} else if len(dials) == 0 || token == "" {
+		Error(w, r, wtf.Errorf(wtf.ENOTFOUND, "Dial not found."))
+		return
+	}
+
+	// Render the badge to a buffer so it can be hashed.
+	var buf bytes.Buffer
+	badge := html.SVGBadge{Label: dials[0].Name, Value: dials[0].Value}
+	badge.Render(r.Context(), &buf)
+	etag := fmt.Sprintf(`"%x"`, sha256.Sum256(buf.Bytes()))
+
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(BadgeMaxAge.Seconds())))
+	w.Header().Set("ETag", etag)
+	if ifNoneMatch(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-type", "image/svg+xml")
+	if _, err := buf.WriteTo(w); err != nil {
+		LogError(r, err)
+		return
+	}
+}
+
+// handleDialBadgeUpdate handles the "POST /dials/:id/badge" route. It allows
+// the dial owner to enable, disable or rotate the badge from the HTML form on
+// the dial view. API clients can set the same fields with "PATCH /dials/:id".
+func (s *Server) handleDialBadgeUpdate(w http.ResponseWriter, r *http.Request) {
+	// Parse dial ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Convert the form action into an update.
+	var upd wtf.DialUpdate
+	var flash string
+	switch action := r.PostFormValue("action"); action {
+	case "enable":
+		enabled := true
+		upd.Badge, flash = &enabled, "Badge enabled."
+	case "disable":
+		enabled := false
+		upd.Badge, flash = &enabled, "Badge disabled."
+	case "rotate":
+		upd.RotateBadgeToken, flash = true, "Badge replaced. Previously embedded badges no longer work."
+	default:
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid badge action."))
+		return
+	}
+
+	if _, err := s.DialService.UpdateDial(r.Context(), id, upd); err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	SetFlash(w, flash)
+	http.Redirect(w, r, fmt.Sprintf("/dials/%d", id), http.StatusFound)
+}
@@ -0,0 +1,90 @@
+package http_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// Ensure a dial's badge can be fetched without logging in & revalidated.
+func TestBadge(t *testing.T) {
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	s.DialService.FindDialsFn = func(ctx context.Context, filter wtf.DialFilter) ([]*wtf.Dial, int, error) {
+		if filter.BadgeToken == nil || *filter.BadgeToken != "TOKEN" {
+			return nil, 0, nil
+		}
+		return []*wtf.Dial{{ID: 1, UserID: 1, Name: "R&D", Value: 60}}, 1, nil
+	}
+
+	var etag string
+	t.Run("OK", func(t *testing.T) {
+		resp, err := http.DefaultClient.Do(s.MustNewRequest(t, context.Background(), "GET", "/badge/TOKEN.svg", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		buf, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := resp.StatusCode, http.StatusOK; got != want {
+			t.Fatalf("StatusCode=%v, want %v", got, want)
+		} else if got, want := resp.Header.Get("Content-type"), "image/svg+xml"; got != want {
+			t.Fatalf("Content-type=%v, want %v", got, want)
+		} else if got, want := resp.Header.Get("Cache-Control"), "public, max-age=60"; got != want {
+			t.Fatalf("Cache-Control=%v, want %v", got, want)
+		} else if etag = resp.Header.Get("ETag"); etag == "" {
+			t.Fatal("expected ETag")
+		} else if !strings.Contains(string(buf), `aria-label="R&amp;D: 60"`) {
+			t.Fatalf("unexpected body: %s", buf)
+		} else if !strings.Contains(string(buf), `fill="#f5803e"`) {
+			t.Fatalf("expected warning color: %s", buf)
+		}
+	})
+
+	// Ensure an unchanged badge is not resent. Tags are weakly compared
+	// & may be passed in a list.
+	t.Run("NotModified", func(t *testing.T) {
+		for _, tt := range []struct {
+			header string
+			status int
+		}{
+			{header: etag, status: http.StatusNotModified},
+			{header: "W/" + etag, status: http.StatusNotModified},
+			{header: `"a,b", ` + etag, status: http.StatusNotModified},
+			{header: `W/"x",W/` + etag, status: http.StatusNotModified},
+			{header: "*", status: http.StatusNotModified},
+			{header: `"x", "y"`, status: http.StatusOK},
+			{header: strings.Trim(etag, `"`), status: http.StatusOK},
+		} {
+			req := s.MustNewRequest(t, context.Background(), "GET", "/badge/TOKEN.svg", nil)
+			req.Header.Set("If-None-Match", tt.header)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if got, want := resp.StatusCode, tt.status; got != want {
+				t.Fatalf("If-None-Match=%s: StatusCode=%v, want %v", tt.header, got, want)
+			}
+		}
+	})
+
+	// Ensure dials without a matching badge token are not found.
+	t.Run("ErrNotFound", func(t *testing.T) {
+		resp, err := http.DefaultClient.Do(s.MustNewRequest(t, context.Background(), "GET", "/badge/BADTOKEN.svg", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if got, want := resp.StatusCode, http.StatusNotFound; got != want {
+			t.Fatalf("StatusCode=%v, want %v", got, want)
+		}
+	})
+}
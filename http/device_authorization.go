@@ -0,0 +1,301 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/http/html"
+	"github.com/gorilla/mux"
+)
+
+// registerDeviceTokenRoutes is a helper function for registering the API
+// routes used by devices to log in. These routes do not require a session.
+func (s *Server) registerDeviceTokenRoutes(r *mux.Router) {
+	r.HandleFunc("/device/code", s.handleDeviceCodeCreate).Methods("POST")
+	r.HandleFunc("/device/token", s.handleDeviceToken).Methods("POST")
+}
+
+// registerDeviceAuthorizationRoutes is a helper function for registering the
+// HTML routes used by a logged in user to approve a device.
+func (s *Server) registerDeviceAuthorizationRoutes(r *mux.Router) {
+	r.HandleFunc("/device", s.handleDeviceAuthorizationNew).Methods("GET")
+	r.HandleFunc("/device", s.handleDeviceAuthorizationApprove).Methods("POST")
+	r.HandleFunc("/devices/{id}", s.handleDeviceDelete).Methods("DELETE")
+}
+
+// handleDeviceCodeCreate handles the "POST /device/code" route. It creates a
+// new pending device authorization and returns the generated codes. The body
+// may optionally name the device so the user can recognize it later.
+func (s *Server) handleDeviceCodeCreate(w http.ResponseWriter, r *http.Request) {
+	// Force application/json output.
+	r.Header.Set("Accept", "application/json")
+
+	var req jsonDeviceCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid JSON body"))
+		return
+	}
+
+	auth := wtf.DeviceAuthorization{DeviceName: req.Name}
+	if err := s.DeviceAuthorizationService.CreateDeviceAuthorization(r.Context(), &auth); err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(jsonDeviceCodeResponse{
+		DeviceCode: auth.DeviceCode,
+		UserCode:   auth.UserCode,
+		ExpiresAt:  auth.ExpiresAt,
+		Interval:   int(wtf.DeviceAuthorizationInterval / time.Second),
+	}); err != nil {
+		LogError(r, err)
+		return
+	}
+}
+
+// jsonDeviceCodeRequest represents the input JSON struct for "POST /device/code".
+type jsonDeviceCodeRequest struct {
+	Name string `json:"name"`
+}
+
+// jsonDeviceCodeResponse represents the output JSON struct for "POST /device/code".
+type jsonDeviceCodeResponse struct {
+	DeviceCode string    `json:"deviceCode"`
+	UserCode   string    `json:"userCode"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	Interval   int       `json:"interval"` // seconds
+}
+
+// handleDeviceToken handles the "POST /device/token" route. Devices poll this
+// route with their device code until the user approves the request. Once
+// approved, a new token is issued for the device.
+func (s *Server) handleDeviceToken(w http.ResponseWriter, r *http.Request) {
+	// Force application/json output.
+	r.Header.Set("Accept", "application/json")
+
+	var req jsonDeviceTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid JSON body"))
+		return
+	}
+
+	auth, err := s.DeviceAuthorizationService.ExchangeDeviceCode(r.Context(), req.DeviceCode)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Only include credentials once the request has been approved.
+	resp := jsonDeviceTokenResponse{Status: deviceTokenStatusPending}
+	if auth.Device != nil {
+		resp.Status, resp.Token = deviceTokenStatusApproved, auth.Device.Token
+	}
+
+	w.Header().Set("Content-type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		LogError(r, err)
+		return
+	}
+}
+
+// Device token statuses returned by "POST /device/token".
+const (
+	deviceTokenStatusPending  = "pending"
+	deviceTokenStatusApproved = "approved"
+)
+
+// jsonDeviceTokenRequest represents the input JSON struct for "POST /device/token".
+type jsonDeviceTokenRequest struct {
+	DeviceCode string `json:"deviceCode"`
+}
+
+// jsonDeviceTokenResponse represents the output JSON struct for "POST /device/token".
+type jsonDeviceTokenResponse struct {
+	Status string `json:"status"`
+	Token  string `json:"token,omitempty"`
+}
+
+// handleDeviceAuthorizationNew handles the "GET /device" route. It displays a
+// form for the user to enter their code. If the code is passed in the URL then
+// it is prefilled & checked so the user is warned early about expired codes.
+func (s *Server) handleDeviceAuthorizationNew(w http.ResponseWriter, r *http.Request) {
+	tmpl := html.DeviceAuthorizationTemplate{UserCode: r.URL.Query().Get("code")}
+	if tmpl.UserCode != "" {
+		if _, err := s.DeviceAuthorizationService.FindDeviceAuthorizationByUserCode(r.Context(), tmpl.UserCode); wtf.ErrorCode(err) == wtf.EINTERNAL {
+			Error(w, r, err)
+			return
+		} else if err != nil {
+			tmpl.Err = err
+		}
+	}
+	tmpl.Render(r.Context(), w)
+}
+
+// handleDeviceAuthorizationApprove handles the "POST /device" route. It
+// approves the device request for the current user and redirects home.
+func (s *Server) handleDeviceAuthorizationApprove(w http.ResponseWriter, r *http.Request) {
+	code := r.PostFormValue("code")
+
+	// Display validation errors on the form. Internal errors use the error page.
+	if err := s.DeviceAuthorizationService.ApproveDeviceAuthorization(r.Context(), code); wtf.ErrorCode(err) == wtf.EINTERNAL {
+		Error(w, r, err)
+		return
+	} else if err != nil {
+		tmpl := html.DeviceAuthorizationTemplate{UserCode: code, Err: err}
+		tmpl.Render(r.Context(), w)
+		return
+	}
+
+	SetFlash(w, "Your device has been approved. You may now return to your terminal.")
+	http.Redirect(w, r, "/", http.StatusFound)
+}
+
+// handleDeviceDelete handles the "DELETE /devices/:id" route. It revokes the
+// device's token & redirects the user back to their settings page.
+func (s *Server) handleDeviceDelete(w http.ResponseWriter, r *http.Request) {
+	// Parse device ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	if err := s.DeviceAuthorizationService.DeleteDevice(r.Context(), id); err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Render output to the client based on HTTP accept header.
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		w.Header().Set("Content-type", "application/json")
+		w.Write([]byte(`{}`))
+
+	default:
+		SetFlash(w, "Device successfully revoked.")
+		http.Redirect(w, r, "/settings", http.StatusFound)
+	}
+}
+
+// DeviceAuthorizationService implements the wtf.DeviceAuthorizationService over the HTTP protocol.
+type DeviceAuthorizationService struct {
+	Client *Client
+}
+
+// NewDeviceAuthorizationService returns a new instance of DeviceAuthorizationService.
+func NewDeviceAuthorizationService(client *Client) *DeviceAuthorizationService {
+	return &DeviceAuthorizationService{Client: client}
+}
+
+// CreateDeviceAuthorization requests a new device code & user code from the
+// server and assigns them to auth.
+func (s *DeviceAuthorizationService) CreateDeviceAuthorization(ctx context.Context, auth *wtf.DeviceAuthorization) error {
+	// Marshal device name into JSON format.
+	body, err := json.Marshal(jsonDeviceCodeRequest{Name: auth.DeviceName})
+	if err != nil {
+		return err
+	}
+
+	// Create request. No API key is required.
+	req, err := s.Client.newRequest(ctx, "POST", "/device/code", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	// Issue request. Treat non-201 status codes as errors.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusCreated {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal returned codes into the caller's object.
+	var jsonResponse jsonDeviceCodeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&jsonResponse); err != nil {
+		return err
+	}
+	auth.DeviceCode = jsonResponse.DeviceCode
+	auth.UserCode = jsonResponse.UserCode
+	auth.ExpiresAt = jsonResponse.ExpiresAt
+	return nil
+}
+
+// FindDeviceAuthorizationByUserCode is not implemented by the HTTP service.
+func (s *DeviceAuthorizationService) FindDeviceAuthorizationByUserCode(ctx context.Context, userCode string) (*wtf.DeviceAuthorization, error) {
+	return nil, wtf.Errorf(wtf.ENOTIMPLEMENTED, "Not implemented.")
+}
+
+// ApproveDeviceAuthorization is not implemented by the HTTP service.
+// Devices are approved by the user through the web site.
+func (s *DeviceAuthorizationService) ApproveDeviceAuthorization(ctx context.Context, userCode string) error {
+	return wtf.Errorf(wtf.ENOTIMPLEMENTED, "Not implemented.")
+}
+
+// ExchangeDeviceCode polls the server once for the status of the request.
+// If approved, the returned authorization has a device attached with its token
+// set. Otherwise no device is attached.
+func (s *DeviceAuthorizationService) ExchangeDeviceCode(ctx context.Context, deviceCode string) (*wtf.DeviceAuthorization, error) {
+	// Marshal device code into JSON format.
+	body, err := json.Marshal(jsonDeviceTokenRequest{DeviceCode: deviceCode})
+	if err != nil {
+		return nil, err
+	}
+
+	// Create request. No API key is required.
+	req, err := s.Client.newRequest(ctx, "POST", "/device/token", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-200 response is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal status & attach credentials, if approved.
+	var jsonResponse jsonDeviceTokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&jsonResponse); err != nil {
+		return nil, err
+	}
+
+	auth := &wtf.DeviceAuthorization{DeviceCode: deviceCode}
+	switch jsonResponse.Status {
+	case deviceTokenStatusPending:
+	case deviceTokenStatusApproved:
+		auth.Device = &wtf.Device{Token: jsonResponse.Token}
+	default:
+		return nil, fmt.Errorf("unexpected device token status: %q", jsonResponse.Status)
+	}
+	return auth, nil
+}
+
+// FindDevices is not implemented by the HTTP service.
+func (s *DeviceAuthorizationService) FindDevices(ctx context.Context, filter wtf.DeviceFilter) ([]*wtf.Device, int, error) {
+	return nil, 0, wtf.Errorf(wtf.ENOTIMPLEMENTED, "Not implemented.")
+}
+
+// FindDeviceByToken is not implemented by the HTTP service.
+func (s *DeviceAuthorizationService) FindDeviceByToken(ctx context.Context, token string) (*wtf.Device, error) {
+	return nil, wtf.Errorf(wtf.ENOTIMPLEMENTED, "Not implemented.")
+}
+
+// DeleteDevice is not implemented by the HTTP service.
+// Devices are revoked by the user through the web site.
+func (s *DeviceAuthorizationService) DeleteDevice(ctx context.Context, id int) error {
+	return wtf.Errorf(wtf.ENOTIMPLEMENTED, "Not implemented.")
+}
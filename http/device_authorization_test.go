@@ -0,0 +1,117 @@
+package http_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	wtfhttp "github.com/benbjohnson/wtf/http"
+)
+
+// Ensure the HTTP client can request device codes & poll for approval.
+func TestDeviceAuthorization(t *testing.T) {
+	// Start the mocked HTTP test server.
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	ctx := context.Background()
+	svc := wtfhttp.NewDeviceAuthorizationService(wtfhttp.NewClient(s.URL()))
+	expiresAt := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	// Ensure codes generated by the server are returned to the device.
+	t.Run("Create", func(t *testing.T) {
+		s.DeviceAuthorizationService.CreateDeviceAuthorizationFn = func(ctx context.Context, auth *wtf.DeviceAuthorization) error {
+			if auth.DeviceName != "LAPTOP" {
+				t.Fatalf("unexpected device name: %q", auth.DeviceName)
+			}
+			auth.DeviceCode, auth.UserCode, auth.ExpiresAt = "DEVICECODE", "BCDF-GHJK", expiresAt
+			return nil
+		}
+
+		auth := wtf.DeviceAuthorization{DeviceName: "LAPTOP"}
+		if err := svc.CreateDeviceAuthorization(ctx, &auth); err != nil {
+			t.Fatal(err)
+		} else if got, want := auth.DeviceCode, "DEVICECODE"; got != want {
+			t.Fatalf("DeviceCode=%v, want %v", got, want)
+		} else if got, want := auth.UserCode, "BCDF-GHJK"; got != want {
+			t.Fatalf("UserCode=%v, want %v", got, want)
+		} else if !auth.ExpiresAt.Equal(expiresAt) {
+			t.Fatalf("unexpected ExpiresAt: %s", auth.ExpiresAt)
+		}
+	})
+
+	// Ensure a pending request does not return credentials.
+	t.Run("Pending", func(t *testing.T) {
+		s.DeviceAuthorizationService.ExchangeDeviceCodeFn = func(ctx context.Context, deviceCode string) (*wtf.DeviceAuthorization, error) {
+			if deviceCode != "DEVICECODE" {
+				t.Fatalf("unexpected device code: %q", deviceCode)
+			}
+			return &wtf.DeviceAuthorization{DeviceCode: deviceCode}, nil
+		}
+
+		if auth, err := svc.ExchangeDeviceCode(ctx, "DEVICECODE"); err != nil {
+			t.Fatal(err)
+		} else if auth.Device != nil {
+			t.Fatalf("unexpected device: %#v", auth.Device)
+		}
+	})
+
+	// Ensure an approved request returns the device's token & not the API key.
+	t.Run("Approved", func(t *testing.T) {
+		s.DeviceAuthorizationService.ExchangeDeviceCodeFn = func(ctx context.Context, deviceCode string) (*wtf.DeviceAuthorization, error) {
+			user := &wtf.User{ID: 1, APIKey: "APIKEY"}
+			return &wtf.DeviceAuthorization{DeviceCode: deviceCode, UserID: 1, User: user, Device: &wtf.Device{ID: 1, UserID: 1, User: user, Token: "TOKEN"}}, nil
+		}
+
+		if auth, err := svc.ExchangeDeviceCode(ctx, "DEVICECODE"); err != nil {
+			t.Fatal(err)
+		} else if auth.Device == nil {
+			t.Fatal("expected device")
+		} else if got, want := auth.Device.Token, "TOKEN"; got != want {
+			t.Fatalf("Token=%v, want %v", got, want)
+		} else if auth.User != nil {
+			t.Fatalf("unexpected user: %#v", auth.User)
+		}
+	})
+
+	// Ensure a device token authenticates API requests as its user.
+	t.Run("DeviceToken", func(t *testing.T) {
+		user := &wtf.User{ID: 1, Name: "USER1"}
+		s.UserService.FindUsersFn = func(ctx context.Context, filter wtf.UserFilter) ([]*wtf.User, int, error) {
+			return nil, 0, nil
+		}
+		s.DeviceAuthorizationService.FindDeviceByTokenFn = func(ctx context.Context, token string) (*wtf.Device, error) {
+			if token != "TOKEN" {
+				return nil, wtf.Errorf(wtf.ENOTFOUND, "Device not found.")
+			}
+			return &wtf.Device{ID: 1, UserID: 1, User: user}, nil
+		}
+		s.DialService.FindDialsFn = func(ctx context.Context, filter wtf.DialFilter) ([]*wtf.Dial, int, error) {
+			if got, want := wtf.UserIDFromContext(ctx), 1; got != want {
+				t.Fatalf("UserID=%v, want %v", got, want)
+			}
+			return []*wtf.Dial{}, 0, nil
+		}
+
+		if _, _, err := wtfhttp.NewDialService(wtfhttp.NewClient(s.URL())).FindDials(wtf.NewContextWithUser(ctx, &wtf.User{APIKey: "TOKEN"}), wtf.DialFilter{}); err != nil {
+			t.Fatal(err)
+		}
+
+		// Ensure revoked or unknown tokens are rejected.
+		if _, _, err := wtfhttp.NewDialService(wtfhttp.NewClient(s.URL())).FindDials(wtf.NewContextWithUser(ctx, &wtf.User{APIKey: "BADTOKEN"}), wtf.DialFilter{}); wtf.ErrorCode(err) != wtf.EUNAUTHORIZED {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure an expired code is returned as an error to the device.
+	t.Run("ErrExpired", func(t *testing.T) {
+		s.DeviceAuthorizationService.ExchangeDeviceCodeFn = func(ctx context.Context, deviceCode string) (*wtf.DeviceAuthorization, error) {
+			return nil, wtf.Errorf(wtf.ENOTFOUND, "Device code has expired.")
+		}
+
+		if _, err := svc.ExchangeDeviceCode(ctx, "DEVICECODE"); wtf.ErrorCode(err) != wtf.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
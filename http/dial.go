@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/benbjohnson/wtf"
@@ -21,11 +23,14 @@ func (s *Server) registerDialRoutes(r *mux.Router) {
 	r.HandleFunc("/dials", s.handleDialIndex).Methods("GET")
 
 	// API endpoint for creating dials.
-	r.HandleFunc("/dials", s.handleDialCreate).Methods("POST")
+	r.HandleFunc("/dials", s.idempotent(MaxIdempotentBodySize, s.handleDialCreate)).Methods("POST")
+
+	// API endpoint for bulk creating dials from a CSV or JSON file.
+	r.HandleFunc("/dials/import", s.idempotent(MaxDialImportSize, s.handleDialImport)).Methods("POST")
 
 	// HTML form for creating dials.
 	r.HandleFunc("/dials/new", s.handleDialNew).Methods("GET")
-	r.HandleFunc("/dials/new", s.handleDialCreate).Methods("POST")
+	r.HandleFunc("/dials/new", s.idempotent(MaxIdempotentBodySize, s.handleDialCreate)).Methods("POST")
 
 	// View a single dial.
 	r.HandleFunc("/dials/{id}", s.handleDialView).Methods("GET")
@@ -34,11 +39,17 @@ func (s *Server) registerDialRoutes(r *mux.Router) {
 	r.HandleFunc("/dials/{id}/edit", s.handleDialEdit).Methods("GET")
 	r.HandleFunc("/dials/{id}/edit", s.handleDialUpdate).Methods("PATCH")
 
+	// API endpoint for updating an existing dial.
+	r.HandleFunc("/dials/{id}", s.handleDialUpdate).Methods("PATCH")
+
 	// Removing a dial.
 	r.HandleFunc("/dials/{id}", s.handleDialDelete).Methods("DELETE")
 
 	// Updating the value for the user's membership.
-	r.HandleFunc("/dials/{id}/membership", s.handleDialSetMembershipValue).Methods("PUT")
+	r.HandleFunc("/dials/{id}/membership", s.idempotent(MaxIdempotentBodySize, s.handleDialSetMembershipValue)).Methods("PUT")
+
+	// Removing the user's membership (i.e. leaving the dial).
+	r.HandleFunc("/dials/{id}/membership", s.handleDialDeleteMembership).Methods("DELETE")
 }
 
 // handleDialIndex handles the "GET /dials" route. This route can optionally
@@ -56,17 +67,37 @@ func (s *Server) handleDialIndex(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	default:
-		filter.Offset, _ = strconv.Atoi(r.URL.Query().Get("offset"))
+		if err := parseDialFilterQuery(r, &filter); err != nil {
+			Error(w, r, err)
+			return
+		}
 		filter.Limit = 20
 	}
 
-	// Fetch dials from database.
-	dials, n, err := s.DialService.FindDials(r.Context(), filter)
+	// Fetch dials from database. One extra dial is fetched to determine if
+	// another page exists.
+	fetch := filter
+	if fetch.Limit > 0 {
+		fetch.Limit++
+	}
+	dials, n, err := s.DialService.FindDials(r.Context(), fetch)
 	if err != nil {
 		Error(w, r, err)
 		return
 	}
 
+	// Trim the extra dial & generate cursors for the surrounding pages.
+	var prev, next string
+	start, end, hasPrev, hasNext := wtf.Paginate(len(dials), filter.Limit, filter.Offset, filter.After, filter.Before)
+	if dials = dials[start:end]; len(dials) > 0 {
+		if hasPrev {
+			prev = filter.Cursor(dials[0]).String()
+		}
+		if hasNext {
+			next = filter.Cursor(dials[len(dials)-1]).String()
+		}
+	}
+
 	// Render output based on HTTP accept header.
 	switch r.Header.Get("Accept") {
 	case "application/json":
@@ -74,6 +105,8 @@ func (s *Server) handleDialIndex(w http.ResponseWriter, r *http.Request) {
 		if err := json.NewEncoder(w).Encode(findDialsResponse{
 			Dials: dials,
 			N:     n,
+			Prev:  prev,
+			Next:  next,
 		}); err != nil {
 			LogError(r, err)
 			return
@@ -94,15 +127,72 @@ func (s *Server) handleDialIndex(w http.ResponseWriter, r *http.Request) {
 		}
 
 	default:
-		tmpl := html.DialIndexTemplate{Dials: dials, N: n, Filter: filter, URL: *r.URL}
+		tmpl := html.DialIndexTemplate{Dials: dials, N: n, Filter: filter, URL: *r.URL, Prev: prev, Next: next}
 		tmpl.Render(r.Context(), w)
 	}
 }
 
+// parseDialFilterQuery reads the search, sort & paging options for the dial
+// listing from the URL query. The "owner" parameter accepts a user ID or "me"
+// for the current user. The "updated_since" parameter accepts an RFC 3339
+// timestamp or a date.
+func parseDialFilterQuery(r *http.Request, filter *wtf.DialFilter) error {
+	q := r.URL.Query()
+	filter.Offset, _ = strconv.Atoi(q.Get("offset"))
+	filter.After, filter.Before = q.Get("after"), q.Get("before")
+	filter.SortBy = q.Get("sort")
+
+	if v := strings.TrimSpace(q.Get("q")); v != "" {
+		filter.Query = &v
+	}
+
+	if v := q.Get("owner"); v == "me" {
+		userID := wtf.UserIDFromContext(r.Context())
+		filter.UserID = &userID
+	} else if v != "" {
+		userID, err := strconv.Atoi(v)
+		if err != nil {
+			return wtf.Errorf(wtf.EINVALID, "Invalid owner.")
+		}
+		filter.UserID = &userID
+	}
+
+	if v := q.Get("min"); v != "" {
+		value, err := strconv.Atoi(v)
+		if err != nil {
+			return wtf.Errorf(wtf.EINVALID, "Invalid minimum value.")
+		}
+		filter.MinValue = &value
+	}
+	if v := q.Get("max"); v != "" {
+		value, err := strconv.Atoi(v)
+		if err != nil {
+			return wtf.Errorf(wtf.EINVALID, "Invalid maximum value.")
+		}
+		filter.MaxValue = &value
+	}
+
+	if v := q.Get("updated_since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			if t, err = time.Parse("2006-01-02", v); err != nil {
+				return wtf.Errorf(wtf.EINVALID, "Invalid updated since time.")
+			}
+		}
+		filter.UpdatedSince = &t
+	}
+
+	return nil
+}
+
 // findDialsResponse represents the output JSON struct for "GET /dials".
 type findDialsResponse struct {
 	Dials []*wtf.Dial `json:"dials"`
 	N     int         `json:"n"`
+
+	// Cursors for the previous & next pages, if any.
+	Prev string `json:"prev,omitempty"`
+	Next string `json:"next,omitempty"`
 }
 
 // handleDialView handles the "GET /dials/:id" route. It updates
@@ -128,6 +218,11 @@ func (s *Server) handleDialView(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Return the version as a weak tag since the memberships in the response
+	// can change without changing the dial's version. Conditional updates
+	// pass the version as a strong tag in "If-Match".
+	w.Header().Set("ETag", formatWeakETag(dial.Version))
+
 	// Format returned data based on HTTP accept header.
 	switch r.Header.Get("Accept") {
 	case "application/json":
@@ -137,11 +232,63 @@ func (s *Server) handleDialView(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+	case "text/csv":
+		// Export the dial's members since a single dial row isn't useful.
+		w.Header().Set("Content-type", "text/csv")
+		enc := csv.NewDialMembershipEncoder(w)
+		for _, membership := range dial.Memberships {
+			if err := enc.EncodeDialMembership(membership); err != nil {
+				LogError(r, err)
+				return
+			}
+		}
+		if err := enc.Close(); err != nil {
+			LogError(r, err)
+			return
+		}
+
 	default:
 		tmpl := html.DialViewTemplate{
 			Dial:      dial,
 			InviteURL: fmt.Sprintf("%s/invite/%s", s.URL(), dial.InviteCode),
+			Location:  reportLocation(r),
+		}
+		if dial.ShareToken != "" {
+			tmpl.ShareURL = fmt.Sprintf("%s/p/%s", s.URL(), dial.ShareToken)
+		}
+		if dial.BadgeToken != "" {
+			tmpl.BadgeURL = fmt.Sprintf("%s/badge/%s.svg", s.URL(), dial.BadgeToken)
+		}
+
+		// Fetch the history used for forecasting in the user's time zone. Only
+		// the last day is charted along with any anomalies detected within it.
+		interval := wtf.ReportInterval{Duration: html.DialHistoryInterval, Location: tmpl.Location}
+		end := interval.Next(interval.Truncate(time.Now()))
+		start := end.Add(-html.DialHistoryPeriod)
+		report, err := s.DialService.DialValueReport(r.Context(), dial.ID, end.Add(-DefaultForecastPeriod), end, interval)
+		if err != nil {
+			Error(w, r, err)
+			return
 		}
+		tmpl.History = &wtf.DialValueReport{Records: report.Records}
+		if n := interval.SlotN(start, end); len(report.Records) > n {
+			tmpl.History.Records = report.Records[len(report.Records)-n:]
+		}
+
+		// Project the history forward. The chart is still useful without a
+		// forecast if there is not enough history.
+		if tmpl.Forecast, err = wtf.ForecastDialValueReport(report, interval, "", html.DialForecastN); err != nil && wtf.ErrorCode(err) != wtf.EINVALID {
+			Error(w, r, err)
+			return
+		}
+		if tmpl.Anomalies, _, err = s.DialAnomalyService.FindDialAnomalies(r.Context(), wtf.DialAnomalyFilter{
+			DialID: &dial.ID,
+			Since:  &start,
+		}); err != nil {
+			Error(w, r, err)
+			return
+		}
+
 		tmpl.Render(r.Context(), w)
 	}
 }
@@ -227,9 +374,10 @@ func (s *Server) handleDialEdit(w http.ResponseWriter, r *http.Request) {
 	tmpl.Render(r.Context(), w)
 }
 
-// handleDialUpdate handles the "PATCH /dials/:id/edit" route. This route
-// reads in the updated fields and issues an update in the database. On success,
-// it redirects to the dial's view page.
+// handleDialUpdate handles the "PATCH /dials/:id" and "PATCH /dials/:id/edit"
+// routes. This route reads in the updated fields and issues an update in the
+// database. On success, it redirects to the dial's view page or returns the
+// updated dial as JSON.
 func (s *Server) handleDialUpdate(w http.ResponseWriter, r *http.Request) {
 	// Parse dial ID from the path.
 	id, err := strconv.Atoi(mux.Vars(r)["id"])
@@ -238,13 +386,58 @@ func (s *Server) handleDialUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Parse fields into an update object.
+	// Parse fields into an update object based on the request's content type.
 	var upd wtf.DialUpdate
-	name := r.PostFormValue("name")
-	upd.Name = &name
+	switch r.Header.Get("Content-type") {
+	case "application/json":
+		if err := json.NewDecoder(r.Body).Decode(&upd); err != nil {
+			Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid JSON body"))
+			return
+		}
+	default:
+		name := r.PostFormValue("name")
+		upd.Name = &name
+
+		// The edit form includes the version that the user started editing.
+		if v := r.PostFormValue("version"); v != "" {
+			version, err := strconv.Atoi(v)
+			if err != nil {
+				Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid version format"))
+				return
+			}
+			upd.Version = &version
+		}
+	}
+
+	// An "If-Match" header takes precedence over any version in the body.
+	if version, err := parseIfMatch(r); err != nil {
+		Error(w, r, err)
+		return
+	} else if version != nil {
+		upd.Version = version
+	}
 
 	// Update the dial in the database.
 	dial, err := s.DialService.UpdateDial(r.Context(), id, upd)
+
+	// Write updated dial to JSON clients. HTML clients are handled below.
+	if r.Header.Get("Accept") == "application/json" {
+		if err != nil {
+			Error(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-type", "application/json")
+		w.Header().Set("ETag", formatWeakETag(dial.Version))
+		if err := json.NewEncoder(w).Encode(dial); err != nil {
+			LogError(r, err)
+			return
+		}
+		return
+	}
+
+	// If we have an internal error, display the standard error page.
+	// Otherwise redisplay the edit form with the validation error.
 	if wtf.ErrorCode(err) == wtf.EINTERNAL {
 		Error(w, r, err)
 		return
@@ -319,6 +512,41 @@ type jsonSetDialMembershipValueRequest struct {
 	Value int `json:"value"`
 }
 
+// handleDialDeleteMembership handles the "DELETE /dials/:id/membership" route.
+// It removes the current user's membership from the dial.
+func (s *Server) handleDialDeleteMembership(w http.ResponseWriter, r *http.Request) {
+	// Parse dial ID from path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Look up the current user's membership on the dial.
+	userID := wtf.UserIDFromContext(r.Context())
+	memberships, _, err := s.DialMembershipService.FindDialMemberships(r.Context(), wtf.DialMembershipFilter{
+		DialID: &id,
+		UserID: &userID,
+	})
+	if err != nil {
+		Error(w, r, err)
+		return
+	} else if len(memberships) == 0 {
+		Error(w, r, wtf.Errorf(wtf.ENOTFOUND, "You are not a member of this dial."))
+		return
+	}
+
+	// Delete the membership. The service ensures the owner cannot leave.
+	if err := s.DialMembershipService.DeleteDialMembership(r.Context(), memberships[0].ID); err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Write response to indicate success.
+	w.Header().Set("Content-type", "application/json")
+	w.Write([]byte(`{}`))
+}
+
 // DialService implements the wtf.DialService over the HTTP protocol.
 type DialService struct {
 	Client *Client
@@ -405,8 +633,9 @@ func (s *DialService) CreateDial(ctx context.Context, dial *wtf.Dial) error {
 		return err
 	}
 
-	// Issue request. Treat non-201 status codes as errors.
-	resp, err := http.DefaultClient.Do(req)
+	// Issue request with an idempotency key so it can be safely retried.
+	// Treat non-201 status codes as errors.
+	resp, err := s.Client.doIdempotent(req)
 	if err != nil {
 		return err
 	} else if resp.StatusCode != http.StatusCreated {
@@ -421,9 +650,42 @@ func (s *DialService) CreateDial(ctx context.Context, dial *wtf.Dial) error {
 	return nil
 }
 
-// UpdateDial is not implemented by the HTTP service.
+// UpdateDial updates an existing dial by ID. Only the dial owner can update
+// a dial. Returns ENOTFOUND if dial does not exist. Returns EUNAUTHORIZED if
+// user is not the dial owner.
 func (s *DialService) UpdateDial(ctx context.Context, id int, upd wtf.DialUpdate) (*wtf.Dial, error) {
-	return nil, wtf.Errorf(wtf.ENOTIMPLEMENTED, "Not implemented.")
+	// Marshal update data into JSON format.
+	body, err := json.Marshal(upd)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "PATCH", fmt.Sprintf("/dials/%d", id), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	// Only apply the update to the expected version, if set.
+	if upd.Version != nil {
+		req.Header.Set("If-Match", formatETag(*upd.Version))
+	}
+
+	// Issue request. Any non-200 response is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal the updated dial data.
+	var dial wtf.Dial
+	if err := json.NewDecoder(resp.Body).Decode(&dial); err != nil {
+		return nil, err
+	}
+	return &dial, nil
 }
 
 // DeleteDial permanently removes a dial by ID. Only the dial owner may delete
@@ -466,6 +728,28 @@ func (s *DialService) SetDialMembershipValue(ctx context.Context, dialID, value
 		return err
 	}
 
+	// Issue request with an idempotency key so it can be safely retried.
+	// Any non-200 response is considered an error.
+	resp, err := s.Client.doIdempotent(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusOK {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+// LeaveDial removes the current user's membership from a dial. The dial owner
+// cannot leave their own dial. Returns ENOTFOUND if the user is not a member.
+func (s *DialService) LeaveDial(ctx context.Context, dialID int) error {
+	// Create a request with API key.
+	req, err := s.Client.newRequest(ctx, "DELETE", fmt.Sprintf("/dials/%d/membership", dialID), nil)
+	if err != nil {
+		return err
+	}
+
 	// Issue request. Any non-200 response is considered an error.
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -478,7 +762,81 @@ func (s *DialService) SetDialMembershipValue(ctx context.Context, dialID, value
 	return nil
 }
 
-// AverageDialValueReport is not implemented by the HTTP service.
-func (s *DialService) AverageDialValueReport(ctx context.Context, start, end time.Time, interval time.Duration) (*wtf.DialValueReport, error) {
-	return nil, wtf.Errorf(wtf.ENOTIMPLEMENTED, "Not implemented.")
+// AverageDialValueReport returns a report of the average dial value across
+// all dials that the user is a member of. A zero end time requests a report
+// through the current interval. Calendar intervals without a location are
+// aligned to the user's time zone.
+func (s *DialService) AverageDialValueReport(ctx context.Context, start, end time.Time, interval wtf.ReportInterval) (*wtf.DialValueReport, error) {
+	return s.findReport(ctx, "/report?"+reportQuery(start, end, interval))
+}
+
+// DialValueReport returns a report of the value of a single dial over time.
+// Zero times & locations are handled the same as AverageDialValueReport().
+// Returns ENOTFOUND if dial does not exist or the user is not a member.
+func (s *DialService) DialValueReport(ctx context.Context, id int, start, end time.Time, interval wtf.ReportInterval) (*wtf.DialValueReport, error) {
+	return s.findReport(ctx, fmt.Sprintf("/dials/%d/report?%s", id, reportQuery(start, end, interval)))
+}
+
+// DialValueForecast returns the projected value of a single dial for the n
+// intervals following the report range, using its history between start &
+// end. Zero times & locations are handled the same as AverageDialValueReport().
+// If model is blank then the server chooses a model based on the history.
+func (s *DialService) DialValueForecast(ctx context.Context, id int, start, end time.Time, interval wtf.ReportInterval, model string, n int) (*wtf.DialValueForecast, error) {
+	q, err := url.ParseQuery(reportQuery(start, end, interval))
+	if err != nil {
+		return nil, err
+	}
+	if model != "" {
+		q.Set("model", model)
+	}
+	if n > 0 {
+		q.Set("n", strconv.Itoa(n))
+	}
+
+	// Create a request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", fmt.Sprintf("/dials/%d/forecast?%s", id, q.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-200 response is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal the forecast records.
+	var forecast wtf.DialValueForecast
+	if err := json.NewDecoder(resp.Body).Decode(&forecast); err != nil {
+		return nil, err
+	}
+	return &forecast, nil
+}
+
+// findReport fetches a value report from the given path.
+func (s *DialService) findReport(ctx context.Context, path string) (*wtf.DialValueReport, error) {
+	// Create a request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-200 response is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal the report records.
+	var report wtf.DialValueReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return nil, err
+	}
+	return &report, nil
 }
@@ -0,0 +1,120 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/gorilla/mux"
+)
+
+// registerDialAnomalyRoutes is a helper function for registering anomaly routes.
+func (s *Server) registerDialAnomalyRoutes(r *mux.Router) {
+	// Listing of anomalies for dials the user is a member of.
+	r.HandleFunc("/dial-anomalies", s.handleDialAnomalyIndex).Methods("GET")
+}
+
+// handleDialAnomalyIndex handles the "GET /dial-anomalies" route. This route
+// can optionally accept filter arguments and outputs a list of anomalies
+// detected on dials the current user is a member of.
+func (s *Server) handleDialAnomalyIndex(w http.ResponseWriter, r *http.Request) {
+	// Parse optional filter object.
+	var filter wtf.DialAnomalyFilter
+	switch r.Header.Get("Content-type") {
+	case "application/json":
+		if err := json.NewDecoder(r.Body).Decode(&filter); err != nil {
+			Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid JSON body"))
+			return
+		}
+	default:
+		q := r.URL.Query()
+		if v := q.Get("dialID"); v != "" {
+			id, err := strconv.Atoi(v)
+			if err != nil {
+				Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid dial ID format"))
+				return
+			}
+			filter.DialID = &id
+		}
+		if v := q.Get("since"); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid since time format."))
+				return
+			}
+			filter.Since = &t
+		}
+		filter.Offset, _ = strconv.Atoi(q.Get("offset"))
+		filter.Limit, _ = strconv.Atoi(q.Get("limit"))
+	}
+
+	// Fetch anomalies from database.
+	anomalies, n, err := s.DialAnomalyService.FindDialAnomalies(r.Context(), filter)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Render output as JSON since there is no HTML page for anomalies.
+	w.Header().Set("Content-type", "application/json")
+	if err := json.NewEncoder(w).Encode(findDialAnomaliesResponse{
+		DialAnomalies: anomalies,
+		N:             n,
+	}); err != nil {
+		LogError(r, err)
+		return
+	}
+}
+
+// findDialAnomaliesResponse represents the output JSON struct for "GET /dial-anomalies".
+type findDialAnomaliesResponse struct {
+	DialAnomalies []*wtf.DialAnomaly `json:"dialAnomalies"`
+	N             int                `json:"n"`
+}
+
+// DialAnomalyService implements the wtf.DialAnomalyService over the HTTP protocol.
+type DialAnomalyService struct {
+	Client *Client
+}
+
+// NewDialAnomalyService returns a new instance of DialAnomalyService.
+func NewDialAnomalyService(client *Client) *DialAnomalyService {
+	return &DialAnomalyService{Client: client}
+}
+
+// FindDialAnomalies retrieves a list of anomalies based on a filter. Only
+// returns anomalies for dials that the user is a member of. Also returns a
+// count of total matching anomalies.
+func (s *DialAnomalyService) FindDialAnomalies(ctx context.Context, filter wtf.DialAnomalyFilter) ([]*wtf.DialAnomaly, int, error) {
+	// Marshal filter into JSON format.
+	body, err := json.Marshal(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", "/dial-anomalies", bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Issue request. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, 0, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal result set of anomalies & total count.
+	var jsonResponse findDialAnomaliesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&jsonResponse); err != nil {
+		return nil, 0, err
+	}
+	return jsonResponse.DialAnomalies, jsonResponse.N, nil
+}
@@ -0,0 +1,75 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	wtfhttp "github.com/benbjohnson/wtf/http"
+)
+
+// Ensure the HTTP client can list anomalies & the server parses query filters.
+func TestDialAnomalyIndex(t *testing.T) {
+	// Start the mocked HTTP test server.
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	// Create a single user and build a context with them.
+	user0 := &wtf.User{ID: 1, Name: "USER1", APIKey: "APIKEY"}
+	ctx0 := wtf.NewContextWithUser(context.Background(), user0)
+	s.UserService.FindUserByIDFn = func(ctx context.Context, id int) (*wtf.User, error) {
+		return user0, nil
+	}
+	s.UserService.FindUsersFn = func(ctx context.Context, filter wtf.UserFilter) ([]*wtf.User, int, error) {
+		return []*wtf.User{user0}, 1, nil
+	}
+
+	since := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	anomaly := &wtf.DialAnomaly{ID: 1, DialID: 1, Value: 95, Baseline: 50, StdDev: 2, Score: 9, Timestamp: since.Add(time.Hour)}
+
+	// Mock anomaly look up by dial & time.
+	s.DialAnomalyService.FindDialAnomaliesFn = func(ctx context.Context, filter wtf.DialAnomalyFilter) ([]*wtf.DialAnomaly, int, error) {
+		if filter.DialID == nil || *filter.DialID != 1 {
+			t.Fatalf("unexpected dial id: %#v", filter.DialID)
+		} else if filter.Since == nil || !filter.Since.Equal(since) {
+			t.Fatalf("unexpected since: %#v", filter.Since)
+		}
+		return []*wtf.DialAnomaly{anomaly}, 1, nil
+	}
+
+	// Ensure the filter is passed through by the client.
+	t.Run("JSON", func(t *testing.T) {
+		dialID := 1
+		svc := wtfhttp.NewDialAnomalyService(wtfhttp.NewClient(s.URL()))
+		if anomalies, n, err := svc.FindDialAnomalies(ctx0, wtf.DialAnomalyFilter{DialID: &dialID, Since: &since}); err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 1; got != want {
+			t.Fatalf("n=%v, want %v", got, want)
+		} else if got, want := anomalies, []*wtf.DialAnomaly{anomaly}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("anomalies=%#v, want %#v", got, want)
+		}
+	})
+
+	// Ensure query parameters are parsed when no JSON body is sent.
+	t.Run("Query", func(t *testing.T) {
+		resp, err := http.DefaultClient.Do(s.MustNewRequest(t, ctx0, "GET", "/dial-anomalies?dialID=1&since=2000-01-01T00:00:00Z", nil))
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := resp.StatusCode, http.StatusOK; got != want {
+			t.Fatalf("StatusCode=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure an invalid since time is rejected.
+	t.Run("ErrSince", func(t *testing.T) {
+		resp, err := http.DefaultClient.Do(s.MustNewRequest(t, ctx0, "GET", "/dial-anomalies?since=yesterday", nil))
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := resp.StatusCode, http.StatusBadRequest; got != want {
+			t.Fatalf("StatusCode=%v, want %v", got, want)
+		}
+	})
+}
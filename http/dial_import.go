@@ -0,0 +1,123 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/csv"
+)
+
+// MaxDialImportSize is the maximum size of an import request body, in bytes.
+const MaxDialImportSize = 10 << 20
+
+// handleDialImport handles the "POST /dials/import" route. It creates dials
+// from a CSV file or a JSON list of rows and returns the result as JSON.
+//
+// Rows which cannot be decoded are reported alongside rows which fail
+// validation so the caller receives all errors for a file at once. The
+// "dry-run" query parameter can be set to validate a file without saving.
+func (s *Server) handleDialImport(w http.ResponseWriter, r *http.Request) {
+	// Force application/json output.
+	r.Header.Set("Accept", "application/json")
+	body := http.MaxBytesReader(w, r.Body, MaxDialImportSize)
+
+	// Decode rows based on the request's content type.
+	var req jsonDialImportRequest
+	var decodeErrs []*wtf.DialImportError
+	switch r.Header.Get("Content-type") {
+	case "text/csv":
+		var err error
+		if req.Rows, decodeErrs, err = decodeDialImportCSV(body); err != nil {
+			Error(w, r, err)
+			return
+		}
+		req.DryRun, _ = strconv.ParseBool(r.URL.Query().Get("dry-run"))
+
+	default:
+		if err := json.NewDecoder(body).Decode(&req); err != nil {
+			Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid JSON body"))
+			return
+		}
+		if v := r.URL.Query().Get("dry-run"); v != "" {
+			req.DryRun, _ = strconv.ParseBool(v)
+		}
+	}
+
+	// Import valid rows & merge in any rows which could not be decoded.
+	result, err := s.DialService.ImportDials(r.Context(), req.Rows, wtf.DialImportOptions{DryRun: req.DryRun})
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+	result.Errors = append(result.Errors, decodeErrs...)
+	result.SortErrors()
+
+	w.Header().Set("Content-type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		LogError(r, err)
+		return
+	}
+}
+
+// jsonDialImportRequest represents the JSON request body for "POST /dials/import".
+type jsonDialImportRequest struct {
+	Rows   []*wtf.DialImportRow `json:"rows"`
+	DryRun bool                 `json:"dryRun"`
+}
+
+// decodeDialImportCSV decodes all rows from r. Rows which cannot be decoded
+// are returned as errors instead. Returns an error if the header is invalid.
+func decodeDialImportCSV(r io.Reader) (rows []*wtf.DialImportRow, errs []*wtf.DialImportError, err error) {
+	dec := csv.NewDialImportDecoder(r)
+	for {
+		var row wtf.DialImportRow
+		if err := dec.Decode(&row); err == io.EOF {
+			return rows, errs, nil
+		} else if err != nil && row.Row != 0 && wtf.ErrorCode(err) == wtf.EINVALID {
+			errs = append(errs, &wtf.DialImportError{Row: row.Row, Code: wtf.EINVALID, Message: wtf.ErrorMessage(err)})
+			continue
+		} else if err != nil {
+			return nil, nil, err
+		}
+		rows = append(rows, &row)
+	}
+}
+
+// ImportDials creates dials from a list of import rows on behalf of the
+// current user. Invalid rows are reported in the result instead of aborting
+// the import. No changes are made if opt.DryRun is set.
+func (s *DialService) ImportDials(ctx context.Context, rows []*wtf.DialImportRow, opt wtf.DialImportOptions) (*wtf.DialImportResult, error) {
+	// Marshal rows into JSON format.
+	body, err := json.Marshal(jsonDialImportRequest{Rows: rows, DryRun: opt.DryRun})
+	if err != nil {
+		return nil, err
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "POST", "/dials/import", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request with an idempotency key so it can be safely retried.
+	// Any non-200 response is considered an error.
+	resp, err := s.Client.doIdempotent(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal the import result.
+	var result wtf.DialImportResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,101 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/benbjohnson/wtf"
+	wtfhttp "github.com/benbjohnson/wtf/http"
+)
+
+// Ensure the HTTP server can import dials from CSV & JSON.
+func TestDialImport(t *testing.T) {
+	// Start the mocked HTTP test server.
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	// Create a single user and build a context with them.
+	user0 := &wtf.User{ID: 1, Name: "USER1", APIKey: "APIKEY"}
+	ctx0 := wtf.NewContextWithUser(context.Background(), user0)
+	s.UserService.FindUserByIDFn = func(ctx context.Context, id int) (*wtf.User, error) {
+		return user0, nil
+	}
+	s.UserService.FindUsersFn = func(ctx context.Context, filter wtf.UserFilter) ([]*wtf.User, int, error) {
+		return []*wtf.User{user0}, 1, nil
+	}
+
+	// Mock import by creating one dial per row.
+	s.DialService.ImportDialsFn = func(ctx context.Context, rows []*wtf.DialImportRow, opt wtf.DialImportOptions) (*wtf.DialImportResult, error) {
+		result := &wtf.DialImportResult{DryRun: opt.DryRun}
+		for _, row := range rows {
+			if row.Name == "INVALID" {
+				result.AddError(row.Row, wtf.Errorf(wtf.EINVALID, "Invalid dial."))
+				continue
+			}
+			result.Dials = append(result.Dials, &wtf.Dial{Name: row.Name})
+		}
+		return result, nil
+	}
+
+	// Ensure CSV rows which cannot be decoded are reported with other errors.
+	t.Run("CSV", func(t *testing.T) {
+		req := s.MustNewRequest(t, ctx0, "POST", "/dials/import?dry-run=true", strings.NewReader(
+			"name,value,timestamp\n"+
+				"DIAL1,50,2000-01-01T00:00:00Z\n"+
+				"INVALID\n"+
+				"DIAL2,XYZ,2000-01-01T00:00:00Z\n",
+		))
+		req.Header.Set("Content-type", "text/csv")
+
+		var result wtf.DialImportResult
+		if resp, err := http.DefaultClient.Do(req); err != nil {
+			t.Fatal(err)
+		} else if got, want := resp.StatusCode, http.StatusOK; got != want {
+			t.Fatalf("StatusCode=%v, want %v", got, want)
+		} else if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Fatal(err)
+		}
+
+		if !result.DryRun {
+			t.Fatal("expected dry run")
+		} else if got, want := len(result.Dials), 1; got != want {
+			t.Fatalf("len(Dials)=%v, want %v", got, want)
+		} else if got, want := len(result.Errors), 2; got != want {
+			t.Fatalf("len(Errors)=%v, want %v", got, want)
+		} else if got, want := result.Errors[0].Row, 3; got != want {
+			t.Fatalf("Errors[0].Row=%v, want %v", got, want)
+		} else if got, want := result.Errors[1].Row, 4; got != want {
+			t.Fatalf("Errors[1].Row=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure a CSV file without a name column is rejected.
+	t.Run("ErrCSVHeader", func(t *testing.T) {
+		req := s.MustNewRequest(t, ctx0, "POST", "/dials/import", strings.NewReader("id,value\n1,2\n"))
+		req.Header.Set("Content-type", "text/csv")
+		if resp, err := http.DefaultClient.Do(req); err != nil {
+			t.Fatal(err)
+		} else if got, want := resp.StatusCode, http.StatusBadRequest; got != want {
+			t.Fatalf("StatusCode=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure the HTTP client can import rows as JSON.
+	t.Run("Client", func(t *testing.T) {
+		svc := wtfhttp.NewDialService(wtfhttp.NewClient(s.URL()))
+		result, err := svc.ImportDials(ctx0, []*wtf.DialImportRow{
+			{Row: 1, Name: "DIAL1"},
+			{Row: 2, Name: "INVALID"},
+		}, wtf.DialImportOptions{})
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := len(result.Dials), 1; got != want {
+			t.Fatalf("len(Dials)=%v, want %v", got, want)
+		} else if got, want := result.Errors[0].Message, "Invalid dial."; got != want {
+			t.Fatalf("Errors[0].Message=%v, want %v", got, want)
+		}
+	})
+}
@@ -1,12 +1,16 @@
 package http
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/csv"
 	"github.com/benbjohnson/wtf/http/html"
 	"github.com/gorilla/mux"
 )
@@ -15,7 +19,10 @@ import (
 func (s *Server) registerDialMembershipRoutes(r *mux.Router) {
 	// Create membership via invite code.
 	r.HandleFunc("/invite/{code}", s.handleDialMembershipNew).Methods("GET")
-	r.HandleFunc("/invite/{code}", s.handleDialMembershipCreate).Methods("POST")
+	r.HandleFunc("/invite/{code}", s.idempotent(MaxIdempotentBodySize, s.handleDialMembershipCreate)).Methods("POST")
+
+	// Listing of memberships for dials the user is a member of.
+	r.HandleFunc("/dial-memberships", s.handleDialMembershipIndex).Methods("GET")
 
 	// Update membership WTF level.
 	r.HandleFunc("/dial-memberships/{id}", s.handleDialMembershipUpdate).Methods("PATCH")
@@ -24,6 +31,100 @@ func (s *Server) registerDialMembershipRoutes(r *mux.Router) {
 	r.HandleFunc("/dial-memberships/{id}", s.handleDialMembershipDelete).Methods("DELETE")
 }
 
+// handleDialMembershipIndex handles the "GET /dial-memberships" route. This
+// route can optionally accept filter arguments and outputs a list of
+// memberships for dials the current user is a member of.
+//
+// The endpoint works with JSON & CSV formats.
+func (s *Server) handleDialMembershipIndex(w http.ResponseWriter, r *http.Request) {
+	// Parse optional filter object.
+	var filter wtf.DialMembershipFilter
+	switch r.Header.Get("Content-type") {
+	case "application/json":
+		if err := json.NewDecoder(r.Body).Decode(&filter); err != nil {
+			Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid JSON body"))
+			return
+		}
+	default:
+		q := r.URL.Query()
+		if v := q.Get("dialID"); v != "" {
+			id, err := strconv.Atoi(v)
+			if err != nil {
+				Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid dial ID format"))
+				return
+			}
+			filter.DialID = &id
+		}
+		filter.Offset, _ = strconv.Atoi(q.Get("offset"))
+		filter.Limit, _ = strconv.Atoi(q.Get("limit"))
+		filter.After, filter.Before = q.Get("after"), q.Get("before")
+	}
+
+	// Fetch memberships from database. One extra membership is fetched to
+	// determine if another page exists.
+	fetch := filter
+	if fetch.Limit > 0 {
+		fetch.Limit++
+	}
+	memberships, n, err := s.DialMembershipService.FindDialMemberships(r.Context(), fetch)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Trim the extra membership & generate cursors for the surrounding pages.
+	var prev, next string
+	start, end, hasPrev, hasNext := wtf.Paginate(len(memberships), filter.Limit, filter.Offset, filter.After, filter.Before)
+	if memberships = memberships[start:end]; len(memberships) > 0 {
+		if hasPrev {
+			prev = filter.Cursor(r.Context(), memberships[0]).String()
+		}
+		if hasNext {
+			next = filter.Cursor(r.Context(), memberships[len(memberships)-1]).String()
+		}
+	}
+
+	// Render output based on HTTP accept header. Defaults to JSON since there
+	// is no HTML page for memberships.
+	switch r.Header.Get("Accept") {
+	case "text/csv":
+		w.Header().Set("Content-type", "text/csv")
+		enc := csv.NewDialMembershipEncoder(w)
+		for _, membership := range memberships {
+			if err := enc.EncodeDialMembership(membership); err != nil {
+				LogError(r, err)
+				return
+			}
+		}
+		if err := enc.Close(); err != nil {
+			LogError(r, err)
+			return
+		}
+
+	default:
+		w.Header().Set("Content-type", "application/json")
+		if err := json.NewEncoder(w).Encode(findDialMembershipsResponse{
+			DialMemberships: memberships,
+			N:               n,
+			Prev:            prev,
+			Next:            next,
+		}); err != nil {
+			LogError(r, err)
+			return
+		}
+	}
+}
+
+// findDialMembershipsResponse represents the output JSON struct for "GET /dial-memberships".
+type findDialMembershipsResponse struct {
+	DialMemberships []*wtf.DialMembership `json:"dialMemberships"`
+	N               int                   `json:"n"`
+
+	// Cursors for the previous & next pages, if any.
+	Prev string `json:"prev,omitempty"`
+	Next string `json:"next,omitempty"`
+}
+
 // handleDialMembershipNew handles the "GET /invite/:code" route. This route
 // uses the dial's invite code to allow users to join an existing dial.
 func (s *Server) handleDialMembershipNew(w http.ResponseWriter, r *http.Request) {
@@ -65,6 +166,8 @@ func (s *Server) handleDialMembershipNew(w http.ResponseWriter, r *http.Request)
 
 // handleDialMembershipCreate handles the "POST /invite/:code" route.
 // This route adds a new membership for the current user to a dial.
+//
+// The endpoint works with HTML & JSON formats.
 func (s *Server) handleDialMembershipCreate(w http.ResponseWriter, r *http.Request) {
 	// Read user ID for currently logged in user.
 	userID := wtf.UserIDFromContext(r.Context())
@@ -93,6 +196,17 @@ func (s *Server) handleDialMembershipCreate(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	// Return the new membership directly to JSON clients.
+	if r.Header.Get("Accept") == "application/json" {
+		w.Header().Set("Content-type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(membership); err != nil {
+			LogError(r, err)
+			return
+		}
+		return
+	}
+
 	// Let the user know they've joined the dial and then redirect them to the
 	// dial's page.
 	SetFlash(w, fmt.Sprintf("You have now joined the %q dial.", membership.Dial.Name))
@@ -119,6 +233,14 @@ func (s *Server) handleDialMembershipUpdate(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	// An "If-Match" header takes precedence over any version in the body.
+	if version, err := parseIfMatch(r); err != nil {
+		Error(w, r, err)
+		return
+	} else if version != nil {
+		upd.Version = version
+	}
+
 	// Update membership.
 	membership, err := s.DialMembershipService.UpdateDialMembership(r.Context(), id, upd)
 	if err != nil {
@@ -128,6 +250,7 @@ func (s *Server) handleDialMembershipUpdate(w http.ResponseWriter, r *http.Reque
 
 	// Write new membership state back as JSON response.
 	w.Header().Set("Content-type", "application/json")
+	w.Header().Set("ETag", formatETag(membership.Version))
 	if err := json.NewEncoder(w).Encode(membership); err != nil {
 		LogError(r, err)
 		return
@@ -135,7 +258,8 @@ func (s *Server) handleDialMembershipUpdate(w http.ResponseWriter, r *http.Reque
 }
 
 // handleDialMembershipDelete handles the "DELETE /dial-memberships/:id" route.
-// This route deletes the given membership and redirects the user.
+// This route deletes the given membership and redirects the user. JSON clients
+// receive an empty object on success.
 func (s *Server) handleDialMembershipDelete(w http.ResponseWriter, r *http.Request) {
 	// Parse membership ID from the URL.
 	id, err := strconv.Atoi(mux.Vars(r)["id"])
@@ -157,6 +281,13 @@ func (s *Server) handleDialMembershipDelete(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	// Write an empty response to JSON clients to indicate success.
+	if r.Header.Get("Accept") == "application/json" {
+		w.Header().Set("Content-type", "application/json")
+		w.Write([]byte(`{}`))
+		return
+	}
+
 	// Let user know the membership has been deleted.
 	SetFlash(w, "Dial membership successfully deleted.")
 
@@ -169,3 +300,143 @@ func (s *Server) handleDialMembershipDelete(w http.ResponseWriter, r *http.Reque
 		http.Redirect(w, r, "/dials", http.StatusFound)
 	}
 }
+
+// DialMembershipService implements the wtf.DialMembershipService over the HTTP protocol.
+type DialMembershipService struct {
+	Client *Client
+}
+
+// NewDialMembershipService returns a new instance of DialMembershipService.
+func NewDialMembershipService(client *Client) *DialMembershipService {
+	return &DialMembershipService{Client: client}
+}
+
+// FindDialMembershipByID is not implemented by the HTTP service.
+func (s *DialMembershipService) FindDialMembershipByID(ctx context.Context, id int) (*wtf.DialMembership, error) {
+	return nil, wtf.Errorf(wtf.ENOTIMPLEMENTED, "Not implemented.")
+}
+
+// FindDialMemberships retrieves a list of memberships based on a filter. Only
+// returns memberships of dials that the user is a member of. Also returns a
+// count of total matching memberships.
+func (s *DialMembershipService) FindDialMemberships(ctx context.Context, filter wtf.DialMembershipFilter) ([]*wtf.DialMembership, int, error) {
+	// Marshal filter into JSON format.
+	body, err := json.Marshal(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", "/dial-memberships", bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Issue request. Any non-200 status code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, 0, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal result set of memberships & total count.
+	var jsonResponse findDialMembershipsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&jsonResponse); err != nil {
+		return nil, 0, err
+	}
+	return jsonResponse.DialMemberships, jsonResponse.N, nil
+}
+
+// CreateDialMembership creates a new membership on a dial for the current user.
+//
+// Users can only join a dial through its invite code so membership.Dial must
+// be set with the InviteCode field. On success, the membership is replaced
+// with the created membership along with its associated dial & user.
+func (s *DialMembershipService) CreateDialMembership(ctx context.Context, membership *wtf.DialMembership) error {
+	if membership.Dial == nil || membership.Dial.InviteCode == "" {
+		return wtf.Errorf(wtf.EINVALID, "Invite code required.")
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "POST", "/invite/"+url.PathEscape(membership.Dial.InviteCode), nil)
+	if err != nil {
+		return err
+	}
+
+	// Issue request with an idempotency key so it can be safely retried.
+	// Treat non-201 status codes as errors.
+	resp, err := s.Client.doIdempotent(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusCreated {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal returned membership data.
+	if err := json.NewDecoder(resp.Body).Decode(&membership); err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpdateDialMembership updates the value of a membership. Only the owner of
+// the membership can update the value. If upd.Version is set then the update
+// is only applied if the membership has not changed since that version.
+func (s *DialMembershipService) UpdateDialMembership(ctx context.Context, id int, upd wtf.DialMembershipUpdate) (*wtf.DialMembership, error) {
+	// Marshal update data into JSON format.
+	body, err := json.Marshal(upd)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "PATCH", fmt.Sprintf("/dial-memberships/%d", id), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	// Only apply the update to the expected version, if set.
+	if upd.Version != nil {
+		req.Header.Set("If-Match", formatETag(*upd.Version))
+	}
+
+	// Issue request. Any non-200 response is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal the updated membership data.
+	var membership wtf.DialMembership
+	if err := json.NewDecoder(resp.Body).Decode(&membership); err != nil {
+		return nil, err
+	}
+	return &membership, nil
+}
+
+// DeleteDialMembership permanently deletes a membership by ID. Only the
+// membership owner and the parent dial's owner can delete a membership.
+func (s *DialMembershipService) DeleteDialMembership(ctx context.Context, id int) error {
+	// Create a request with API key.
+	req, err := s.Client.newRequest(ctx, "DELETE", fmt.Sprintf("/dial-memberships/%d", id), nil)
+	if err != nil {
+		return err
+	}
+
+	// Issue request. Any non-200 response is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusOK {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
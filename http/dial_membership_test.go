@@ -0,0 +1,178 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/benbjohnson/wtf"
+	wtfhttp "github.com/benbjohnson/wtf/http"
+)
+
+// Ensure the HTTP client can join a dial by invite code and then leave it.
+func TestDialMembershipCreate(t *testing.T) {
+	// Start the mocked HTTP test server.
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	// Create a single user and build a context with them.
+	user0 := &wtf.User{ID: 2, Name: "USER2", APIKey: "APIKEY"}
+	ctx0 := wtf.NewContextWithUser(context.Background(), user0)
+	dial := &wtf.Dial{ID: 1, UserID: 1, Name: "DIAL1", InviteCode: "INVITECODE"}
+
+	// Mock user look up by API key for API calls.
+	s.UserService.FindUsersFn = func(ctx context.Context, filter wtf.UserFilter) ([]*wtf.User, int, error) {
+		return []*wtf.User{user0}, 1, nil
+	}
+
+	// Mock dial look up by invite code.
+	s.DialService.FindDialsFn = func(ctx context.Context, filter wtf.DialFilter) ([]*wtf.Dial, int, error) {
+		if filter.InviteCode == nil || *filter.InviteCode != "INVITECODE" {
+			return nil, 0, nil
+		}
+		return []*wtf.Dial{dial}, 1, nil
+	}
+
+	// Ensure the membership is created for the current user.
+	t.Run("Join", func(t *testing.T) {
+		s.DialMembershipService.CreateDialMembershipFn = func(ctx context.Context, membership *wtf.DialMembership) error {
+			if got, want := membership.DialID, 1; got != want {
+				t.Fatalf("DialID=%v, want %v", got, want)
+			} else if got, want := membership.UserID, 2; got != want {
+				t.Fatalf("UserID=%v, want %v", got, want)
+			}
+			membership.ID, membership.Dial, membership.User = 10, dial, user0
+			return nil
+		}
+
+		membership := &wtf.DialMembership{Dial: &wtf.Dial{InviteCode: "INVITECODE"}}
+		svc := wtfhttp.NewDialMembershipService(wtfhttp.NewClient(s.URL()))
+		if err := svc.CreateDialMembership(ctx0, membership); err != nil {
+			t.Fatal(err)
+		} else if got, want := membership.ID, 10; got != want {
+			t.Fatalf("ID=%v, want %v", got, want)
+		} else if got, want := membership.Dial.Name, "DIAL1"; got != want {
+			t.Fatalf("Dial.Name=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure an unknown invite code returns a not found error.
+	t.Run("ErrInvalidInviteCode", func(t *testing.T) {
+		membership := &wtf.DialMembership{Dial: &wtf.Dial{InviteCode: "BADCODE"}}
+		svc := wtfhttp.NewDialMembershipService(wtfhttp.NewClient(s.URL()))
+		if err := svc.CreateDialMembership(ctx0, membership); wtf.ErrorCode(err) != wtf.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure the current user's membership is found & removed when leaving.
+	t.Run("Leave", func(t *testing.T) {
+		s.DialMembershipService.FindDialMembershipsFn = func(ctx context.Context, filter wtf.DialMembershipFilter) ([]*wtf.DialMembership, int, error) {
+			if filter.DialID == nil || *filter.DialID != 1 {
+				t.Fatalf("unexpected dial id: %#v", filter.DialID)
+			} else if filter.UserID == nil || *filter.UserID != 2 {
+				t.Fatalf("unexpected user id: %#v", filter.UserID)
+			}
+			return []*wtf.DialMembership{{ID: 10, DialID: 1, UserID: 2}}, 1, nil
+		}
+
+		var deletedID int
+		s.DialMembershipService.DeleteDialMembershipFn = func(ctx context.Context, id int) error {
+			deletedID = id
+			return nil
+		}
+
+		if err := wtfhttp.NewDialService(wtfhttp.NewClient(s.URL())).LeaveDial(ctx0, 1); err != nil {
+			t.Fatal(err)
+		} else if got, want := deletedID, 10; got != want {
+			t.Fatalf("deleted=%v, want %v", got, want)
+		}
+	})
+}
+
+// Ensure the HTTP client can list memberships & the server can export them as CSV.
+func TestDialMembershipIndex(t *testing.T) {
+	// Start the mocked HTTP test server.
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	// Create a single user and build a context with them.
+	user0 := &wtf.User{ID: 1, Name: "USER1", APIKey: "APIKEY"}
+	ctx0 := wtf.NewContextWithUser(context.Background(), user0)
+	s.UserService.FindUserByIDFn = func(ctx context.Context, id int) (*wtf.User, error) {
+		return user0, nil
+	}
+	s.UserService.FindUsersFn = func(ctx context.Context, filter wtf.UserFilter) ([]*wtf.User, int, error) {
+		return []*wtf.User{user0}, 1, nil
+	}
+
+	// Mock membership look up by dial.
+	s.DialMembershipService.FindDialMembershipsFn = func(ctx context.Context, filter wtf.DialMembershipFilter) ([]*wtf.DialMembership, int, error) {
+		if filter.DialID == nil || *filter.DialID != 1 {
+			t.Fatalf("unexpected dial id: %#v", filter.DialID)
+		}
+		return []*wtf.DialMembership{{ID: 2, DialID: 1, UserID: 1, Value: 50}}, 1, nil
+	}
+
+	// Ensure the filter is passed through by the client.
+	t.Run("JSON", func(t *testing.T) {
+		dialID := 1
+		svc := wtfhttp.NewDialMembershipService(wtfhttp.NewClient(s.URL()))
+		if memberships, n, err := svc.FindDialMemberships(ctx0, wtf.DialMembershipFilter{DialID: &dialID}); err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 1; got != want {
+			t.Fatalf("n=%v, want %v", got, want)
+		} else if got, want := memberships[0].Value, 50; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure the dialID query parameter is used for CSV exports.
+	t.Run("CSV", func(t *testing.T) {
+		resp, err := http.DefaultClient.Do(s.MustNewRequest(t, ctx0, "GET", "/dial-memberships.csv?dialID=1", nil))
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := resp.StatusCode, http.StatusOK; got != want {
+			t.Fatalf("StatusCode=%v, want %v", got, want)
+		} else if got, want := resp.Header.Get("Content-type"), "text/csv"; got != want {
+			t.Fatalf("Content-type=%v, want %v", got, want)
+		}
+	})
+}
+
+// Ensure membership updates can be made conditional on the membership version.
+func TestDialMembershipUpdate(t *testing.T) {
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	user0 := &wtf.User{ID: 1, Name: "USER1", APIKey: "APIKEY"}
+	ctx0 := wtf.NewContextWithUser(context.Background(), user0)
+	s.UserService.FindUsersFn = func(ctx context.Context, filter wtf.UserFilter) ([]*wtf.User, int, error) {
+		return []*wtf.User{user0}, 1, nil
+	}
+
+	// Mock update which only succeeds at version 3.
+	s.DialMembershipService.UpdateDialMembershipFn = func(ctx context.Context, id int, upd wtf.DialMembershipUpdate) (*wtf.DialMembership, error) {
+		if upd.Version == nil || *upd.Version != 3 {
+			return nil, wtf.Errorf(wtf.ECONFLICT, "Dial membership has been modified since it was last read.")
+		}
+		return &wtf.DialMembership{ID: id, Value: *upd.Value, Version: 4}, nil
+	}
+	svc := wtfhttp.NewDialMembershipService(wtfhttp.NewClient(s.URL()))
+
+	t.Run("OK", func(t *testing.T) {
+		value, version := 25, 3
+		if membership, err := svc.UpdateDialMembership(ctx0, 2, wtf.DialMembershipUpdate{Value: &value, Version: &version}); err != nil {
+			t.Fatal(err)
+		} else if got, want := membership.Version, 4; got != want {
+			t.Fatalf("Version=%v, want %v", got, want)
+		}
+	})
+
+	t.Run("ErrConflict", func(t *testing.T) {
+		value, version := 25, 2
+		if _, err := svc.UpdateDialMembership(ctx0, 2, wtf.DialMembershipUpdate{Value: &value, Version: &version}); wtf.ErrorCode(err) != wtf.ECONFLICT {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
@@ -2,6 +2,9 @@ package http_test
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
 	"net/http"
 	"strings"
 	"testing"
@@ -91,3 +94,286 @@ func TestDialIndex(t *testing.T) {
 		}
 	})
 }
+
+// Ensure the HTTP server returns cursors for the surrounding pages of dials.
+func TestDialIndex_Cursor(t *testing.T) {
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	user0 := &wtf.User{ID: 1, Name: "USER1", APIKey: "APIKEY"}
+	ctx0 := wtf.NewContextWithUser(context.Background(), user0)
+	s.UserService.FindUserByIDFn = func(ctx context.Context, id int) (*wtf.User, error) {
+		return user0, nil
+	}
+	s.UserService.FindUsersFn = func(ctx context.Context, filter wtf.UserFilter) ([]*wtf.User, int, error) {
+		return []*wtf.User{user0}, 1, nil
+	}
+
+	// Mock five dials & return the requested number after the cursor.
+	s.DialService.FindDialsFn = func(ctx context.Context, filter wtf.DialFilter) ([]*wtf.Dial, int, error) {
+		start := 0
+		if filter.After != "" {
+			cursor, err := wtf.ParseCursor(filter.After)
+			if err != nil {
+				return nil, 0, err
+			}
+			start = cursor.ID
+		}
+
+		var dials []*wtf.Dial
+		for id := start + 1; id <= 5 && len(dials) < filter.Limit; id++ {
+			dials = append(dials, &wtf.Dial{ID: id, Name: fmt.Sprintf("DIAL%d", id), User: user0})
+		}
+		return dials, 5, nil
+	}
+
+	// Ensure the JSON response includes the cursors.
+	t.Run("JSON", func(t *testing.T) {
+		req := s.MustNewRequest(t, ctx0, "GET", "/dials", strings.NewReader(`{"limit":2,"after":"`+wtf.Cursor{ID: 2}.String()+`"}`))
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("Content-type", "application/json")
+		req.Header.Set("Authorization", "Bearer APIKEY")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		var body struct {
+			Dials []*wtf.Dial `json:"dials"`
+			N     int         `json:"n"`
+			Prev  string      `json:"prev"`
+			Next  string      `json:"next"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(body.Dials), 2; got != want {
+			t.Fatalf("len(dials)=%d, want %d", got, want)
+		} else if got, want := body.N, 5; got != want {
+			t.Fatalf("n=%d, want %d", got, want)
+		} else if got, want := body.Prev, (wtf.Cursor{ID: 3}).String(); got != want {
+			t.Fatalf("prev=%q, want %q", got, want)
+		} else if got, want := body.Next, (wtf.Cursor{ID: 4}).String(); got != want {
+			t.Fatalf("next=%q, want %q", got, want)
+		}
+	})
+
+	// Ensure the HTML page links to the previous page.
+	t.Run("HTML", func(t *testing.T) {
+		resp, err := http.DefaultClient.Do(s.MustNewRequest(t, ctx0, "GET", "/dials?after="+(wtf.Cursor{ID: 2}).String(), nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if doc, err := goquery.NewDocumentFromReader(resp.Body); err != nil {
+			t.Fatal(err)
+		} else if got, want := doc.Find(".pagination a.page-link").AttrOr("href", ""), "/dials?before="+(wtf.Cursor{ID: 3}).String(); got != want {
+			t.Fatalf("href=%q, want %q", got, want)
+		}
+	})
+}
+
+// Ensure the HTTP server passes search & sort query parameters to the filter.
+func TestDialIndex_Search(t *testing.T) {
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	user0 := &wtf.User{ID: 1, Name: "USER1", APIKey: "APIKEY"}
+	ctx0 := wtf.NewContextWithUser(context.Background(), user0)
+	s.UserService.FindUsersFn = func(ctx context.Context, filter wtf.UserFilter) ([]*wtf.User, int, error) {
+		return []*wtf.User{user0}, 1, nil
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		s.DialService.FindDialsFn = func(ctx context.Context, filter wtf.DialFilter) ([]*wtf.Dial, int, error) {
+			if filter.Query == nil || *filter.Query != "deploy" {
+				t.Fatalf("unexpected query: %#v", filter.Query)
+			} else if filter.UserID == nil || *filter.UserID != 1 {
+				t.Fatalf("unexpected owner: %#v", filter.UserID)
+			} else if filter.MinValue == nil || *filter.MinValue != 10 {
+				t.Fatalf("unexpected min: %#v", filter.MinValue)
+			} else if filter.MaxValue == nil || *filter.MaxValue != 90 {
+				t.Fatalf("unexpected max: %#v", filter.MaxValue)
+			} else if filter.UpdatedSince == nil || !filter.UpdatedSince.Equal(time.Date(2000, time.January, 2, 0, 0, 0, 0, time.UTC)) {
+				t.Fatalf("unexpected updated since: %#v", filter.UpdatedSince)
+			} else if got, want := filter.SortBy, wtf.DialSortByValueDesc; got != want {
+				t.Fatalf("SortBy=%q, want %q", got, want)
+			}
+			return nil, 0, nil
+		}
+
+		req := s.MustNewRequest(t, ctx0, "GET", "/dials?q=deploy&owner=me&min=10&max=90&updated_since=2000-01-02&sort=value_desc", nil)
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("Authorization", "Bearer APIKEY")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if got, want := resp.StatusCode, http.StatusOK; got != want {
+			t.Fatalf("StatusCode=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure malformed parameters are rejected.
+	t.Run("ErrInvalid", func(t *testing.T) {
+		req := s.MustNewRequest(t, ctx0, "GET", "/dials?min=low", nil)
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("Authorization", "Bearer APIKEY")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if got, want := resp.StatusCode, http.StatusBadRequest; got != want {
+			t.Fatalf("StatusCode=%v, want %v", got, want)
+		}
+	})
+}
+
+// Ensure the HTTP server can export a dial's members as CSV.
+func TestDialView_CSV(t *testing.T) {
+	// Start the mocked HTTP test server.
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	// Create a single user and build a context with them.
+	user0 := &wtf.User{ID: 1, Name: "USER1", APIKey: "APIKEY"}
+	ctx0 := wtf.NewContextWithUser(context.Background(), user0)
+	s.UserService.FindUserByIDFn = func(ctx context.Context, id int) (*wtf.User, error) {
+		return user0, nil
+	}
+
+	// Mock dial & membership data.
+	dial := &wtf.Dial{ID: 1, UserID: 1, Name: "DIAL1"}
+	s.DialService.FindDialByIDFn = func(ctx context.Context, id int) (*wtf.Dial, error) {
+		return dial, nil
+	}
+	s.DialMembershipService.FindDialMembershipsFn = func(ctx context.Context, filter wtf.DialMembershipFilter) ([]*wtf.DialMembership, int, error) {
+		return []*wtf.DialMembership{{
+			ID:        2,
+			DialID:    1,
+			Dial:      dial,
+			UserID:    1,
+			User:      user0,
+			Value:     50,
+			CreatedAt: time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC),
+			UpdatedAt: time.Date(2000, time.January, 2, 0, 0, 0, 0, time.UTC),
+		}}, 1, nil
+	}
+
+	resp, err := http.DefaultClient.Do(s.MustNewRequest(t, ctx0, "GET", "/dials/1.csv", nil))
+	if err != nil {
+		t.Fatal(err)
+	} else if got, want := resp.StatusCode, http.StatusOK; got != want {
+		t.Fatalf("StatusCode=%v, want %v", got, want)
+	} else if got, want := resp.Header.Get("Content-type"), "text/csv"; got != want {
+		t.Fatalf("Content-type=%v, want %v", got, want)
+	}
+
+	if buf, err := ioutil.ReadAll(resp.Body); err != nil {
+		t.Fatal(err)
+	} else if got, want := string(buf), ""+
+		"id,dial_id,dial_name,user_id,user_name,value,created_at,updated_at\n"+
+		"2,1,DIAL1,1,USER1,50,2000-01-01T00:00:00Z,2000-01-02T00:00:00Z\n"; got != want {
+		t.Fatalf("body=%q, want %q", got, want)
+	}
+}
+
+// Ensure dial updates can be made conditional on the dial version.
+func TestDialUpdate(t *testing.T) {
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	user0 := &wtf.User{ID: 1, Name: "USER1", APIKey: "APIKEY"}
+	ctx0 := wtf.NewContextWithUser(context.Background(), user0)
+	s.UserService.FindUsersFn = func(ctx context.Context, filter wtf.UserFilter) ([]*wtf.User, int, error) {
+		return []*wtf.User{user0}, 1, nil
+	}
+
+	// Ensure the dial version is returned as a weak ETag.
+	t.Run("ETag", func(t *testing.T) {
+		s.DialService.FindDialByIDFn = func(ctx context.Context, id int) (*wtf.Dial, error) {
+			return &wtf.Dial{ID: 1, UserID: 1, Name: "DIAL1", Version: 3}, nil
+		}
+		s.DialMembershipService.FindDialMembershipsFn = func(ctx context.Context, filter wtf.DialMembershipFilter) ([]*wtf.DialMembership, int, error) {
+			return nil, 0, nil
+		}
+
+		req := s.MustNewRequest(t, ctx0, "GET", "/dials/1", nil)
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("Authorization", "Bearer APIKEY")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if got, want := resp.StatusCode, http.StatusOK; got != want {
+			t.Fatalf("StatusCode=%v, want %v", got, want)
+		} else if got, want := resp.Header.Get("ETag"), `W/"3"`; got != want {
+			t.Fatalf("ETag=%s, want %s", got, want)
+		}
+	})
+
+	// Ensure the client passes the expected version through "If-Match".
+	t.Run("IfMatch", func(t *testing.T) {
+		s.DialService.UpdateDialFn = func(ctx context.Context, id int, upd wtf.DialUpdate) (*wtf.Dial, error) {
+			if upd.Version == nil || *upd.Version != 3 {
+				t.Fatalf("unexpected version: %#v", upd.Version)
+			}
+			return &wtf.Dial{ID: id, Name: *upd.Name, Version: 4}, nil
+		}
+
+		name, version := "DIAL2", 3
+		dialService := wtfhttp.NewDialService(wtfhttp.NewClient(s.URL()))
+		if dial, err := dialService.UpdateDial(ctx0, 1, wtf.DialUpdate{Name: &name, Version: &version}); err != nil {
+			t.Fatal(err)
+		} else if got, want := dial.Version, 4; got != want {
+			t.Fatalf("Version=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure a version mismatch is returned as a conflict.
+	t.Run("ErrConflict", func(t *testing.T) {
+		s.DialService.UpdateDialFn = func(ctx context.Context, id int, upd wtf.DialUpdate) (*wtf.Dial, error) {
+			return nil, wtf.Errorf(wtf.ECONFLICT, "Dial has been modified since it was last read.")
+		}
+
+		req := s.MustNewRequest(t, ctx0, "PATCH", "/dials/1", strings.NewReader(`{"name":"DIAL2"}`))
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("Content-type", "application/json")
+		req.Header.Set("Authorization", "Bearer APIKEY")
+		req.Header.Set("If-Match", `"2"`)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if got, want := resp.StatusCode, http.StatusConflict; got != want {
+			t.Fatalf("StatusCode=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure a malformed "If-Match" header is rejected.
+	t.Run("ErrInvalidIfMatch", func(t *testing.T) {
+		req := s.MustNewRequest(t, ctx0, "PATCH", "/dials/1", strings.NewReader(`{"name":"DIAL2"}`))
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("Content-type", "application/json")
+		req.Header.Set("Authorization", "Bearer APIKEY")
+		req.Header.Set("If-Match", `W/"2"`)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if got, want := resp.StatusCode, http.StatusBadRequest; got != want {
+			t.Fatalf("StatusCode=%v, want %v", got, want)
+		}
+	})
+}
@@ -7,7 +7,9 @@ import (
 	"html"
 	"io"
 	"io/fs"
+	"math"
 	"net/url"
+	"strings"
 
 	"github.com/benbjohnson/wtf"
 	"github.com/benbjohnson/wtf/http/assets"
@@ -52,63 +54,54 @@ func (r *Flash) Render(ctx context.Context, w io.Writer) {
 	fmt.Fprint(w, `</div>`)
 }
 
+// Pagination renders links to the previous & next pages of results which are
+// paged by cursor. Links are only rendered if another page exists.
 type Pagination struct {
-	URL    url.URL
-	Offset int
-	Limit  int
-	N      int
+	URL url.URL
+
+	// Cursors for the previous & next pages. Empty if no page exists.
+	Prev string
+	Next string
+
+	// Total number of results across all pages.
+	N int
 }
 
 func (r *Pagination) Render(ctx context.Context, w io.Writer) {
-	// Do not render if no limit exists or there is only one page.
-	if r.Limit == 0 || r.N <= r.Limit {
+	// Do not render if there is only one page.
+	if r.Prev == "" && r.Next == "" {
 		return
 	}
 
-	// Determine the page range & current page.
-	current := (r.Offset / r.Limit) + 1
-	pageN := ((r.N - 1) / r.Limit) + 1
-
-	prev := current - 1
-	if prev <= 0 {
-		prev = 1
-	}
-	next := current + 1
-	if next >= pageN {
-		next = pageN
-	}
-
-	// Print container & "previous" link.
-	fmt.Fprint(w, `<nav aria-label="Page navigation">`)
-	fmt.Fprint(w, `<ul class="pagination pagination-sm justify-content-end mb-0">`)
-	fmt.Fprintf(w, `<li class="page-item"><a class="page-link" href="%s">Previous</a></li>`, r.pageURL(current-1))
+	// Print container & total count.
+	fmt.Fprint(w, `<nav class="d-flex align-items-center justify-content-end" aria-label="Page navigation">`)
+	fmt.Fprintf(w, `<span class="fs--1 text-500 mr-3">%d total</span>`, r.N)
+	fmt.Fprint(w, `<ul class="pagination pagination-sm mb-0">`)
 
-	// Print a button for each page number.
-	for page := 1; page <= pageN; page++ {
-		className := ""
-		if page == current {
-			className = " active"
-		}
-		fmt.Fprintf(w, `<li class="page-item %s"><a class="page-link" href="%s">%d</a></li>`, className, r.pageURL(page), page)
-	}
+	// Print "previous" & "next" links. Links are disabled for missing pages.
+	r.renderLink(w, "Previous", "before", r.Prev)
+	r.renderLink(w, "Next", "after", r.Next)
 
-	// Print "next" link & close container.
-	fmt.Fprintf(w, `<li class="page-item"><a class="page-link" href="%s">Next</a></li>`, r.pageURL(current+1))
+	// Close container.
 	fmt.Fprint(w, `</ul>`)
 	fmt.Fprint(w, `</nav>`)
 }
 
-func (r *Pagination) pageURL(page int) string {
-	// Ensure page number is within min/max.
-	pageN := ((r.N - 1) / r.Limit) + 1
-	if page < 1 {
-		page = 1
-	} else if page > pageN {
-		page = pageN
+func (r *Pagination) renderLink(w io.Writer, label, param, cursor string) {
+	if cursor == "" {
+		fmt.Fprintf(w, `<li class="page-item disabled"><span class="page-link">%s</span></li>`, label)
+		return
 	}
+	fmt.Fprintf(w, `<li class="page-item"><a class="page-link" href="%s">%s</a></li>`, html.EscapeString(r.pageURL(param, cursor)), label)
+}
 
+// pageURL returns the current URL with the page cursor replaced.
+func (r *Pagination) pageURL(param, cursor string) string {
 	q := r.URL.Query()
-	q.Set("offset", fmt.Sprint((page-1)*r.Limit))
+	q.Del("offset")
+	q.Del("after")
+	q.Del("before")
+	q.Set(param, cursor)
 	u := url.URL{Path: r.URL.Path, RawQuery: q.Encode()}
 	return u.String()
 }
@@ -126,17 +119,7 @@ func (r *WTFBadge) Render(ctx context.Context, w io.Writer) {
 		prefix = "badge-soft-"
 	}
 
-	var class string
-	switch {
-	case r.Value < 25:
-		class = prefix + "success"
-	case r.Value < 50:
-		class = prefix + "info"
-	case r.Value < 75:
-		class = prefix + "warning"
-	default:
-		class = prefix + "danger"
-	}
+	class := prefix + WTFLevel(r.Value)
 
 	fmt.Fprintf(w, `<span`)
 	fmt.Fprintf(w, ` class="wtf-badge wtf-value badge rounded-pill %s"`, class)
@@ -153,6 +136,86 @@ func (r *WTFBadge) Render(ctx context.Context, w io.Writer) {
 	fmt.Fprint(w, `</span>`)
 }
 
+// WTFLevel returns the color band for a WTF value. This must match the bands
+// used by updateWTFValueNode() in main.js.
+func WTFLevel(value int) string {
+	switch {
+	case value < 25:
+		return "success"
+	case value < 50:
+		return "info"
+	case value < 75:
+		return "warning"
+	default:
+		return "danger"
+	}
+}
+
+// SVGBadge renders a flat status badge as a standalone SVG image. The label
+// is drawn on the left & the value on the right, colored by its WTF level.
+type SVGBadge struct {
+	Label string
+	Value int
+}
+
+// svgBadgeColors are the theme colors for each WTF level.
+var svgBadgeColors = map[string]string{
+	"success": "#00d27a",
+	"info":    "#27bcfd",
+	"warning": "#f5803e",
+	"danger":  "#e63757",
+}
+
+func (r *SVGBadge) Render(ctx context.Context, w io.Writer) {
+	label, value := html.EscapeString(r.Label), fmt.Sprint(r.Value)
+	lw, vw := svgTextWidth(r.Label)+10, svgTextWidth(value)+10
+	width := lw + vw
+
+	fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s: %s">`, width, label, value)
+	fmt.Fprintf(w, `<title>%s: %s</title>`, label, value)
+	fmt.Fprint(w, `<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`)
+	fmt.Fprintf(w, `<clipPath id="r"><rect width="%d" height="20" rx="3" fill="#fff"/></clipPath>`, width)
+
+	// Draw the label & value backgrounds with a slight gradient.
+	fmt.Fprint(w, `<g clip-path="url(#r)">`)
+	fmt.Fprintf(w, `<rect width="%d" height="20" fill="#555"/>`, lw)
+	fmt.Fprintf(w, `<rect x="%d" width="%d" height="20" fill="%s"/>`, lw, vw, svgBadgeColors[WTFLevel(r.Value)])
+	fmt.Fprintf(w, `<rect width="%d" height="20" fill="url(#s)"/>`, width)
+	fmt.Fprint(w, `</g>`)
+
+	// Draw each text with a shadow underneath.
+	fmt.Fprint(w, `<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`)
+	for _, t := range []struct {
+		x    float64
+		text string
+	}{{float64(lw) / 2, label}, {float64(lw) + float64(vw)/2, value}} {
+		fmt.Fprintf(w, `<text x="%g" y="15" fill="#010101" fill-opacity=".3">%s</text>`, t.x, t.text)
+		fmt.Fprintf(w, `<text x="%g" y="14">%s</text>`, t.x, t.text)
+	}
+	fmt.Fprint(w, `</g>`)
+	fmt.Fprint(w, `</svg>`)
+}
+
+// svgTextWidth returns the approximate width, in pixels, of s when drawn in
+// 11px Verdana. Exact widths would require font metrics so characters are
+// grouped into narrow, regular & wide classes.
+func svgTextWidth(s string) int {
+	var width float64
+	for _, ch := range s {
+		switch {
+		case strings.ContainsRune(" !'(),.:;[]fijlrtI|", ch):
+			width += 4
+		case strings.ContainsRune("mwMW@%", ch):
+			width += 11
+		case ch >= 'A' && ch <= 'Z':
+			width += 8
+		default:
+			width += 7
+		}
+	}
+	return int(math.Ceil(width))
+}
+
 func marshalJSONTo(w io.Writer, v interface{}) {
 	json.NewEncoder(w).Encode(v)
 }
@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/benbjohnson/wtf"
@@ -26,11 +27,16 @@ var (
 // Client represents an HTTP client.
 type Client struct {
 	URL string
+
+	// Number of times a request which creates or changes data is retried
+	// after a network error. Retries reuse the same idempotency key so the
+	// change is only applied once.
+	RetryN int
 }
 
 // NewClient returns a new instance of Client.
 func NewClient(u string) *Client {
-	return &Client{URL: u}
+	return &Client{URL: u, RetryN: DefaultRetryN}
 }
 
 // newRequest returns a new HTTP request but adds the current user's API key
@@ -140,6 +146,76 @@ func LogError(r *http.Request, err error) {
 	log.Printf("[http] error: %s %s: %s", r.Method, r.URL.Path, err)
 }
 
+// formatETag returns an entity tag header value for an object version.
+func formatETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// formatWeakETag returns a weak entity tag for an object version. This is used
+// when the response includes related data that can change without changing
+// the object's version, such as the memberships of a dial.
+func formatWeakETag(version int) string {
+	return "W/" + formatETag(version)
+}
+
+// parseIfMatch returns the version from the request's "If-Match" header.
+// Returns nil if the header is not set or matches any version ("*").
+// Only a single, strong entity tag is supported.
+func parseIfMatch(r *http.Request) (*int, error) {
+	s := strings.TrimSpace(r.Header.Get("If-Match"))
+	if s == "" || s == "*" {
+		return nil, nil
+	} else if strings.HasPrefix(s, "W/") {
+		return nil, wtf.Errorf(wtf.EINVALID, "Weak entity tags cannot be used in If-Match. Pass the version as a strong tag instead.")
+	}
+
+	unquoted, err := strconv.Unquote(s)
+	if err != nil {
+		return nil, wtf.Errorf(wtf.EINVALID, "Invalid If-Match header.")
+	}
+	version, err := strconv.Atoi(unquoted)
+	if err != nil {
+		return nil, wtf.Errorf(wtf.EINVALID, "Invalid If-Match header.")
+	}
+	return &version, nil
+}
+
+// ifNoneMatch returns true if the request's "If-None-Match" header matches
+// etag. The header may be "*" or a comma-separated list of entity tags which
+// are compared using the weak comparison of RFC 7232, section 2.3.2, so the
+// "W/" prefix is ignored. Parsing stops at the first malformed tag.
+func ifNoneMatch(r *http.Request, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, s := range r.Header.Values("If-None-Match") {
+		for {
+			s = strings.TrimLeft(s, " \t,")
+			if s == "" {
+				break
+			} else if s[0] == '*' {
+				return true
+			}
+
+			// Read the quoted tag. Tags may contain commas so the list
+			// cannot simply be split.
+			s = strings.TrimPrefix(s, "W/")
+			if !strings.HasPrefix(s, `"`) {
+				return false
+			}
+			i := strings.IndexByte(s[1:], '"')
+			if i < 0 {
+				return false
+			}
+			tag := s[:i+2]
+			s = s[i+2:]
+
+			if tag == etag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // lookup of application error codes to HTTP status codes.
 var codes = map[string]int{
 	wtf.ECONFLICT:       http.StatusConflict,
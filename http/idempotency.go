@@ -0,0 +1,240 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// IdempotencyKeyHeader is the request header used to pass an idempotency key.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader is set on responses which were replayed from a
+// previous request with the same idempotency key.
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// MaxIdempotentBodySize is the default maximum size of a request body sent
+// with an idempotency key, in bytes. The body is read in full to fingerprint
+// the request so it is limited before the handler applies its own limit.
+const MaxIdempotentBodySize = 1 << 20
+
+// Client retry settings for requests sent with an idempotency key.
+const (
+	// DefaultRetryN is the default number of times a request is retried.
+	DefaultRetryN = 3
+
+	// RetryInterval is the time waited before the first retry. The wait
+	// doubles for each subsequent retry.
+	RetryInterval = 250 * time.Millisecond
+)
+
+// idempotent is middleware for handlers which create or change data. If the
+// request has an idempotency key then the response is stored so that repeated
+// requests with the same key receive the original response instead of being
+// executed again. Requests without a key are handled normally.
+//
+// Reusing a key with a different request returns EINVALID. Repeating a request
+// while the original is still executing returns ECONFLICT. Internal errors are
+// not stored so that the request can be retried. Bodies larger than
+// maxBodySize are rejected with EINVALID.
+//
+// The key is completed or released with a context that is not canceled when
+// the client disconnects. Otherwise the key would stay reserved & retries
+// would be rejected until it expires. The key is also released if the
+// handler panics.
+func (s *Server) idempotent(maxBodySize int64, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" || s.IdempotencyKeyService == nil || wtf.UserIDFromContext(r.Context()) == 0 {
+			next(w, r)
+			return
+		}
+
+		if r.Body != nil {
+			r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+		}
+		fingerprint, err := requestFingerprint(r)
+		if err != nil {
+			Error(w, r, wtf.Errorf(wtf.EINVALID, "Request body is too large or could not be read."))
+			return
+		}
+
+		// Reserve the key. If it already exists then replay the response.
+		k := &wtf.IdempotencyKey{Key: key, Fingerprint: fingerprint}
+		if err := s.IdempotencyKeyService.CreateIdempotencyKey(r.Context(), k); wtf.ErrorCode(err) == wtf.ECONFLICT {
+			s.replayIdempotentResponse(w, r, key, fingerprint)
+			return
+		} else if err != nil {
+			Error(w, r, err)
+			return
+		}
+
+		// Finish the key even if the request is canceled. The user is kept
+		// so the key can only be changed by its owner.
+		ctx := wtf.NewContextWithUser(context.Background(), wtf.UserFromContext(r.Context()))
+
+		// Release the key if the handler panics so the client can retry.
+		// The panic is then passed on to be reported.
+		defer func() {
+			if v := recover(); v != nil {
+				if err := s.IdempotencyKeyService.DeleteIdempotencyKey(ctx, k.ID); err != nil {
+					LogError(r, err)
+				}
+				panic(v)
+			}
+		}()
+
+		// Execute the request while recording the response.
+		rec := &idempotencyResponseWriter{ResponseWriter: w}
+		next(rec, r)
+
+		// Release the key on internal errors so the client can retry.
+		// Otherwise store the response for future repeats of the request.
+		if rec.statusCode() >= http.StatusInternalServerError {
+			if err := s.IdempotencyKeyService.DeleteIdempotencyKey(ctx, k.ID); err != nil {
+				LogError(r, err)
+			}
+			return
+		}
+
+		header := rec.Header()
+		if err := s.IdempotencyKeyService.CompleteIdempotencyKey(ctx, k.ID,
+			rec.statusCode(), header.Get("Content-type"), header.Get("Location"), rec.buf.Bytes(),
+		); err != nil {
+			LogError(r, err)
+		}
+	}
+}
+
+// replayIdempotentResponse writes the stored response for an existing key.
+func (s *Server) replayIdempotentResponse(w http.ResponseWriter, r *http.Request, key, fingerprint string) {
+	k, err := s.IdempotencyKeyService.FindIdempotencyKey(r.Context(), key)
+	if err != nil {
+		Error(w, r, err)
+		return
+	} else if k.Fingerprint != fingerprint {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Idempotency key has already been used for a different request."))
+		return
+	} else if !k.Completed() {
+		Error(w, r, wtf.Errorf(wtf.ECONFLICT, "A request with this idempotency key is still in progress."))
+		return
+	}
+
+	if k.ContentType != "" {
+		w.Header().Set("Content-type", k.ContentType)
+	}
+	if k.Location != "" {
+		w.Header().Set("Location", k.Location)
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(k.StatusCode)
+	w.Write(k.Body)
+}
+
+// requestFingerprint returns a hash of the request's method, path & body.
+// The body is restored so that it can still be read by the handler.
+func requestFingerprint(r *http.Request) (string, error) {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.Path+"\n")
+
+	// Form bodies may have already been read while checking for a method
+	// override so the parsed form is used instead.
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-type"))
+	if mediaType == "application/x-www-form-urlencoded" && r.PostForm != nil {
+		io.WriteString(h, r.PostForm.Encode())
+	} else if r.Body != nil {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return "", err
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		h.Write(body)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// idempotencyResponseWriter wraps a response writer to record the response.
+type idempotencyResponseWriter struct {
+	http.ResponseWriter
+	code int
+	buf  bytes.Buffer
+}
+
+// WriteHeader records the status code & writes it to the underlying writer.
+func (w *idempotencyResponseWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write records p & writes it to the underlying writer.
+func (w *idempotencyResponseWriter) Write(p []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	w.buf.Write(p)
+	return w.ResponseWriter.Write(p)
+}
+
+// statusCode returns the status code written to the response.
+func (w *idempotencyResponseWriter) statusCode() int {
+	if w.code == 0 {
+		return http.StatusOK
+	}
+	return w.code
+}
+
+// generateIdempotencyKey returns a random key for a client request.
+func generateIdempotencyKey() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// doIdempotent issues a request which creates or changes data. A random
+// idempotency key is attached so that the request can be safely retried if
+// the connection fails. The request is retried up to c.RetryN times with the
+// same key. Error responses from the server are not retried.
+func (c *Client) doIdempotent(req *http.Request) (*http.Response, error) {
+	key, err := generateIdempotencyKey()
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(IdempotencyKeyHeader, key)
+
+	interval := RetryInterval
+	for i := 0; ; i++ {
+		resp, err := http.DefaultClient.Do(req)
+		if err == nil || i >= c.RetryN || req.GetBody == nil && req.Body != nil {
+			return resp, err
+		}
+
+		// Wait before retrying, unless the request has been canceled.
+		select {
+		case <-req.Context().Done():
+			return nil, err
+		case <-time.After(interval):
+		}
+		interval *= 2
+
+		// Rewind the body for the next attempt.
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package http_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/benbjohnson/wtf"
+	wtfhttp "github.com/benbjohnson/wtf/http"
+)
+
+// Ensure requests with an idempotency key are executed once & replayed.
+func TestIdempotency(t *testing.T) {
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	user0 := &wtf.User{ID: 1, Name: "USER1", APIKey: "APIKEY"}
+	ctx0 := wtf.NewContextWithUser(context.Background(), user0)
+	s.UserService.FindUsersFn = func(ctx context.Context, filter wtf.UserFilter) ([]*wtf.User, int, error) {
+		return []*wtf.User{user0}, 1, nil
+	}
+
+	// Attach mock key storage backed by an in-memory map. Other tests run
+	// without the service so that idempotency keys are ignored.
+	s.Server.IdempotencyKeyService = &s.IdempotencyKeyService
+	keys := make(map[string]*wtf.IdempotencyKey)
+	s.IdempotencyKeyService.FindIdempotencyKeyFn = func(ctx context.Context, key string) (*wtf.IdempotencyKey, error) {
+		if k := keys[key]; k != nil {
+			return k, nil
+		}
+		return nil, wtf.Errorf(wtf.ENOTFOUND, "Idempotency key not found.")
+	}
+	s.IdempotencyKeyService.CreateIdempotencyKeyFn = func(ctx context.Context, key *wtf.IdempotencyKey) error {
+		if keys[key.Key] != nil {
+			return wtf.Errorf(wtf.ECONFLICT, "Idempotency key already exists.")
+		}
+		key.ID = len(keys) + 1
+		keys[key.Key] = key
+		return nil
+	}
+	s.IdempotencyKeyService.CompleteIdempotencyKeyFn = func(ctx context.Context, id int, statusCode int, contentType, location string, body []byte) error {
+		for _, k := range keys {
+			if k.ID == id {
+				k.StatusCode, k.ContentType, k.Location, k.Body = statusCode, contentType, location, body
+				return nil
+			}
+		}
+		return wtf.Errorf(wtf.ENOTFOUND, "Idempotency key not found.")
+	}
+
+	var deletedUserID int
+	s.IdempotencyKeyService.DeleteIdempotencyKeyFn = func(ctx context.Context, id int) error {
+		for name, k := range keys {
+			if k.ID == id {
+				delete(keys, name)
+				deletedUserID = wtf.UserIDFromContext(ctx)
+				return nil
+			}
+		}
+		return wtf.Errorf(wtf.ENOTFOUND, "Idempotency key not found.")
+	}
+
+	var createN int
+	s.DialService.CreateDialFn = func(ctx context.Context, dial *wtf.Dial) error {
+		if dial.Name == "PANIC" {
+			panic("marker")
+		}
+		createN++
+		dial.ID, dial.UserID = createN, 1
+		return nil
+	}
+
+	// Issues a dial creation request with the given key & body.
+	createDial := func(tb testing.TB, key, body string) *http.Response {
+		tb.Helper()
+		req := s.MustNewRequest(tb, ctx0, "POST", "/dials", strings.NewReader(body))
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("Content-type", "application/json")
+		req.Header.Set("Authorization", "Bearer APIKEY")
+		req.Header.Set(wtfhttp.IdempotencyKeyHeader, key)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		return resp
+	}
+
+	// Ensure a repeated request returns the original response.
+	t.Run("Replay", func(t *testing.T) {
+		resp0 := createDial(t, "KEY1", `{"name":"DIAL1"}`)
+		defer resp0.Body.Close()
+		body0, _ := ioutil.ReadAll(resp0.Body)
+		if got, want := resp0.StatusCode, http.StatusCreated; got != want {
+			t.Fatalf("StatusCode=%v, want %v", got, want)
+		}
+
+		resp1 := createDial(t, "KEY1", `{"name":"DIAL1"}`)
+		defer resp1.Body.Close()
+		body1, _ := ioutil.ReadAll(resp1.Body)
+		if got, want := resp1.StatusCode, http.StatusCreated; got != want {
+			t.Fatalf("StatusCode=%v, want %v", got, want)
+		} else if got, want := resp1.Header.Get(wtfhttp.IdempotentReplayedHeader), "true"; got != want {
+			t.Fatalf("Idempotent-Replayed=%q, want %q", got, want)
+		} else if got, want := string(body1), string(body0); got != want {
+			t.Fatalf("Body=%s, want %s", got, want)
+		} else if got, want := createN, 1; got != want {
+			t.Fatalf("CreateDial() n=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure a key cannot be reused with a different request body.
+	t.Run("ErrDifferentRequest", func(t *testing.T) {
+		resp0 := createDial(t, "KEY2", `{"name":"DIAL2"}`)
+		resp0.Body.Close()
+
+		resp1 := createDial(t, "KEY2", `{"name":"DIAL3"}`)
+		defer resp1.Body.Close()
+		if got, want := resp1.StatusCode, http.StatusBadRequest; got != want {
+			t.Fatalf("StatusCode=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure the key is released if the handler panics so it can be retried.
+	t.Run("Panic", func(t *testing.T) {
+		resp := createDial(t, "KEY3", `{"name":"PANIC"}`)
+		resp.Body.Close()
+		if got, want := resp.StatusCode, http.StatusInternalServerError; got != want {
+			t.Fatalf("StatusCode=%v, want %v", got, want)
+		} else if _, ok := keys["KEY3"]; ok {
+			t.Fatal("expected key to be released")
+		} else if got, want := deletedUserID, 1; got != want {
+			t.Fatalf("UserID=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure bodies are limited before they are read for the fingerprint.
+	t.Run("ErrTooLarge", func(t *testing.T) {
+		body := `{"name":"` + strings.Repeat("x", wtfhttp.MaxIdempotentBodySize) + `"}`
+		resp := createDial(t, "KEY4", body)
+		resp.Body.Close()
+		if got, want := resp.StatusCode, http.StatusBadRequest; got != want {
+			t.Fatalf("StatusCode=%v, want %v", got, want)
+		} else if _, ok := keys["KEY4"]; ok {
+			t.Fatal("expected key to not be reserved")
+		}
+	})
+
+	// Ensure the Go client generates a unique key for each request.
+	t.Run("Client", func(t *testing.T) {
+		n := len(keys)
+		dialService := wtfhttp.NewDialService(wtfhttp.NewClient(s.URL()))
+		if err := dialService.CreateDial(ctx0, &wtf.Dial{Name: "DIAL4"}); err != nil {
+			t.Fatal(err)
+		} else if err := dialService.CreateDial(ctx0, &wtf.Dial{Name: "DIAL4"}); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(keys), n+2; got != want {
+			t.Fatalf("len(keys)=%v, want %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,318 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/csv"
+	"github.com/benbjohnson/wtf/svg"
+	"github.com/gorilla/mux"
+)
+
+// Report defaults & limits.
+const (
+	// DefaultReportPeriod is the length of a report when no start time is given.
+	DefaultReportPeriod = 24 * time.Hour
+
+	// DefaultReportInterval is the size of each slot when none is given.
+	DefaultReportInterval = 15 * time.Minute
+
+	// MaxReportSlots is the maximum number of intervals that can be requested
+	// in a single report. This protects the server from very large reports.
+	MaxReportSlots = 10000
+
+	// DefaultForecastPeriod is the length of history used for a forecast when
+	// no start time is given. It covers enough days to find a daily cycle.
+	DefaultForecastPeriod = 7 * 24 * time.Hour
+)
+
+// registerReportRoutes is a helper function for registering value report routes.
+func (s *Server) registerReportRoutes(r *mux.Router) {
+	// Average value across all dials the user is a member of.
+	r.HandleFunc("/report", s.handleReport).Methods("GET")
+	r.HandleFunc("/report/chart.svg", s.handleReportChart).Methods("GET")
+
+	// Value of a single dial.
+	r.HandleFunc("/dials/{id}/report", s.handleDialReport).Methods("GET")
+	r.HandleFunc("/dials/{id}/chart.svg", s.handleDialChart).Methods("GET")
+
+	// Projected values of a single dial.
+	r.HandleFunc("/dials/{id}/forecast", s.handleDialForecast).Methods("GET")
+}
+
+// handleReport handles the "GET /report" route. It returns the average value
+// across all of the user's dials.
+//
+// The endpoint works with JSON & CSV formats.
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
+	start, end, interval, err := parseReportRange(r.URL.Query(), reportLocation(r), DefaultReportPeriod)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	report, err := s.DialService.AverageDialValueReport(r.Context(), start, end, interval)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+	writeReport(w, r, report)
+}
+
+// handleDialReport handles the "GET /dials/:id/report" route. It returns the
+// value of a single dial over time.
+//
+// The endpoint works with JSON & CSV formats.
+func (s *Server) handleDialReport(w http.ResponseWriter, r *http.Request) {
+	// Parse ID from path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	start, end, interval, err := parseReportRange(r.URL.Query(), reportLocation(r), DefaultReportPeriod)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	report, err := s.DialService.DialValueReport(r.Context(), id, start, end, interval)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+	writeReport(w, r, report)
+}
+
+// handleReportChart handles the "GET /report/chart.svg" route. It renders the
+// average value across all of the user's dials as an SVG chart. The range &
+// interval are set with the same parameters as "GET /report".
+func (s *Server) handleReportChart(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	start, end, interval, err := parseReportRange(q, reportLocation(r), DefaultReportPeriod)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	report, err := s.DialService.AverageDialValueReport(r.Context(), start, end, interval)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+	writeReportChart(w, r, report, "Average WTF Level", interval)
+}
+
+// handleDialChart handles the "GET /dials/:id/chart.svg" route. It renders the
+// value of a single dial over time as an SVG chart. The range & interval are
+// set with the same parameters as "GET /dials/:id/report".
+func (s *Server) handleDialChart(w http.ResponseWriter, r *http.Request) {
+	// Parse ID from path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	start, end, interval, err := parseReportRange(r.URL.Query(), reportLocation(r), DefaultReportPeriod)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Fetch the dial for its name & then its history.
+	dial, err := s.DialService.FindDialByID(r.Context(), id)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+	report, err := s.DialService.DialValueReport(r.Context(), id, start, end, interval)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+	writeReportChart(w, r, report, dial.Name, interval)
+}
+
+// handleDialForecast handles the "GET /dials/:id/forecast" route. It returns
+// the projected value of a single dial for the "n" intervals following the
+// report range. The "model" parameter chooses the forecast model. By default,
+// a model is chosen based on the amount of history.
+//
+// The endpoint only works with the JSON format.
+func (s *Server) handleDialForecast(w http.ResponseWriter, r *http.Request) {
+	// Parse ID from path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	q := r.URL.Query()
+	start, end, interval, err := parseReportRange(q, reportLocation(r), DefaultForecastPeriod)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	n := wtf.DefaultForecastN
+	if v := q.Get("n"); v != "" {
+		if n, err = strconv.Atoi(v); err != nil {
+			Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid forecast size format."))
+			return
+		}
+	}
+
+	// Fetch the dial's history & project it forward.
+	report, err := s.DialService.DialValueReport(r.Context(), id, start, end, interval)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+	forecast, err := wtf.ForecastDialValueReport(report, interval, q.Get("model"), n)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-type", "application/json")
+	if err := json.NewEncoder(w).Encode(forecast); err != nil {
+		LogError(r, err)
+		return
+	}
+}
+
+// writeReport writes report to w as CSV, if requested. Otherwise as JSON.
+func writeReport(w http.ResponseWriter, r *http.Request, report *wtf.DialValueReport) {
+	switch r.Header.Get("Accept") {
+	case "text/csv":
+		w.Header().Set("Content-type", "text/csv")
+		enc := csv.NewDialValueReportEncoder(w)
+		if err := enc.EncodeDialValueReport(report); err != nil {
+			LogError(r, err)
+			return
+		} else if err := enc.Close(); err != nil {
+			LogError(r, err)
+			return
+		}
+
+	default:
+		w.Header().Set("Content-type", "application/json")
+		if err := json.NewEncoder(w).Encode(report); err != nil {
+			LogError(r, err)
+			return
+		}
+	}
+}
+
+// writeReportChart writes report to w as an SVG chart. The chart size can be
+// set with the "width" & "height" query parameters. Labels use the time zone
+// of the report interval.
+func writeReportChart(w http.ResponseWriter, r *http.Request, report *wtf.DialValueReport, title string, interval wtf.ReportInterval) {
+	q := r.URL.Query()
+	width, height := svg.DefaultChartWidth, svg.DefaultChartHeight
+	for _, p := range []struct {
+		name string
+		v    *int
+	}{{"width", &width}, {"height", &height}} {
+		if s := q.Get(p.name); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < svg.MinChartSize || n > svg.MaxChartSize {
+				Error(w, r, wtf.Errorf(wtf.EINVALID, "Chart %s must be between %d and %d.", p.name, svg.MinChartSize, svg.MaxChartSize))
+				return
+			}
+			*p.v = n
+		}
+	}
+
+	w.Header().Set("Content-type", "image/svg+xml")
+	enc := svg.NewDialValueReportEncoder(w)
+	enc.Width, enc.Height, enc.Title = width, height, title
+	if interval.Location != nil {
+		enc.Location = interval.Location
+	}
+	if err := enc.EncodeDialValueReport(report); err != nil {
+		LogError(r, err)
+		return
+	}
+}
+
+// parseReportRange parses the "start", "end", "interval" & "tz" query
+// parameters. Times are RFC 3339 formatted and the interval is either a
+// calendar unit ("day", "week" or "month") or uses Go's duration format.
+// Calendar intervals are aligned to the "tz" time zone, if specified.
+// Otherwise they are aligned to loc.
+//
+// The end time defaults to the end of the current interval so the latest
+// value is included. The start defaults to period before the end.
+func parseReportRange(q url.Values, loc *time.Location, period time.Duration) (start, end time.Time, interval wtf.ReportInterval, err error) {
+	if v := q.Get("tz"); v != "" {
+		if loc, err = time.LoadLocation(v); err != nil {
+			return start, end, interval, wtf.Errorf(wtf.EINVALID, "Invalid time zone.")
+		}
+	}
+
+	interval = wtf.ReportInterval{Duration: DefaultReportInterval, Location: loc}
+	if v := q.Get("interval"); v != "" {
+		if interval, err = wtf.ParseReportInterval(v, loc); err != nil {
+			return start, end, interval, err
+		}
+	}
+	if err := interval.Validate(); err != nil {
+		return start, end, interval, err
+	}
+
+	end = interval.Next(interval.Truncate(time.Now()))
+	if v := q.Get("end"); v != "" {
+		if end, err = time.Parse(time.RFC3339, v); err != nil {
+			return start, end, interval, wtf.Errorf(wtf.EINVALID, "Invalid end time format.")
+		}
+	}
+
+	start = end.Add(-period)
+	if v := q.Get("start"); v != "" {
+		if start, err = time.Parse(time.RFC3339, v); err != nil {
+			return start, end, interval, wtf.Errorf(wtf.EINVALID, "Invalid start time format.")
+		}
+	}
+
+	// Validate the range & ensure the report isn't too large.
+	if !start.Before(end) {
+		return start, end, interval, wtf.Errorf(wtf.EINVALID, "Start time must be before end time.")
+	} else if interval.SlotN(start, end) > MaxReportSlots {
+		return start, end, interval, wtf.Errorf(wtf.EINVALID, "Report cannot contain more than %d intervals.", MaxReportSlots)
+	}
+	return start, end, interval, nil
+}
+
+// reportLocation returns the time zone of the current user. Returns UTC if
+// the user is not logged in or has not chosen a time zone.
+func reportLocation(r *http.Request) *time.Location {
+	if user := wtf.UserFromContext(r.Context()); user != nil {
+		return user.Location()
+	}
+	return time.UTC
+}
+
+// reportQuery returns the encoded query parameters for a report request.
+// Zero times are omitted so that the server uses its defaults. The time zone
+// is only sent if the interval specifies one.
+func reportQuery(start, end time.Time, interval wtf.ReportInterval) string {
+	q := make(url.Values)
+	if !start.IsZero() {
+		q.Set("start", start.UTC().Format(time.RFC3339))
+	}
+	if !end.IsZero() {
+		q.Set("end", end.UTC().Format(time.RFC3339))
+	}
+	q.Set("interval", interval.String())
+	if interval.Location != nil {
+		q.Set("tz", interval.Location.String())
+	}
+	return q.Encode()
+}
@@ -0,0 +1,365 @@
+package http_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	wtfhttp "github.com/benbjohnson/wtf/http"
+)
+
+// Ensure the HTTP client can fetch average & per-dial value reports.
+func TestDialService_Report(t *testing.T) {
+	// Start the mocked HTTP test server.
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	user0 := &wtf.User{ID: 1, Name: "USER1", APIKey: "APIKEY"}
+	ctx0 := wtf.NewContextWithUser(context.Background(), user0)
+	s.UserService.FindUserByIDFn = func(ctx context.Context, id int) (*wtf.User, error) {
+		return user0, nil
+	}
+	s.UserService.FindUsersFn = func(ctx context.Context, filter wtf.UserFilter) ([]*wtf.User, int, error) {
+		return []*wtf.User{user0}, 1, nil
+	}
+
+	start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	records := []*wtf.DialValueRecord{
+		{Value: 10, Timestamp: start, Min: 0, Max: 10, Median: 5, StdDev: 5, Count: 2, Members: 1},
+		{Value: 20, Timestamp: start.Add(30 * time.Minute), Min: 10, Max: 20, Median: 15, StdDev: 4.71, Count: 3, Members: 2},
+	}
+
+	// Ensure the time range is passed through to the average report.
+	t.Run("Average", func(t *testing.T) {
+		s.DialService.AverageDialValueReportFn = func(ctx context.Context, a, b time.Time, interval wtf.ReportInterval) (*wtf.DialValueReport, error) {
+			if !a.Equal(start) || !b.Equal(end) || interval.Duration != 30*time.Minute {
+				t.Fatalf("unexpected range: %s-%s %s", a, b, interval)
+			}
+			return &wtf.DialValueReport{Records: records}, nil
+		}
+
+		svc := wtfhttp.NewDialService(wtfhttp.NewClient(s.URL()))
+		if report, err := svc.AverageDialValueReport(ctx0, start, end, wtf.ReportInterval{Duration: 30 * time.Minute}); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(report.Records, records) {
+			t.Fatalf("unexpected records: %#v", report.Records)
+		}
+	})
+
+	// Ensure the dial ID is passed through to the per-dial report.
+	t.Run("Dial", func(t *testing.T) {
+		s.DialService.DialValueReportFn = func(ctx context.Context, id int, a, b time.Time, interval wtf.ReportInterval) (*wtf.DialValueReport, error) {
+			if id != 5 {
+				t.Fatalf("unexpected id: %d", id)
+			}
+			return &wtf.DialValueReport{Records: records}, nil
+		}
+
+		svc := wtfhttp.NewDialService(wtfhttp.NewClient(s.URL()))
+		if report, err := svc.DialValueReport(ctx0, 5, start, end, wtf.ReportInterval{Duration: 30 * time.Minute}); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(report.Records, records) {
+			t.Fatalf("unexpected records: %#v", report.Records)
+		}
+	})
+
+	// Ensure calendar intervals are passed through with their time zone.
+	t.Run("Calendar", func(t *testing.T) {
+		loc, err := time.LoadLocation("America/New_York")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		s.DialService.AverageDialValueReportFn = func(ctx context.Context, a, b time.Time, interval wtf.ReportInterval) (*wtf.DialValueReport, error) {
+			if interval.Unit != wtf.ReportUnitWeek || interval.Location.String() != "America/New_York" {
+				t.Fatalf("unexpected interval: %s %s", interval, interval.Location)
+			}
+			return &wtf.DialValueReport{Records: records}, nil
+		}
+
+		svc := wtfhttp.NewDialService(wtfhttp.NewClient(s.URL()))
+		if _, err := svc.AverageDialValueReport(ctx0, start, start.AddDate(0, 1, 0), wtf.ReportInterval{Unit: wtf.ReportUnitWeek, Location: loc}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	// Ensure calendar intervals default to the user's time zone.
+	t.Run("UserTimeZone", func(t *testing.T) {
+		user0.TimeZone = "Europe/Paris"
+		defer func() { user0.TimeZone = "" }()
+
+		s.DialService.AverageDialValueReportFn = func(ctx context.Context, a, b time.Time, interval wtf.ReportInterval) (*wtf.DialValueReport, error) {
+			if interval.Unit != wtf.ReportUnitDay || interval.Location.String() != "Europe/Paris" {
+				t.Fatalf("unexpected interval: %s %s", interval, interval.Location)
+			}
+			return &wtf.DialValueReport{Records: records}, nil
+		}
+
+		resp, err := http.DefaultClient.Do(s.MustNewRequest(t, ctx0, "GET", "/report?"+url.Values{
+			"start":    {start.Format(time.RFC3339)},
+			"end":      {start.AddDate(0, 0, 7).Format(time.RFC3339)},
+			"interval": {"day"},
+		}.Encode(), nil))
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := resp.StatusCode, http.StatusOK; got != want {
+			t.Fatalf("StatusCode=%v, want %v", got, want)
+		}
+		resp.Body.Close()
+	})
+
+	// Ensure the report can be exported as CSV.
+	t.Run("CSV", func(t *testing.T) {
+		s.DialService.AverageDialValueReportFn = func(ctx context.Context, a, b time.Time, interval wtf.ReportInterval) (*wtf.DialValueReport, error) {
+			return &wtf.DialValueReport{Records: records}, nil
+		}
+
+		resp, err := http.DefaultClient.Do(s.MustNewRequest(t, ctx0, "GET", "/report.csv?"+url.Values{
+			"start": {start.Format(time.RFC3339)},
+			"end":   {end.Format(time.RFC3339)},
+		}.Encode(), nil))
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := resp.StatusCode, http.StatusOK; got != want {
+			t.Fatalf("StatusCode=%v, want %v", got, want)
+		}
+
+		if buf, err := ioutil.ReadAll(resp.Body); err != nil {
+			t.Fatal(err)
+		} else if got, want := string(buf), ""+
+			"timestamp,value,min,max,median,stddev,count,members\n"+
+			"2000-01-01T00:00:00Z,10,0,10,5,5,2,1\n"+
+			"2000-01-01T00:30:00Z,20,10,20,15,4.71,3,2\n"; got != want {
+			t.Fatalf("body=%q, want %q", got, want)
+		}
+	})
+
+	// Ensure intervals below the minimum are rejected.
+	t.Run("ErrInterval", func(t *testing.T) {
+		svc := wtfhttp.NewDialService(wtfhttp.NewClient(s.URL()))
+		if _, err := svc.AverageDialValueReport(ctx0, start, end, wtf.ReportInterval{Duration: time.Second}); wtf.ErrorCode(err) != wtf.EINVALID {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure unknown time zones are rejected.
+	t.Run("ErrTimeZone", func(t *testing.T) {
+		resp, err := http.DefaultClient.Do(s.MustNewRequest(t, ctx0, "GET", "/report?tz=Mars%2FOlympus_Mons", nil))
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := resp.StatusCode, http.StatusBadRequest; got != want {
+			t.Fatalf("StatusCode=%v, want %v", got, want)
+		}
+		resp.Body.Close()
+	})
+}
+
+// Ensure the HTTP client can fetch dial value forecasts.
+func TestDialService_DialValueForecast(t *testing.T) {
+	// Start the mocked HTTP test server.
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	user0 := &wtf.User{ID: 1, Name: "USER1", APIKey: "APIKEY"}
+	ctx0 := wtf.NewContextWithUser(context.Background(), user0)
+	s.UserService.FindUserByIDFn = func(ctx context.Context, id int) (*wtf.User, error) {
+		return user0, nil
+	}
+	s.UserService.FindUsersFn = func(ctx context.Context, filter wtf.UserFilter) ([]*wtf.User, int, error) {
+		return []*wtf.User{user0}, 1, nil
+	}
+
+	// Generates a report of hourly records with the given values.
+	start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	newReport := func(values []int) *wtf.DialValueReport {
+		report := &wtf.DialValueReport{}
+		for i, v := range values {
+			report.Records = append(report.Records, &wtf.DialValueRecord{Value: v, Timestamp: start.Add(time.Duration(i) * time.Hour)})
+		}
+		return report
+	}
+	interval := wtf.ReportInterval{Duration: time.Hour}
+
+	// Ensure a steady trend is projected forward along a straight line with
+	// no uncertainty.
+	t.Run("Linear", func(t *testing.T) {
+		s.DialService.DialValueReportFn = func(ctx context.Context, id int, a, b time.Time, interval wtf.ReportInterval) (*wtf.DialValueReport, error) {
+			if id != 5 {
+				t.Fatalf("unexpected id: %d", id)
+			}
+			return newReport([]int{10, 12, 14, 16}), nil
+		}
+
+		svc := wtfhttp.NewDialService(wtfhttp.NewClient(s.URL()))
+		if forecast, err := svc.DialValueForecast(ctx0, 5, start, start.Add(4*time.Hour), interval, "", 2); err != nil {
+			t.Fatal(err)
+		} else if got, want := forecast, (&wtf.DialValueForecast{
+			Model: wtf.ForecastModelLinear,
+			Records: []*wtf.DialValueForecastRecord{
+				{Value: 18, Lower: 18, Upper: 18, Timestamp: start.Add(4 * time.Hour)},
+				{Value: 20, Lower: 20, Upper: 20, Timestamp: start.Add(5 * time.Hour)},
+			},
+		}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("forecast=%#v, want %#v", got, want)
+		}
+	})
+
+	// Ensure Holt-Winters is chosen when there are two days of history and
+	// that it repeats a steady daily cycle.
+	t.Run("HoltWinters", func(t *testing.T) {
+		var values []int
+		for day := 0; day < 2; day++ {
+			for hour := 0; hour < 24; hour++ {
+				values = append(values, 40+hour)
+			}
+		}
+		s.DialService.DialValueReportFn = func(ctx context.Context, id int, a, b time.Time, interval wtf.ReportInterval) (*wtf.DialValueReport, error) {
+			return newReport(values), nil
+		}
+
+		svc := wtfhttp.NewDialService(wtfhttp.NewClient(s.URL()))
+		if forecast, err := svc.DialValueForecast(ctx0, 5, time.Time{}, time.Time{}, interval, "", 3); err != nil {
+			t.Fatal(err)
+		} else if got, want := forecast.Model, wtf.ForecastModelHoltWinters; got != want {
+			t.Fatalf("Model=%v, want %v", got, want)
+		} else if got, want := forecast.Records, []*wtf.DialValueForecastRecord{
+			{Value: 40, Lower: 40, Upper: 40, Timestamp: start.Add(48 * time.Hour)},
+			{Value: 41, Lower: 41, Upper: 41, Timestamp: start.Add(49 * time.Hour)},
+			{Value: 42, Lower: 42, Upper: 42, Timestamp: start.Add(50 * time.Hour)},
+		}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("records=%#v, want %#v", got, want)
+		}
+	})
+
+	// Ensure bounds widen around noisy history & stay within dial limits.
+	t.Run("Bounds", func(t *testing.T) {
+		s.DialService.DialValueReportFn = func(ctx context.Context, id int, a, b time.Time, interval wtf.ReportInterval) (*wtf.DialValueReport, error) {
+			return newReport([]int{80, 100, 85, 100, 90, 100}), nil
+		}
+
+		svc := wtfhttp.NewDialService(wtfhttp.NewClient(s.URL()))
+		forecast, err := svc.DialValueForecast(ctx0, 5, time.Time{}, time.Time{}, interval, wtf.ForecastModelLinear, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, record := range forecast.Records {
+			if record.Lower >= record.Value || record.Upper > 100 {
+				t.Fatalf("unexpected bounds: %#v", record)
+			}
+		}
+		if forecast.Records[1].Value-forecast.Records[1].Lower <= forecast.Records[0].Value-forecast.Records[0].Lower {
+			t.Fatal("expected bounds to widen")
+		}
+	})
+
+	// Ensure Holt-Winters is rejected without enough history.
+	t.Run("ErrHoltWintersHistory", func(t *testing.T) {
+		s.DialService.DialValueReportFn = func(ctx context.Context, id int, a, b time.Time, interval wtf.ReportInterval) (*wtf.DialValueReport, error) {
+			return newReport([]int{10, 20, 30}), nil
+		}
+
+		svc := wtfhttp.NewDialService(wtfhttp.NewClient(s.URL()))
+		if _, err := svc.DialValueForecast(ctx0, 5, time.Time{}, time.Time{}, interval, wtf.ForecastModelHoltWinters, 2); wtf.ErrorCode(err) != wtf.EINVALID {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure unknown models & sizes are rejected.
+	t.Run("ErrInvalid", func(t *testing.T) {
+		for _, path := range []string{"/dials/5/forecast?model=magic", "/dials/5/forecast?n=0", "/dials/5/forecast?n=abc"} {
+			resp, err := http.DefaultClient.Do(s.MustNewRequest(t, ctx0, "GET", path, nil))
+			if err != nil {
+				t.Fatal(err)
+			} else if got, want := resp.StatusCode, http.StatusBadRequest; got != want {
+				t.Fatalf("%s: StatusCode=%v, want %v", path, got, want)
+			}
+			resp.Body.Close()
+		}
+	})
+}
+
+// Ensure value reports can be rendered as SVG charts.
+func TestReportChart(t *testing.T) {
+	// Start the mocked HTTP test server.
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	user0 := &wtf.User{ID: 1, Name: "USER1", APIKey: "APIKEY"}
+	ctx0 := wtf.NewContextWithUser(context.Background(), user0)
+	s.UserService.FindUserByIDFn = func(ctx context.Context, id int) (*wtf.User, error) {
+		return user0, nil
+	}
+
+	start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	records := []*wtf.DialValueRecord{
+		{Value: 10, Timestamp: start},
+		{Value: 80, Timestamp: start.Add(30 * time.Minute)},
+	}
+	s.DialService.FindDialByIDFn = func(ctx context.Context, id int) (*wtf.Dial, error) {
+		return &wtf.Dial{ID: id, UserID: 1, Name: "R&D"}, nil
+	}
+	s.DialService.DialValueReportFn = func(ctx context.Context, id int, a, b time.Time, interval wtf.ReportInterval) (*wtf.DialValueReport, error) {
+		if id != 5 || !a.Equal(start) || interval.Duration != 30*time.Minute {
+			t.Fatalf("unexpected report: id=%d start=%s interval=%s", id, a, interval)
+		}
+		return &wtf.DialValueReport{Records: records}, nil
+	}
+	s.DialService.AverageDialValueReportFn = func(ctx context.Context, a, b time.Time, interval wtf.ReportInterval) (*wtf.DialValueReport, error) {
+		return &wtf.DialValueReport{}, nil
+	}
+
+	// get fetches a chart & returns the response with its body.
+	get := func(t *testing.T, path string) (*http.Response, string) {
+		resp, err := http.DefaultClient.Do(s.MustNewRequest(t, ctx0, "GET", path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		buf, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp, string(buf)
+	}
+
+	// Ensure a dial's values are plotted with its escaped name as a title.
+	t.Run("Dial", func(t *testing.T) {
+		resp, body := get(t, "/dials/5/chart.svg?start=2000-01-01T00:00:00Z&end=2000-01-01T01:00:00Z&interval=30m&width=400&height=200")
+		if got, want := resp.StatusCode, http.StatusOK; got != want {
+			t.Fatalf("StatusCode=%v, want %v: %s", got, want, body)
+		} else if got, want := resp.Header.Get("Content-type"), "image/svg+xml"; got != want {
+			t.Fatalf("Content-type=%v, want %v", got, want)
+		} else if !strings.Contains(body, `width="400" height="200"`) {
+			t.Fatalf("expected size: %s", body)
+		} else if !strings.Contains(body, `<title>R&amp;D</title>`) {
+			t.Fatalf("expected title: %s", body)
+		} else if !strings.Contains(body, `<polyline points="`) {
+			t.Fatalf("expected value line: %s", body)
+		}
+	})
+
+	// Ensure an empty report still renders a chart.
+	t.Run("Average", func(t *testing.T) {
+		resp, body := get(t, "/report/chart.svg")
+		if got, want := resp.StatusCode, http.StatusOK; got != want {
+			t.Fatalf("StatusCode=%v, want %v: %s", got, want, body)
+		} else if !strings.Contains(body, "No data") {
+			t.Fatalf("expected empty chart: %s", body)
+		}
+	})
+
+	// Ensure chart sizes are limited.
+	t.Run("ErrSize", func(t *testing.T) {
+		if resp, _ := get(t, "/report/chart.svg?width=5000"); resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("StatusCode=%v, want %v", resp.StatusCode, http.StatusBadRequest)
+		}
+	})
+}
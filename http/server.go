@@ -65,11 +65,14 @@ type Server struct {
 	GitHubClientSecret string
 
 	// Servics used by the various HTTP routes.
-	AuthService           wtf.AuthService
-	DialService           wtf.DialService
-	DialMembershipService wtf.DialMembershipService
-	EventService          wtf.EventService
-	UserService           wtf.UserService
+	AuthService                wtf.AuthService
+	DeviceAuthorizationService wtf.DeviceAuthorizationService
+	DialAnomalyService         wtf.DialAnomalyService
+	DialService                wtf.DialService
+	DialMembershipService      wtf.DialMembershipService
+	EventService               wtf.EventService
+	IdempotencyKeyService      wtf.IdempotencyKeyService
+	UserService                wtf.UserService
 }
 
 // NewServer returns a new instance of Server.
@@ -108,6 +111,15 @@ func NewServer() *Server {
 	// Handle authentication check within handler function for home page.
 	router.HandleFunc("/", s.handleIndex).Methods("GET")
 
+	// Register device login API routes. These are used by devices which do
+	// not have a session so they do not require authentication.
+	s.registerDeviceTokenRoutes(router)
+
+	// Register public share & badge routes. These are viewed by people
+	// outside the dial so they do not require authentication.
+	s.registerShareRoutes(router)
+	s.registerBadgeRoutes(router)
+
 	// Register unauthenticated routes.
 	{
 		r := s.router.PathPrefix("/").Subrouter()
@@ -120,9 +132,15 @@ func NewServer() *Server {
 		r := router.PathPrefix("/").Subrouter()
 		r.Use(s.requireAuth)
 		r.HandleFunc("/settings", s.handleSettings).Methods("GET")
+		r.HandleFunc("/settings", s.handleSettingsUpdate).Methods("POST")
+		s.registerDeviceAuthorizationRoutes(r)
 		s.registerDialRoutes(r)
+		s.registerDialAnomalyRoutes(r)
 		s.registerDialMembershipRoutes(r)
 		s.registerEventRoutes(r)
+		s.registerReportRoutes(r)
+		s.registerShareOwnerRoutes(r)
+		s.registerBadgeOwnerRoutes(r)
 	}
 
 	return s
@@ -282,13 +300,24 @@ func (s *Server) authenticate(next http.Handler) http.Handler {
 			if err != nil {
 				Error(w, r, err)
 				return
-			} else if len(users) == 0 {
+			}
+
+			// Fall back to tokens issued to devices by "wtf login".
+			var user *wtf.User
+			if len(users) > 0 {
+				user = users[0]
+			} else if device, err := s.DeviceAuthorizationService.FindDeviceByToken(r.Context(), apiKey); wtf.ErrorCode(err) == wtf.ENOTFOUND {
 				Error(w, r, wtf.Errorf(wtf.EUNAUTHORIZED, "Invalid API key."))
 				return
+			} else if err != nil {
+				Error(w, r, err)
+				return
+			} else {
+				user = device.User
 			}
 
 			// Update request context to include authenticated user.
-			r = r.WithContext(wtf.NewContextWithUser(r.Context(), users[0]))
+			r = r.WithContext(wtf.NewContextWithUser(r.Context(), user))
 
 			// Delegate to next HTTP handler.
 			next.ServeHTTP(w, r)
@@ -456,10 +485,32 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Fetch historical average WTF values.
-	interval := time.Minute
-	end := time.Now().Truncate(interval).Add(interval)
-	start := end.Add(-1 * time.Hour)
+	// Fetch historical average WTF values for the selected period. Calendar
+	// periods are aligned to the user's time zone. The current slot is
+	// included so the latest value is shown.
+	tmpl.Location = reportLocation(r)
+	interval := wtf.ReportInterval{Duration: time.Minute, Location: tmpl.Location}
+	switch tmpl.Period = r.URL.Query().Get("period"); tmpl.Period {
+	case html.DashboardPeriodWeek, html.DashboardPeriodMonth:
+		interval = wtf.ReportInterval{Unit: wtf.ReportUnitDay, Location: tmpl.Location}
+	case html.DashboardPeriodYear:
+		interval = wtf.ReportInterval{Unit: wtf.ReportUnitMonth, Location: tmpl.Location}
+	default:
+		tmpl.Period = html.DashboardPeriodHour
+	}
+
+	end := interval.Next(interval.Truncate(time.Now())).In(tmpl.Location)
+	var start time.Time
+	switch tmpl.Period {
+	case html.DashboardPeriodWeek:
+		start = end.AddDate(0, 0, -7)
+	case html.DashboardPeriodMonth:
+		start = end.AddDate(0, 0, -30)
+	case html.DashboardPeriodYear:
+		start = end.AddDate(-1, 0, 0)
+	default:
+		start = end.Add(-1 * time.Hour)
+	}
 	if tmpl.AverageDialValueReport, err = s.DialService.AverageDialValueReport(r.Context(), start, end, interval); err != nil {
 		Error(w, r, err)
 		return
@@ -471,10 +522,39 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 
 // handleSettings handles the "GET /settings" route.
 func (s *Server) handleSettings(w http.ResponseWriter, r *http.Request) {
-	var tmpl html.SettingsTemplate
+	tmpl := html.SettingsTemplate{TimeZone: wtf.UserFromContext(r.Context()).TimeZone}
+
+	var err error
+	if tmpl.Devices, _, err = s.DeviceAuthorizationService.FindDevices(r.Context(), wtf.DeviceFilter{}); err != nil {
+		Error(w, r, err)
+		return
+	}
 	tmpl.Render(r.Context(), w)
 }
 
+// handleSettingsUpdate handles the "POST /settings" route. It updates the
+// current user's time zone and redirects back to the settings page.
+func (s *Server) handleSettingsUpdate(w http.ResponseWriter, r *http.Request) {
+	timeZone := strings.TrimSpace(r.PostFormValue("time_zone"))
+	if _, err := s.UserService.UpdateUser(r.Context(), wtf.UserIDFromContext(r.Context()), wtf.UserUpdate{
+		TimeZone: &timeZone,
+	}); wtf.ErrorCode(err) == wtf.EINTERNAL {
+		Error(w, r, err)
+		return
+	} else if err != nil {
+		tmpl := html.SettingsTemplate{TimeZone: timeZone, Err: err}
+		if tmpl.Devices, _, err = s.DeviceAuthorizationService.FindDevices(r.Context(), wtf.DeviceFilter{}); err != nil {
+			Error(w, r, err)
+			return
+		}
+		tmpl.Render(r.Context(), w)
+		return
+	}
+
+	SetFlash(w, "Settings successfully updated.")
+	http.Redirect(w, r, "/settings", http.StatusFound)
+}
+
 // handleVersion displays the deployed version.
 func (s *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/plain")
@@ -25,11 +25,14 @@ type Server struct {
 	*wtfhttp.Server
 
 	// Mock services.
-	AuthService           mock.AuthService
-	DialService           mock.DialService
-	DialMembershipService mock.DialMembershipService
-	EventService          mock.EventService
-	UserService           mock.UserService
+	AuthService                mock.AuthService
+	DeviceAuthorizationService mock.DeviceAuthorizationService
+	DialAnomalyService         mock.DialAnomalyService
+	DialService                mock.DialService
+	DialMembershipService      mock.DialMembershipService
+	EventService               mock.EventService
+	IdempotencyKeyService      mock.IdempotencyKeyService
+	UserService                mock.UserService
 }
 
 // MustOpenServer is a test helper function for starting a new test HTTP server.
@@ -46,6 +49,8 @@ func MustOpenServer(tb testing.TB) *Server {
 
 	// Assign mocks to actual server's services.
 	s.Server.AuthService = &s.AuthService
+	s.Server.DeviceAuthorizationService = &s.DeviceAuthorizationService
+	s.Server.DialAnomalyService = &s.DialAnomalyService
 	s.Server.DialService = &s.DialService
 	s.Server.DialMembershipService = &s.DialMembershipService
 	s.Server.EventService = &s.EventService
@@ -0,0 +1,251 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/http/html"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+)
+
+// registerShareRoutes is a helper function for registering public share
+// routes. These routes do not require authentication.
+func (s *Server) registerShareRoutes(r *mux.Router) {
+	// Read-only view of a shared dial.
+	r.HandleFunc("/p/{token}", s.handleShareView).Methods("GET")
+
+	// Real-time updates restricted to the shared dial.
+	r.HandleFunc("/p/{token}/events", s.handleShareEvents)
+}
+
+// registerShareOwnerRoutes is a helper function for registering the routes
+// used by dial owners to manage public links.
+func (s *Server) registerShareOwnerRoutes(r *mux.Router) {
+	r.HandleFunc("/dials/{id}/share", s.handleDialShareUpdate).Methods("POST")
+}
+
+// publicDial represents the JSON output for "GET /p/:token". It only includes
+// fields which are safe to show to anyone with the link.
+type publicDial struct {
+	Name      string    `json:"name"`
+	Value     int       `json:"value"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// Only set if the owner chose to show members.
+	Members []*publicDialMember `json:"members,omitempty"`
+}
+
+// publicDialMember represents a member in the JSON output for "GET /p/:token".
+type publicDialMember struct {
+	Name  string `json:"name"`
+	Value int    `json:"value"`
+}
+
+// handleShareView handles the "GET /p/:token" route. It displays the dial's
+// aggregate value to anyone with the link. Member names are only included if
+// the owner has enabled them.
+//
+// The endpoint works with HTML & JSON formats.
+func (s *Server) handleShareView(w http.ResponseWriter, r *http.Request) {
+	dial, err := s.findSharedDial(r.Context(), mux.Vars(r)["token"])
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Shared pages should not show up in search results.
+	w.Header().Set("X-Robots-Tag", "noindex")
+
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		other := publicDial{Name: dial.Name, Value: dial.Value, UpdatedAt: dial.UpdatedAt}
+		for _, membership := range dial.Memberships {
+			other.Members = append(other.Members, &publicDialMember{Name: membership.User.Name, Value: membership.Value})
+		}
+
+		w.Header().Set("Content-type", "application/json")
+		if err := json.NewEncoder(w).Encode(other); err != nil {
+			LogError(r, err)
+			return
+		}
+
+	default:
+		tmpl := html.ShareViewTemplate{Dial: dial, Token: mux.Vars(r)["token"]}
+		tmpl.Render(r.Context(), w)
+	}
+}
+
+// handleShareEvents handles the "GET /p/:token/events" route. It streams value
+// changes of the shared dial over Websockets. Membership changes are only
+// streamed if the owner has enabled showing members. The stream is closed
+// once the link is disabled or rotated.
+func (s *Server) handleShareEvents(w http.ResponseWriter, r *http.Request) {
+	// Look up the dial before upgrading so invalid links return a 404.
+	token := mux.Vars(r)["token"]
+	dial, err := s.findSharedDial(r.Context(), token)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	websocketConnections.Inc()
+	defer websocketConnections.Dec()
+
+	// Upgrade HTTP connection to use websockets.
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		LogError(r, err)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	r = r.WithContext(ctx)
+	conn.SetCloseHandler(func(code int, text string) error {
+		cancel()
+		return nil
+	})
+	defer conn.Close()
+
+	// Ignore all incoming messages.
+	go ignoreWebSocketReaders(conn)
+
+	// Subscribe to the owner's events since the owner is always a member of
+	// the dial. Events are filtered below so only the shared dial is seen.
+	sub, err := s.EventService.Subscribe(shareContext(r.Context(), dial))
+	if err != nil {
+		LogError(r, err)
+		return
+	}
+	defer sub.Close()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return // disconnect when HTTP connection disconnects
+
+		case event, ok := <-sub.C():
+			// If subscription is closed then exit.
+			if !ok {
+				return
+			} else if !isSharedDialEvent(dial, event) {
+				continue
+			}
+
+			// Ensure the link is still valid before writing each event.
+			// This also refreshes the members in case they have changed.
+			if dial, err = s.findSharedDial(r.Context(), token); wtf.ErrorCode(err) == wtf.ENOTFOUND {
+				return
+			} else if err != nil {
+				LogError(r, err)
+				return
+			} else if !isSharedDialEvent(dial, event) {
+				continue
+			}
+
+			// Marshal event data to JSON.
+			buf, err := json.Marshal(event)
+			if err != nil {
+				LogError(r, err)
+				return
+			}
+
+			// Write JSON data out to the websocket connection.
+			if err := conn.WriteMessage(websocket.TextMessage, buf); err != nil {
+				LogError(r, err)
+				return
+			}
+		}
+	}
+}
+
+// isSharedDialEvent returns true if event can be sent to viewers of a shared
+// dial. Only the dial's value & optionally its members' values are public.
+func isSharedDialEvent(dial *wtf.Dial, event wtf.Event) bool {
+	switch payload := event.Payload.(type) {
+	case *wtf.DialValueChangedPayload:
+		return payload.ID == dial.ID
+	case *wtf.DialMembershipValueChangedPayload:
+		for _, membership := range dial.Memberships {
+			if membership.ID == payload.ID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// findSharedDial returns the dial with the given share token. Memberships are
+// attached only if the owner has enabled showing members. Returns ENOTFOUND
+// if no dial is shared with the token.
+func (s *Server) findSharedDial(ctx context.Context, token string) (*wtf.Dial, error) {
+	dials, _, err := s.DialService.FindDials(ctx, wtf.DialFilter{ShareToken: &token})
+	if err != nil {
+		return nil, err
+	} else if len(dials) == 0 || token == "" {
+		return nil, wtf.Errorf(wtf.ENOTFOUND, "Dial not found.")
+	}
+
+	dial := dials[0]
+	if dial.ShareMembers {
+		if dial.Memberships, _, err = s.DialMembershipService.FindDialMemberships(shareContext(ctx, dial), wtf.DialMembershipFilter{DialID: &dial.ID}); err != nil {
+			return nil, err
+		}
+	}
+	return dial, nil
+}
+
+// shareContext returns a context which reads as the owner of a shared dial.
+// Viewers of a public link are usually not logged in so the owner's access
+// is used and the results are restricted by the caller.
+func shareContext(ctx context.Context, dial *wtf.Dial) context.Context {
+	return wtf.NewContextWithUser(ctx, &wtf.User{ID: dial.UserID})
+}
+
+// handleDialShareUpdate handles the "POST /dials/:id/share" route. It allows
+// the dial owner to enable, disable or rotate the public link from the HTML
+// form on the dial view. API clients can set the same fields with
+// "PATCH /dials/:id".
+func (s *Server) handleDialShareUpdate(w http.ResponseWriter, r *http.Request) {
+	// Parse dial ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Convert the form action into an update. The member setting is always
+	// submitted with the form.
+	members := r.PostFormValue("members") == "on"
+	upd := wtf.DialUpdate{ShareMembers: &members}
+
+	var flash string
+	switch action := r.PostFormValue("action"); action {
+	case "enable":
+		shared := true
+		upd.Shared, flash = &shared, "Public link enabled."
+	case "disable":
+		shared := false
+		upd.Shared, flash = &shared, "Public link disabled."
+	case "rotate":
+		upd.RotateShareToken, flash = true, "Public link replaced. The previous link no longer works."
+	case "update":
+		flash = "Public link updated."
+	default:
+		Error(w, r, wtf.Errorf(wtf.EINVALID, "Invalid share action."))
+		return
+	}
+
+	if _, err := s.DialService.UpdateDial(r.Context(), id, upd); err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	SetFlash(w, flash)
+	http.Redirect(w, r, fmt.Sprintf("/dials/%d", id), http.StatusFound)
+}
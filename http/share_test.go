@@ -0,0 +1,97 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// Ensure a shared dial can be viewed without logging in.
+func TestShareView(t *testing.T) {
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	dial := &wtf.Dial{ID: 1, UserID: 1, Name: "DIAL", Value: 40, InviteCode: "INVITE"}
+	s.DialService.FindDialsFn = func(ctx context.Context, filter wtf.DialFilter) ([]*wtf.Dial, int, error) {
+		if filter.ShareToken == nil || *filter.ShareToken != "TOKEN" {
+			return nil, 0, nil
+		}
+		other := *dial
+		return []*wtf.Dial{&other}, 1, nil
+	}
+	s.DialMembershipService.FindDialMembershipsFn = func(ctx context.Context, filter wtf.DialMembershipFilter) ([]*wtf.DialMembership, int, error) {
+		if got, want := wtf.UserIDFromContext(ctx), 1; got != want {
+			t.Fatalf("UserID=%v, want %v", got, want)
+		}
+		return []*wtf.DialMembership{{ID: 1, DialID: 1, UserID: 2, User: &wtf.User{ID: 2, Name: "USER2", Email: "user2@gmail.com"}, Value: 40}}, 1, nil
+	}
+
+	// viewJSON fetches the public view & decodes it into a generic map.
+	viewJSON := func(t *testing.T, path string) (map[string]interface{}, *http.Response) {
+		req := s.MustNewRequest(t, context.Background(), "GET", path, nil)
+		req.Header.Set("Accept", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		var m map[string]interface{}
+		if resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return m, resp
+	}
+
+	// Ensure only the aggregate value is shown by default.
+	t.Run("OK", func(t *testing.T) {
+		m, resp := viewJSON(t, "/p/TOKEN")
+		if got, want := resp.StatusCode, http.StatusOK; got != want {
+			t.Fatalf("StatusCode=%v, want %v", got, want)
+		} else if got, want := resp.Header.Get("X-Robots-Tag"), "noindex"; got != want {
+			t.Fatalf("X-Robots-Tag=%q, want %q", got, want)
+		}
+		delete(m, "updatedAt")
+		if got, want := m, map[string]interface{}{"name": "DIAL", "value": float64(40)}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("body=%#v, want %#v", got, want)
+		}
+	})
+
+	// Ensure member names & values are shown once the owner enables them.
+	t.Run("Members", func(t *testing.T) {
+		dial.ShareMembers = true
+		defer func() { dial.ShareMembers = false }()
+
+		m, resp := viewJSON(t, "/p/TOKEN")
+		if got, want := resp.StatusCode, http.StatusOK; got != want {
+			t.Fatalf("StatusCode=%v, want %v", got, want)
+		} else if got, want := m["members"], []interface{}{map[string]interface{}{"name": "USER2", "value": float64(40)}}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("members=%#v, want %#v", got, want)
+		}
+	})
+
+	// Ensure the HTML view renders without a session.
+	t.Run("HTML", func(t *testing.T) {
+		resp, err := http.DefaultClient.Do(s.MustNewRequest(t, context.Background(), "GET", "/p/TOKEN", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if got, want := resp.StatusCode, http.StatusOK; got != want {
+			t.Fatalf("StatusCode=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure unknown or revoked tokens are not found.
+	t.Run("ErrNotFound", func(t *testing.T) {
+		if _, resp := viewJSON(t, "/p/BADTOKEN"); resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("StatusCode=%v, want %v", resp.StatusCode, http.StatusNotFound)
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package wtf
+
+import (
+	"context"
+	"time"
+)
+
+// Idempotency constants.
+const (
+	// IdempotencyKeyExpiry is the time that a response is stored for a key.
+	// Requests repeated after this time are executed again.
+	IdempotencyKeyExpiry = 24 * time.Hour
+
+	// MaxIdempotencyKeyLen is the maximum length of a client-provided key.
+	MaxIdempotencyKeyLen = 255
+)
+
+// IdempotencyKey represents a client-provided key used to safely retry
+// requests which create or change data. The first request with a key is
+// executed and its response is stored. Repeated requests with the same key
+// receive the stored response instead of being executed again.
+//
+// Keys are scoped to a user so different users may use the same key.
+type IdempotencyKey struct {
+	ID int `json:"id"`
+
+	// User who made the original request.
+	UserID int `json:"userID"`
+
+	// Key provided by the client.
+	Key string `json:"key"`
+
+	// Hash of the original request's method, path & body. Used to reject
+	// reuse of a key with a different request.
+	Fingerprint string `json:"fingerprint"`
+
+	// Stored response of the original request. The status code is zero
+	// while the original request is still being executed.
+	StatusCode  int    `json:"statusCode"`
+	ContentType string `json:"contentType"`
+	Location    string `json:"location"`
+	Body        []byte `json:"body"`
+
+	// Time after which the key can be reused.
+	ExpiresAt time.Time `json:"expiresAt"`
+
+	// Timestamps for creation & last update.
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Completed returns true if the response of the original request is stored.
+func (k *IdempotencyKey) Completed() bool {
+	return k.StatusCode != 0
+}
+
+// Expired returns true if the key has expired as of now.
+func (k *IdempotencyKey) Expired(now time.Time) bool {
+	return !now.Before(k.ExpiresAt)
+}
+
+// Validate returns an error if the key has invalid fields.
+func (k *IdempotencyKey) Validate() error {
+	if k.Key == "" {
+		return Errorf(EINVALID, "Idempotency key required.")
+	} else if len(k.Key) > MaxIdempotencyKeyLen {
+		return Errorf(EINVALID, "Idempotency key too long.")
+	} else if k.Fingerprint == "" {
+		return Errorf(EINVALID, "Idempotency key fingerprint required.")
+	}
+	return nil
+}
+
+// IdempotencyKeyService represents a service for storing request results by
+// idempotency key.
+type IdempotencyKeyService interface {
+	// Retrieves an unexpired key for the current user.
+	// Returns ENOTFOUND if the key does not exist or has expired.
+	FindIdempotencyKey(ctx context.Context, key string) (*IdempotencyKey, error)
+
+	// Reserves a key for the current user before the original request is
+	// executed. Returns ECONFLICT if an unexpired key already exists.
+	CreateIdempotencyKey(ctx context.Context, key *IdempotencyKey) error
+
+	// Stores the response of the original request for a reserved key.
+	// Returns ENOTFOUND if the key does not exist.
+	CompleteIdempotencyKey(ctx context.Context, id int, statusCode int, contentType, location string, body []byte) error
+
+	// Removes a reserved key so the request can be retried, such as after
+	// an internal error. Returns ENOTFOUND if the key does not exist.
+	DeleteIdempotencyKey(ctx context.Context, id int) error
+}
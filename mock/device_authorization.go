@@ -0,0 +1,47 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/benbjohnson/wtf"
+)
+
+var _ wtf.DeviceAuthorizationService = (*DeviceAuthorizationService)(nil)
+
+type DeviceAuthorizationService struct {
+	CreateDeviceAuthorizationFn         func(ctx context.Context, auth *wtf.DeviceAuthorization) error
+	FindDeviceAuthorizationByUserCodeFn func(ctx context.Context, userCode string) (*wtf.DeviceAuthorization, error)
+	ApproveDeviceAuthorizationFn        func(ctx context.Context, userCode string) error
+	ExchangeDeviceCodeFn                func(ctx context.Context, deviceCode string) (*wtf.DeviceAuthorization, error)
+	FindDevicesFn                       func(ctx context.Context, filter wtf.DeviceFilter) ([]*wtf.Device, int, error)
+	FindDeviceByTokenFn                 func(ctx context.Context, token string) (*wtf.Device, error)
+	DeleteDeviceFn                      func(ctx context.Context, id int) error
+}
+
+func (s *DeviceAuthorizationService) CreateDeviceAuthorization(ctx context.Context, auth *wtf.DeviceAuthorization) error {
+	return s.CreateDeviceAuthorizationFn(ctx, auth)
+}
+
+func (s *DeviceAuthorizationService) FindDeviceAuthorizationByUserCode(ctx context.Context, userCode string) (*wtf.DeviceAuthorization, error) {
+	return s.FindDeviceAuthorizationByUserCodeFn(ctx, userCode)
+}
+
+func (s *DeviceAuthorizationService) ApproveDeviceAuthorization(ctx context.Context, userCode string) error {
+	return s.ApproveDeviceAuthorizationFn(ctx, userCode)
+}
+
+func (s *DeviceAuthorizationService) ExchangeDeviceCode(ctx context.Context, deviceCode string) (*wtf.DeviceAuthorization, error) {
+	return s.ExchangeDeviceCodeFn(ctx, deviceCode)
+}
+
+func (s *DeviceAuthorizationService) FindDevices(ctx context.Context, filter wtf.DeviceFilter) ([]*wtf.Device, int, error) {
+	return s.FindDevicesFn(ctx, filter)
+}
+
+func (s *DeviceAuthorizationService) FindDeviceByToken(ctx context.Context, token string) (*wtf.Device, error) {
+	return s.FindDeviceByTokenFn(ctx, token)
+}
+
+func (s *DeviceAuthorizationService) DeleteDevice(ctx context.Context, id int) error {
+	return s.DeleteDeviceFn(ctx, id)
+}
@@ -17,7 +17,9 @@ type DialService struct {
 	UpdateDialFn             func(ctx context.Context, id int, upd wtf.DialUpdate) (*wtf.Dial, error)
 	DeleteDialFn             func(ctx context.Context, id int) error
 	SetDialMembershipValueFn func(ctx context.Context, dialID, value int) error
-	AverageDialValueReportFn func(ctx context.Context, start, end time.Time, interval time.Duration) (*wtf.DialValueReport, error)
+	AverageDialValueReportFn func(ctx context.Context, start, end time.Time, interval wtf.ReportInterval) (*wtf.DialValueReport, error)
+	DialValueReportFn        func(ctx context.Context, id int, start, end time.Time, interval wtf.ReportInterval) (*wtf.DialValueReport, error)
+	ImportDialsFn            func(ctx context.Context, rows []*wtf.DialImportRow, opt wtf.DialImportOptions) (*wtf.DialImportResult, error)
 }
 
 func (s *DialService) FindDialByID(ctx context.Context, id int) (*wtf.Dial, error) {
@@ -44,6 +46,14 @@ func (s *DialService) SetDialMembershipValue(ctx context.Context, dialID, value
 	return s.SetDialMembershipValueFn(ctx, dialID, value)
 }
 
-func (s *DialService) AverageDialValueReport(ctx context.Context, start, end time.Time, interval time.Duration) (*wtf.DialValueReport, error) {
+func (s *DialService) AverageDialValueReport(ctx context.Context, start, end time.Time, interval wtf.ReportInterval) (*wtf.DialValueReport, error) {
 	return s.AverageDialValueReportFn(ctx, start, end, interval)
 }
+
+func (s *DialService) DialValueReport(ctx context.Context, id int, start, end time.Time, interval wtf.ReportInterval) (*wtf.DialValueReport, error) {
+	return s.DialValueReportFn(ctx, id, start, end, interval)
+}
+
+func (s *DialService) ImportDials(ctx context.Context, rows []*wtf.DialImportRow, opt wtf.DialImportOptions) (*wtf.DialImportResult, error) {
+	return s.ImportDialsFn(ctx, rows, opt)
+}
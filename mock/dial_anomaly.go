@@ -0,0 +1,17 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/benbjohnson/wtf"
+)
+
+var _ wtf.DialAnomalyService = (*DialAnomalyService)(nil)
+
+type DialAnomalyService struct {
+	FindDialAnomaliesFn func(ctx context.Context, filter wtf.DialAnomalyFilter) ([]*wtf.DialAnomaly, int, error)
+}
+
+func (s *DialAnomalyService) FindDialAnomalies(ctx context.Context, filter wtf.DialAnomalyFilter) ([]*wtf.DialAnomaly, int, error) {
+	return s.FindDialAnomaliesFn(ctx, filter)
+}
@@ -0,0 +1,32 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/benbjohnson/wtf"
+)
+
+var _ wtf.IdempotencyKeyService = (*IdempotencyKeyService)(nil)
+
+type IdempotencyKeyService struct {
+	FindIdempotencyKeyFn     func(ctx context.Context, key string) (*wtf.IdempotencyKey, error)
+	CreateIdempotencyKeyFn   func(ctx context.Context, key *wtf.IdempotencyKey) error
+	CompleteIdempotencyKeyFn func(ctx context.Context, id int, statusCode int, contentType, location string, body []byte) error
+	DeleteIdempotencyKeyFn   func(ctx context.Context, id int) error
+}
+
+func (s *IdempotencyKeyService) FindIdempotencyKey(ctx context.Context, key string) (*wtf.IdempotencyKey, error) {
+	return s.FindIdempotencyKeyFn(ctx, key)
+}
+
+func (s *IdempotencyKeyService) CreateIdempotencyKey(ctx context.Context, key *wtf.IdempotencyKey) error {
+	return s.CreateIdempotencyKeyFn(ctx, key)
+}
+
+func (s *IdempotencyKeyService) CompleteIdempotencyKey(ctx context.Context, id int, statusCode int, contentType, location string, body []byte) error {
+	return s.CompleteIdempotencyKeyFn(ctx, id, statusCode, contentType, location, body)
+}
+
+func (s *IdempotencyKeyService) DeleteIdempotencyKey(ctx context.Context, id int) error {
+	return s.DeleteIdempotencyKeyFn(ctx, id)
+}
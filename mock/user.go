@@ -14,6 +14,7 @@ type UserService struct {
 	CreateUserFn   func(ctx context.Context, user *wtf.User) error
 	UpdateUserFn   func(ctx context.Context, id int, upd wtf.UserUpdate) (*wtf.User, error)
 	DeleteUserFn   func(ctx context.Context, id int) error
+	ResetAPIKeyFn  func(ctx context.Context, id int) (*wtf.User, error)
 }
 
 func (s *UserService) FindUserByID(ctx context.Context, id int) (*wtf.User, error) {
@@ -35,3 +36,7 @@ func (s *UserService) UpdateUser(ctx context.Context, id int, upd wtf.UserUpdate
 func (s *UserService) DeleteUser(ctx context.Context, id int) error {
 	return s.DeleteUserFn(ctx, id)
 }
+
+func (s *UserService) ResetAPIKey(ctx context.Context, id int) (*wtf.User, error) {
+	return s.ResetAPIKeyFn(ctx, id)
+}
@@ -0,0 +1,153 @@
+package wtf
+
+import (
+	"time"
+)
+
+// Calendar units for report intervals.
+const (
+	ReportUnitDay   = "day"
+	ReportUnitWeek  = "week"
+	ReportUnitMonth = "month"
+)
+
+// MinReportInterval is the smallest fixed interval allowed in a report.
+const MinReportInterval = time.Minute
+
+// ReportInterval represents the size of each slot in a dial value report.
+//
+// Fixed intervals are a duration and their slots are aligned to multiples of
+// the duration since the zero time, in UTC. Calendar intervals are aligned to
+// midnight of each day, Monday of each week, or the first of each month in
+// the interval's location. Their slots vary in length across daylight saving
+// time changes & months of different lengths.
+type ReportInterval struct {
+	// Size of each slot. Only used if Unit is blank.
+	Duration time.Duration
+
+	// Calendar unit of each slot: "day", "week" or "month".
+	Unit string
+
+	// Time zone used to align calendar slots. Defaults to UTC.
+	Location *time.Location
+}
+
+// ParseReportInterval parses a calendar unit or a Go duration into an interval
+// aligned to loc. Returns EINVALID if s is not a valid interval.
+func ParseReportInterval(s string, loc *time.Location) (ReportInterval, error) {
+	switch s {
+	case ReportUnitDay, ReportUnitWeek, ReportUnitMonth:
+		return ReportInterval{Unit: s, Location: loc}, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return ReportInterval{}, Errorf(EINVALID, "Invalid interval format.")
+	}
+	return ReportInterval{Duration: d, Location: loc}, nil
+}
+
+// String returns the calendar unit or the duration of the interval.
+func (i ReportInterval) String() string {
+	if i.Unit != "" {
+		return i.Unit
+	}
+	return i.Duration.String()
+}
+
+// Validate returns an error if the interval has an invalid unit or if a fixed
+// interval is smaller than MinReportInterval.
+func (i ReportInterval) Validate() error {
+	switch i.Unit {
+	case "":
+		if i.Duration < MinReportInterval {
+			return Errorf(EINVALID, "Interval must be at least one minute.")
+		}
+	case ReportUnitDay, ReportUnitWeek, ReportUnitMonth:
+	default:
+		return Errorf(EINVALID, "Invalid interval unit.")
+	}
+	return nil
+}
+
+// location returns the location used for calendar slots.
+func (i ReportInterval) location() *time.Location {
+	if i.Location == nil {
+		return time.UTC
+	}
+	return i.Location
+}
+
+// Truncate returns the start of the slot containing t.
+func (i ReportInterval) Truncate(t time.Time) time.Time {
+	if i.Unit == "" {
+		return t.Truncate(i.Duration).UTC()
+	}
+
+	y, m, d := t.In(i.location()).Date()
+	switch i.Unit {
+	case ReportUnitWeek:
+		d -= (int(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Weekday()) + 6) % 7
+	case ReportUnitMonth:
+		d = 1
+	}
+	return time.Date(y, m, d, 0, 0, 0, 0, i.location()).UTC()
+}
+
+// Next returns the start of the slot following the slot which starts at t.
+// Calendar slots are computed with the local date so they remain aligned to
+// midnight across daylight saving time changes.
+func (i ReportInterval) Next(t time.Time) time.Time {
+	if i.Unit == "" {
+		return t.Add(i.Duration).UTC()
+	}
+
+	y, m, d := t.In(i.location()).Date()
+	switch i.Unit {
+	case ReportUnitDay:
+		d++
+	case ReportUnitWeek:
+		d += 7
+	case ReportUnitMonth:
+		m++
+	}
+	return time.Date(y, m, d, 0, 0, 0, 0, i.location()).UTC()
+}
+
+// SlotN returns the number of whole slots between the truncated start & end
+// times. This is computed without generating each slot so that the size of
+// very large reports can be checked cheaply.
+func (i ReportInterval) SlotN(start, end time.Time) int {
+	start, end = i.Truncate(start), i.Truncate(end)
+	if !start.Before(end) {
+		return 0
+	} else if i.Unit == "" {
+		return int(end.Sub(start) / i.Duration)
+	}
+
+	// Compare local dates as UTC dates so that offset changes are ignored.
+	y0, m0, d0 := start.In(i.location()).Date()
+	y1, m1, d1 := end.In(i.location()).Date()
+	if i.Unit == ReportUnitMonth {
+		return (y1-y0)*12 + int(m1-m0)
+	}
+	days := int(time.Date(y1, m1, d1, 0, 0, 0, 0, time.UTC).Sub(time.Date(y0, m0, d0, 0, 0, 0, 0, time.UTC)) / (24 * time.Hour))
+	if i.Unit == ReportUnitWeek {
+		return days / 7
+	}
+	return days
+}
+
+// Slots returns the start time of each whole slot between the truncated start
+// & end times, followed by the end time of the last slot. Returns only the
+// truncated start if there are no slots.
+func (i ReportInterval) Slots(start, end time.Time) []time.Time {
+	start, end = i.Truncate(start), i.Truncate(end)
+
+	a := []time.Time{start}
+	for t := start; t.Before(end); {
+		t = i.Next(t)
+		a = append(a, t)
+	}
+	return a
+}
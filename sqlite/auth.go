@@ -185,9 +185,17 @@ func findAuths(ctx context.Context, tx *Tx, filter wtf.AuthFilter) (_ []*wtf.Aut
 		where, args = append(where, "source_id = ?"), append(args, *v)
 	}
 
+	// Auths are ordered by ID & paged from the filter's cursor, if any. The
+	// total count is computed before the cursor is applied.
+	q, err := newCursorQuery(nil, false, filter.After, filter.Before)
+	if err != nil {
+		return nil, 0, err
+	}
+	cursorWhere, cursorArgs := q.where()
+
 	// Execute the query with WHERE clause and LIMIT/OFFSET injected.
 	rows, err := tx.QueryContext(ctx, `
-		SELECT 
+		SELECT
 		    id,
 		    user_id,
 		    source,
@@ -197,13 +205,17 @@ func findAuths(ctx context.Context, tx *Tx, filter wtf.AuthFilter) (_ []*wtf.Aut
 		    expiry,
 		    created_at,
 		    updated_at,
-		    COUNT(*) OVER()
-		FROM auths
-		WHERE `+strings.Join(where, " AND ")+`
-		ORDER BY id ASC
+		    n
+		FROM (
+		    SELECT *, COUNT(*) OVER() AS n
+		    FROM auths
+		    WHERE `+strings.Join(where, " AND ")+`
+		)
+		WHERE `+cursorWhere+`
+		ORDER BY `+q.orderBy()+`
 		`+FormatLimitOffset(filter.Limit, filter.Offset)+`
 	`,
-		args...,
+		append(args, cursorArgs...)...,
 	)
 	if err != nil {
 		return nil, n, FormatError(err)
@@ -242,6 +254,13 @@ func findAuths(ctx context.Context, tx *Tx, filter wtf.AuthFilter) (_ []*wtf.Aut
 		return nil, 0, FormatError(err)
 	}
 
+	// Restore the sort order if rows were fetched backward from a cursor.
+	if q.before {
+		for i, j := 0, len(auths)-1; i < j; i, j = i+1, j-1 {
+			auths[i], auths[j] = auths[j], auths[i]
+		}
+	}
+
 	return auths, n, nil
 }
 
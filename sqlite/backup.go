@@ -0,0 +1,294 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/mattn/go-sqlite3"
+)
+
+// Snapshot file naming. Snapshot names sort lexically by creation time so
+// that the oldest snapshots can be found for retention.
+const (
+	SnapshotPrefix     = "wtfd-"
+	SnapshotExt        = ".db"
+	SnapshotTimeFormat = "20060102T150405Z"
+)
+
+// Backup writes a consistent snapshot of the database to filename using the
+// SQLite online backup API. The database can continue to be written to while
+// the backup is running.
+//
+// The snapshot is written to a temporary file and then renamed so a partial
+// backup is never left at filename.
+func (db *DB) Backup(ctx context.Context, filename string) error {
+	return backup(ctx, db.db, filename)
+}
+
+// Backup writes a consistent snapshot of the database at dsn to filename.
+// Unlike DB.Open(), this does not execute migrations so it is safe to run
+// against the database of a running server, even from a different version.
+func Backup(ctx context.Context, dsn, filename string) error {
+	if _, err := os.Stat(dsn); err != nil {
+		return err
+	}
+
+	src, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	return backup(ctx, src, filename)
+}
+
+// Snapshot writes a timestamped backup of the database into dir and returns
+// the path of the new snapshot file.
+func (db *DB) Snapshot(ctx context.Context, dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+
+	filename := SnapshotPath(dir, db.Now())
+	if err := db.Backup(ctx, filename); err != nil {
+		return "", err
+	}
+	return filename, nil
+}
+
+// SnapshotPath returns the path of a snapshot in dir taken at time t.
+func SnapshotPath(dir string, t time.Time) string {
+	return filepath.Join(dir, SnapshotPrefix+t.UTC().Format(SnapshotTimeFormat)+SnapshotExt)
+}
+
+// PruneSnapshots removes the oldest snapshots in dir so that at most retain
+// snapshots remain. Returns the paths of the removed files. Files which do
+// not match the snapshot naming scheme are ignored.
+func PruneSnapshots(dir string, retain int) ([]string, error) {
+	if retain <= 0 {
+		return nil, fmt.Errorf("snapshot retention must be greater than zero")
+	}
+
+	filenames, err := Snapshots(dir)
+	if err != nil || len(filenames) <= retain {
+		return nil, err
+	}
+
+	// Snapshots are sorted from oldest to newest so remove from the front.
+	removed := filenames[:len(filenames)-retain]
+	for _, filename := range removed {
+		if err := os.Remove(filename); err != nil {
+			return nil, err
+		}
+	}
+	return removed, nil
+}
+
+// Snapshots returns the paths of all snapshot files in dir, sorted from the
+// oldest to the newest. Returns no files if dir does not exist.
+func Snapshots(dir string) ([]string, error) {
+	fis, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var filenames []string
+	for _, fi := range fis {
+		name := fi.Name()
+		if fi.IsDir() || !strings.HasPrefix(name, SnapshotPrefix) || !strings.HasSuffix(name, SnapshotExt) {
+			continue
+		} else if _, err := time.Parse(SnapshotTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, SnapshotPrefix), SnapshotExt)); err != nil {
+			continue
+		}
+		filenames = append(filenames, filepath.Join(dir, name))
+	}
+	sort.Strings(filenames)
+	return filenames, nil
+}
+
+// Restore replaces the database at dsn with the backup at filename. The
+// server must not be running while the database is restored. Returns
+// ECONFLICT if another connection has the database open.
+//
+// The backup is validated before the existing database is touched. Returns
+// EINVALID if the backup is not a WTF database or if it has been migrated
+// by a newer version of the application. Older backups are accepted and
+// pending migrations run the next time the database is opened.
+func Restore(ctx context.Context, filename, dsn string) error {
+	if _, err := os.Stat(filename); err != nil {
+		return err
+	}
+
+	src, err := sql.Open("sqlite3", filename)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if err := validateBackup(ctx, src); err != nil {
+		return err
+	}
+
+	if err := checkDatabaseUnused(ctx, dsn); err != nil {
+		return err
+	}
+
+	// Copy into a temporary file next to the database so the final rename
+	// is atomic. The WAL files of the old database are removed before the
+	// rename so they are never replayed against the restored database.
+	if err := os.MkdirAll(filepath.Dir(dsn), 0700); err != nil {
+		return err
+	}
+	tmpname := dsn + ".tmp"
+	if err := backupTemp(ctx, src, tmpname); err != nil {
+		return err
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(dsn + suffix); err != nil && !os.IsNotExist(err) {
+			os.Remove(tmpname)
+			return err
+		}
+	}
+	if err := os.Rename(tmpname, dsn); err != nil {
+		os.Remove(tmpname)
+		return err
+	}
+	return nil
+}
+
+// checkDatabaseUnused returns ECONFLICT if the database at dsn is open by
+// another connection, such as a running server. This is checked by briefly
+// taking an exclusive lock which fails while any other connection is open,
+// even if it is idle. A missing database is not in use.
+func checkDatabaseUnused(ctx context.Context, dsn string) error {
+	if _, err := os.Stat(dsn); os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+dsn+"?_locking_mode=EXCLUSIVE&_txlock=exclusive&_busy_timeout=0")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		var e sqlite3.Error
+		if errors.As(err, &e) && e.Code == sqlite3.ErrBusy {
+			return wtf.Errorf(wtf.ECONFLICT, "Database is in use. Stop the server first.")
+		}
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return db.Close()
+}
+
+// validateBackup returns an error if the database is corrupt or if it contains
+// migrations that are unknown to this version of the application.
+func validateBackup(ctx context.Context, db *sql.DB) error {
+	var result string
+	if err := db.QueryRowContext(ctx, `PRAGMA integrity_check`).Scan(&result); err != nil {
+		return wtf.Errorf(wtf.EINVALID, "Backup is not a valid SQLite database.")
+	} else if result != "ok" {
+		return wtf.Errorf(wtf.EINVALID, "Backup failed integrity check: %s", result)
+	}
+
+	applied, err := findAppliedMigrations(ctx, db)
+	if err != nil {
+		return err
+	} else if len(applied) == 0 {
+		return wtf.Errorf(wtf.EINVALID, "Backup has no migrations applied.")
+	}
+
+	for name := range applied {
+		if _, err := fs.Stat(migrationFS, name); os.IsNotExist(err) {
+			return wtf.Errorf(wtf.EINVALID, "Backup contains unknown migration %q. It may be from a newer version.", name)
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backup copies the main database of src to filename using the SQLite online
+// backup API. The copy is written to a temporary file and renamed on success.
+func backup(ctx context.Context, src *sql.DB, filename string) error {
+	tmpname := filename + ".tmp"
+	if err := backupTemp(ctx, src, tmpname); err != nil {
+		return err
+	} else if err := os.Rename(tmpname, filename); err != nil {
+		os.Remove(tmpname)
+		return err
+	}
+	return nil
+}
+
+// backupTemp copies the main database of src to the temporary file tmpname.
+// Any existing file at tmpname is replaced & the file is removed on error.
+func backupTemp(ctx context.Context, src *sql.DB, tmpname string) (err error) {
+	if err := os.Remove(tmpname); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(tmpname)
+		}
+	}()
+
+	dst, err := sql.Open("sqlite3", tmpname)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+
+	dstConn, err := dst.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer dstConn.Close()
+
+	// Copy all pages in a single step. This holds a read transaction on the
+	// source for the duration of the copy which does not block writers in WAL mode.
+	if err := dstConn.Raw(func(dstDriverConn interface{}) error {
+		return srcConn.Raw(func(srcDriverConn interface{}) error {
+			b, err := dstDriverConn.(*sqlite3.SQLiteConn).Backup("main", srcDriverConn.(*sqlite3.SQLiteConn), "main")
+			if err != nil {
+				return fmt.Errorf("backup init: %w", err)
+			}
+			if _, err := b.Step(-1); err != nil {
+				b.Finish()
+				return fmt.Errorf("backup step: %w", err)
+			}
+			return b.Finish()
+		})
+	}); err != nil {
+		return err
+	}
+
+	// Close the destination before renaming so all data is flushed.
+	if err := dstConn.Close(); err != nil {
+		return err
+	}
+	return dst.Close()
+}
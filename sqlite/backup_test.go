@@ -0,0 +1,125 @@
+package sqlite_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/sqlite"
+)
+
+func TestBackup(t *testing.T) {
+	// Ensure a backup can be restored over another database.
+	t.Run("OK", func(t *testing.T) {
+		dir := t.TempDir()
+		db := sqlite.NewDB(filepath.Join(dir, "db"))
+		if err := db.Open(); err != nil {
+			t.Fatal(err)
+		}
+		defer MustCloseDB(t, db)
+
+		ctx := context.Background()
+		MustCreateUser(t, ctx, db, &wtf.User{Name: "jill", Email: "jill@gmail.com"})
+
+		// Write snapshot while the database is open.
+		filename := filepath.Join(dir, "backup")
+		if err := db.Backup(ctx, filename); err != nil {
+			t.Fatal(err)
+		}
+
+		// Restore into a new location & verify the user exists.
+		dsn := filepath.Join(dir, "restored", "db")
+		if err := sqlite.Restore(ctx, filename, dsn); err != nil {
+			t.Fatal(err)
+		}
+
+		other := sqlite.NewDB(dsn)
+		if err := other.Open(); err != nil {
+			t.Fatal(err)
+		}
+		defer MustCloseDB(t, other)
+
+		if user, err := sqlite.NewUserService(other).FindUserByID(ctx, 1); err != nil {
+			t.Fatal(err)
+		} else if got, want := user.Name, "jill"; got != want {
+			t.Fatalf("Name=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure a database cannot be restored while it is open.
+	t.Run("ErrDatabaseOpen", func(t *testing.T) {
+		dir := t.TempDir()
+		db := sqlite.NewDB(filepath.Join(dir, "db"))
+		if err := db.Open(); err != nil {
+			t.Fatal(err)
+		}
+		defer MustCloseDB(t, db)
+
+		ctx := context.Background()
+		filename := filepath.Join(dir, "backup")
+		if err := db.Backup(ctx, filename); err != nil {
+			t.Fatal(err)
+		}
+		MustCreateUser(t, ctx, db, &wtf.User{Name: "jill", Email: "jill@gmail.com"})
+
+		if err := sqlite.Restore(ctx, filename, db.DSN); wtf.ErrorCode(err) != wtf.ECONFLICT {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		// Ensure the open database is untouched.
+		if _, err := sqlite.NewUserService(db).FindUserByID(ctx, 1); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	// Ensure a database with no migrations cannot be restored.
+	t.Run("ErrNoMigrations", func(t *testing.T) {
+		dir := t.TempDir()
+		filename := filepath.Join(dir, "backup")
+		if err := os.WriteFile(filename, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := sqlite.Restore(context.Background(), filename, filepath.Join(dir, "db")); wtf.ErrorCode(err) != wtf.EINVALID {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestPruneSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	db := sqlite.NewDB(filepath.Join(dir, "db"))
+	if err := db.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer MustCloseDB(t, db)
+
+	// Write three snapshots a minute apart.
+	var filenames []string
+	for i := 0; i < 3; i++ {
+		now := time.Date(2000, time.January, 1, 0, i, 0, 0, time.UTC)
+		db.Now = func() time.Time { return now }
+		filename, err := db.Snapshot(context.Background(), filepath.Join(dir, "snapshots"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		filenames = append(filenames, filename)
+	}
+
+	// Ensure only the newest snapshot is retained.
+	if removed, err := sqlite.PruneSnapshots(filepath.Join(dir, "snapshots"), 1); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(removed, filenames[:2]) {
+		t.Fatalf("removed=%v, want %v", removed, filenames[:2])
+	}
+
+	if a, err := sqlite.Snapshots(filepath.Join(dir, "snapshots")); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(a, filenames[2:]) {
+		t.Fatalf("snapshots=%v", a)
+	}
+}
@@ -0,0 +1,116 @@
+package sqlite_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/sqlite"
+)
+
+// Benchmark sizes for read paths. Each dial has ten members, including its
+// owner, so the largest size holds 1k dials and 10k memberships.
+var benchmarkDialNs = []int{10, 100, 1000}
+
+// Number of members per dial & number of users shared between all dials.
+const (
+	benchmarkMembersPerDial = 10
+	benchmarkUserN          = 100
+)
+
+func BenchmarkDialService_FindDials(b *testing.B) {
+	for _, n := range benchmarkDialNs {
+		db, ctx := MustSeedBenchmarkDB(b, n)
+		s := sqlite.NewDialService(db)
+
+		b.Run(fmt.Sprintf("dials=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if dials, _, err := s.FindDials(ctx, wtf.DialFilter{}); err != nil {
+					b.Fatal(err)
+				} else if len(dials) != n {
+					b.Fatalf("len=%d, want %d", len(dials), n)
+				}
+			}
+		})
+		MustCloseDB(b, db)
+	}
+}
+
+func BenchmarkDialMembershipService_FindDialMemberships(b *testing.B) {
+	for _, n := range benchmarkDialNs {
+		db, ctx := MustSeedBenchmarkDB(b, n)
+		s := sqlite.NewDialMembershipService(db)
+
+		b.Run(fmt.Sprintf("memberships=%d", n*benchmarkMembersPerDial), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if memberships, _, err := s.FindDialMemberships(ctx, wtf.DialMembershipFilter{}); err != nil {
+					b.Fatal(err)
+				} else if len(memberships) != n*benchmarkMembersPerDial {
+					b.Fatalf("len=%d, want %d", len(memberships), n*benchmarkMembersPerDial)
+				}
+			}
+		})
+		MustCloseDB(b, db)
+	}
+}
+
+func BenchmarkDialService_AverageDialValueReport(b *testing.B) {
+	for _, n := range benchmarkDialNs {
+		db, ctx := MustSeedBenchmarkDB(b, n)
+		s := sqlite.NewDialService(db)
+		end := db.Now().Add(time.Hour)
+		start := end.Add(-24 * time.Hour)
+
+		b.Run(fmt.Sprintf("dials=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := s.AverageDialValueReport(ctx, start, end, wtf.ReportInterval{Duration: time.Hour}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		MustCloseDB(b, db)
+	}
+}
+
+// MustSeedBenchmarkDB returns a database containing dialN dials, each with
+// ten members drawn from a shared pool of users. The first user is a member
+// of every dial so its returned context can see all dials & memberships.
+// Fatal on error.
+func MustSeedBenchmarkDB(tb testing.TB, dialN int) (*sqlite.DB, context.Context) {
+	tb.Helper()
+
+	db := MustOpenDB(tb)
+	ctx := context.Background()
+
+	users := make([]*wtf.User, benchmarkUserN)
+	userCtxs := make([]context.Context, benchmarkUserN)
+	for i := range users {
+		users[i], userCtxs[i] = MustCreateUser(tb, ctx, db, &wtf.User{Name: fmt.Sprintf("USER%d", i)})
+	}
+
+	for i := 0; i < dialN; i++ {
+		owner := 1 + i%(benchmarkUserN-1)
+		dial := MustCreateDial(tb, userCtxs[owner], db, &wtf.Dial{Name: fmt.Sprintf("DIAL%d", i)})
+
+		// Owner is added as a member on creation so add the first user and
+		// then fill the remaining members from the rest of the pool.
+		for j := 1; j < benchmarkMembersPerDial; j++ {
+			member := 0
+			if j > 1 {
+				member = 1 + (owner-1+j-1)%(benchmarkUserN-1)
+			}
+			MustCreateDialMembership(tb, userCtxs[member], db, &wtf.DialMembership{
+				DialID: dial.ID,
+				UserID: users[member].ID,
+				Value:  (i * j) % 100,
+			})
+		}
+	}
+
+	return db, userCtxs[0]
+}
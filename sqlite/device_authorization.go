@@ -0,0 +1,408 @@
+package sqlite
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"io"
+	"strings"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// userCodeAlphabet is the set of characters used to generate user codes.
+// Vowels are excluded to avoid spelling words and similar looking characters
+// are excluded to avoid confusion when typing the code.
+const userCodeAlphabet = "BCDFGHJKLMNPQRSTVWXZ"
+
+// Ensure service implements interface.
+var _ wtf.DeviceAuthorizationService = (*DeviceAuthorizationService)(nil)
+
+// DeviceAuthorizationService represents a service for managing device logins.
+type DeviceAuthorizationService struct {
+	db *DB
+}
+
+// NewDeviceAuthorizationService returns a new instance of DeviceAuthorizationService.
+func NewDeviceAuthorizationService(db *DB) *DeviceAuthorizationService {
+	return &DeviceAuthorizationService{db: db}
+}
+
+// CreateDeviceAuthorization creates a new device authorization request and
+// assigns the generated codes & expiration time to auth.
+func (s *DeviceAuthorizationService) CreateDeviceAuthorization(ctx context.Context, auth *wtf.DeviceAuthorization) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Clear out expired requests so the table does not grow unbounded.
+	if err := deleteExpiredDeviceAuthorizations(ctx, tx); err != nil {
+		return err
+	}
+
+	if err := createDeviceAuthorization(ctx, tx, auth); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// FindDeviceAuthorizationByUserCode retrieves a pending request by its user code.
+// Returns ENOTFOUND if the code does not exist or has expired.
+func (s *DeviceAuthorizationService) FindDeviceAuthorizationByUserCode(ctx context.Context, userCode string) (*wtf.DeviceAuthorization, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	return findDeviceAuthorization(ctx, tx, "user_code", wtf.FormatUserCode(userCode))
+}
+
+// ApproveDeviceAuthorization approves a pending request for the current user.
+// Returns EUNAUTHORIZED if no user is logged in. Returns ENOTFOUND if the code
+// does not exist or has expired.
+func (s *DeviceAuthorizationService) ApproveDeviceAuthorization(ctx context.Context, userCode string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Ensure a user is logged in to approve the request.
+	userID := wtf.UserIDFromContext(ctx)
+	if userID == 0 {
+		return wtf.Errorf(wtf.EUNAUTHORIZED, "You must be logged in to approve a device.")
+	}
+
+	// Look up the request & ensure it hasn't already been approved.
+	auth, err := findDeviceAuthorization(ctx, tx, "user_code", wtf.FormatUserCode(userCode))
+	if err != nil {
+		return err
+	} else if auth.Approved() {
+		return wtf.Errorf(wtf.ECONFLICT, "Device has already been approved.")
+	}
+
+	// Assign the current user to the request.
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE device_authorizations
+		SET user_id = ?,
+		    updated_at = ?
+		WHERE id = ?
+	`,
+		userID,
+		(*NullTime)(&tx.now),
+		auth.ID,
+	); err != nil {
+		return FormatError(err)
+	}
+	return tx.Commit()
+}
+
+// ExchangeDeviceCode exchanges a device code for a new device token. If the
+// request is still pending then the authorization is returned without a user.
+// Approved requests are removed so they can only be exchanged once.
+func (s *DeviceAuthorizationService) ExchangeDeviceCode(ctx context.Context, deviceCode string) (*wtf.DeviceAuthorization, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Look up request by device code. Return as-is if still pending.
+	auth, err := findDeviceAuthorization(ctx, tx, "device_code", deviceCode)
+	if err != nil {
+		return nil, err
+	} else if !auth.Approved() {
+		return auth, nil
+	}
+
+	// Attach the approving user & issue a token for the device.
+	if auth.User, err = findUserByID(ctx, tx, auth.UserID); err != nil {
+		return nil, err
+	}
+	auth.Device = &wtf.Device{UserID: auth.UserID, User: auth.User, Name: auth.DeviceName}
+	if err := createDevice(ctx, tx, auth.Device); err != nil {
+		return nil, err
+	}
+
+	// Remove the request so the device code cannot be reused.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM device_authorizations WHERE id = ?`, auth.ID); err != nil {
+		return nil, FormatError(err)
+	}
+	return auth, tx.Commit()
+}
+
+// findDeviceAuthorization returns an unexpired request by a unique code column.
+// Returns ENOTFOUND if no request exists or if it has expired.
+func findDeviceAuthorization(ctx context.Context, tx *Tx, column, code string) (*wtf.DeviceAuthorization, error) {
+	var auth wtf.DeviceAuthorization
+	var userID sql.NullInt64
+	if err := tx.QueryRowContext(ctx, `
+		SELECT
+		    id,
+		    device_name,
+		    device_code,
+		    user_code,
+		    user_id,
+		    expires_at,
+		    created_at,
+		    updated_at
+		FROM device_authorizations
+		WHERE `+column+` = ?
+	`,
+		code,
+	).Scan(
+		&auth.ID,
+		&auth.DeviceName,
+		&auth.DeviceCode,
+		&auth.UserCode,
+		&userID,
+		(*NullTime)(&auth.ExpiresAt),
+		(*NullTime)(&auth.CreatedAt),
+		(*NullTime)(&auth.UpdatedAt),
+	); err == sql.ErrNoRows {
+		return nil, wtf.Errorf(wtf.ENOTFOUND, "Device code not found.")
+	} else if err != nil {
+		return nil, FormatError(err)
+	}
+	auth.UserID = int(userID.Int64)
+
+	// Treat expired requests as if they do not exist.
+	if auth.Expired(tx.now) {
+		return nil, wtf.Errorf(wtf.ENOTFOUND, "Device code has expired.")
+	}
+	return &auth, nil
+}
+
+// createDeviceAuthorization generates new codes and inserts a pending request.
+func createDeviceAuthorization(ctx context.Context, tx *Tx, auth *wtf.DeviceAuthorization) (err error) {
+	// Generate a random secret for the device.
+	deviceCode := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, deviceCode); err != nil {
+		return err
+	}
+	auth.DeviceCode = hex.EncodeToString(deviceCode)
+
+	// Generate a short code for the user to type in.
+	if auth.UserCode, err = generateUserCode(); err != nil {
+		return err
+	}
+
+	// Set expiration & timestamps based on the current time.
+	auth.DeviceName = strings.TrimSpace(auth.DeviceName)
+	auth.UserID, auth.User, auth.Device = 0, nil, nil
+	auth.ExpiresAt = tx.now.Add(wtf.DeviceAuthorizationExpiry)
+	auth.CreatedAt = tx.now
+	auth.UpdatedAt = auth.CreatedAt
+
+	// Insert row into database.
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO device_authorizations (
+			device_name,
+			device_code,
+			user_code,
+			expires_at,
+			created_at,
+			updated_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`,
+		auth.DeviceName,
+		auth.DeviceCode,
+		auth.UserCode,
+		(*NullTime)(&auth.ExpiresAt),
+		(*NullTime)(&auth.CreatedAt),
+		(*NullTime)(&auth.UpdatedAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	// Read back new ID into caller argument.
+	if auth.ID, err = lastInsertID(result); err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindDevices retrieves a list of the current user's devices.
+func (s *DeviceAuthorizationService) FindDevices(ctx context.Context, filter wtf.DeviceFilter) ([]*wtf.Device, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+	return findDevices(ctx, tx, filter)
+}
+
+// FindDeviceByToken retrieves a device by its token with its user attached.
+// Returns ENOTFOUND if no device has the token.
+func (s *DeviceAuthorizationService) FindDeviceByToken(ctx context.Context, token string) (*wtf.Device, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	device, err := findDevice(ctx, tx, "token_hash", hashDeviceToken(token))
+	if err != nil {
+		return nil, err
+	} else if device.User, err = findUserByID(ctx, tx, device.UserID); err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+// DeleteDevice revokes a device owned by the current user. Returns
+// EUNAUTHORIZED if the device belongs to another user.
+func (s *DeviceAuthorizationService) DeleteDevice(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if device, err := findDevice(ctx, tx, "id", id); err != nil {
+		return err
+	} else if device.UserID != wtf.UserIDFromContext(ctx) {
+		return wtf.Errorf(wtf.EUNAUTHORIZED, "You are not allowed to revoke this device.")
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM devices WHERE id = ?`, id); err != nil {
+		return FormatError(err)
+	}
+	return tx.Commit()
+}
+
+// findDevices returns the current user's devices. Also returns a count of
+// total matching devices which may differ if filter.Limit is set.
+func findDevices(ctx context.Context, tx *Tx, filter wtf.DeviceFilter) (_ []*wtf.Device, n int, err error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+		    id,
+		    user_id,
+		    name,
+		    created_at,
+		    COUNT(*) OVER()
+		FROM devices
+		WHERE user_id = ?
+		ORDER BY id ASC
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		wtf.UserIDFromContext(ctx),
+	)
+	if err != nil {
+		return nil, n, FormatError(err)
+	}
+	defer rows.Close()
+
+	devices := make([]*wtf.Device, 0)
+	for rows.Next() {
+		var device wtf.Device
+		if err := rows.Scan(
+			&device.ID,
+			&device.UserID,
+			&device.Name,
+			(*NullTime)(&device.CreatedAt),
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+		devices = append(devices, &device)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return devices, n, nil
+}
+
+// findDevice returns a device by a unique column. Returns ENOTFOUND if no
+// device exists.
+func findDevice(ctx context.Context, tx *Tx, column string, value interface{}) (*wtf.Device, error) {
+	var device wtf.Device
+	if err := tx.QueryRowContext(ctx, `
+		SELECT
+		    id,
+		    user_id,
+		    name,
+		    created_at
+		FROM devices
+		WHERE `+column+` = ?
+	`,
+		value,
+	).Scan(
+		&device.ID,
+		&device.UserID,
+		&device.Name,
+		(*NullTime)(&device.CreatedAt),
+	); err == sql.ErrNoRows {
+		return nil, wtf.Errorf(wtf.ENOTFOUND, "Device not found.")
+	} else if err != nil {
+		return nil, FormatError(err)
+	}
+	return &device, nil
+}
+
+// createDevice generates a new token for device and inserts it. Only the
+// hash of the token is stored.
+func createDevice(ctx context.Context, tx *Tx, device *wtf.Device) (err error) {
+	token := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, token); err != nil {
+		return err
+	}
+	device.Token = hex.EncodeToString(token)
+	device.CreatedAt = tx.now
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO devices (
+			user_id,
+			name,
+			token_hash,
+			created_at
+		)
+		VALUES (?, ?, ?, ?)
+	`,
+		device.UserID,
+		device.Name,
+		hashDeviceToken(device.Token),
+		(*NullTime)(&device.CreatedAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	if device.ID, err = lastInsertID(result); err != nil {
+		return err
+	}
+	return nil
+}
+
+// hashDeviceToken returns the hex-encoded SHA-256 hash of a device token.
+func hashDeviceToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// deleteExpiredDeviceAuthorizations removes all requests that have expired.
+func deleteExpiredDeviceAuthorizations(ctx context.Context, tx *Tx) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM device_authorizations WHERE expires_at <= ?`, (*NullTime)(&tx.now)); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
+
+// generateUserCode returns a random 8 character code in "XXXX-XXXX" format.
+func generateUserCode() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := io.ReadFull(rand.Reader, buf); err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for _, b := range buf {
+		sb.WriteByte(userCodeAlphabet[int(b)%len(userCodeAlphabet)])
+	}
+	return wtf.FormatUserCode(sb.String()), nil
+}
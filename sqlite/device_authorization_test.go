@@ -0,0 +1,166 @@
+package sqlite_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/sqlite"
+)
+
+func TestDeviceAuthorizationService(t *testing.T) {
+	// Ensure a device can obtain its own token after approval.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDeviceAuthorizationService(db)
+
+		ctx := context.Background()
+		user0, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+
+		// Create a new request without a logged in user.
+		auth := &wtf.DeviceAuthorization{DeviceName: "LAPTOP"}
+		if err := s.CreateDeviceAuthorization(ctx, auth); err != nil {
+			t.Fatal(err)
+		} else if auth.DeviceCode == "" {
+			t.Fatal("expected device code")
+		} else if got, want := len(auth.UserCode), 9; got != want {
+			t.Fatalf("len(UserCode)=%v, want %v", got, want)
+		}
+
+		// Ensure the device receives no user while the request is pending.
+		if other, err := s.ExchangeDeviceCode(ctx, auth.DeviceCode); err != nil {
+			t.Fatal(err)
+		} else if other.Approved() {
+			t.Fatal("expected pending authorization")
+		}
+
+		// Approve using a lowercase code without separators.
+		code := strings.ToLower(auth.UserCode[:4] + auth.UserCode[5:])
+		if err := s.ApproveDeviceAuthorization(ctx0, code); err != nil {
+			t.Fatal(err)
+		}
+
+		// Exchange code for a new device token.
+		other, err := s.ExchangeDeviceCode(ctx, auth.DeviceCode)
+		if err != nil {
+			t.Fatal(err)
+		} else if !other.Approved() {
+			t.Fatal("expected approved authorization")
+		} else if other.Device == nil || other.Device.Token == "" {
+			t.Fatalf("expected device token: %#v", other.Device)
+		} else if other.Device.Token == user0.APIKey {
+			t.Fatal("expected device token to differ from API key")
+		}
+
+		// Ensure the token identifies the user.
+		if device, err := s.FindDeviceByToken(ctx, other.Device.Token); err != nil {
+			t.Fatal(err)
+		} else if got, want := device.Name, "LAPTOP"; got != want {
+			t.Fatalf("Name=%v, want %v", got, want)
+		} else if got, want := device.User.ID, user0.ID; got != want {
+			t.Fatalf("UserID=%v, want %v", got, want)
+		} else if device.Token != "" {
+			t.Fatal("expected token to not be returned")
+		}
+
+		// Ensure the device code cannot be exchanged again.
+		if _, err := s.ExchangeDeviceCode(ctx, auth.DeviceCode); wtf.ErrorCode(err) != wtf.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure a device can be listed & revoked by its owner only.
+	t.Run("DeleteDevice", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDeviceAuthorizationService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "john"})
+		device := MustLoginDevice(t, ctx0, db, "LAPTOP")
+		MustLoginDevice(t, ctx1, db, "DESKTOP")
+
+		// Ensure each user only sees their own devices.
+		if devices, n, err := s.FindDevices(ctx0, wtf.DeviceFilter{}); err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 1; got != want {
+			t.Fatalf("n=%v, want %v", got, want)
+		} else if got, want := devices[0].Name, "LAPTOP"; got != want {
+			t.Fatalf("Name=%v, want %v", got, want)
+		}
+
+		// Ensure another user cannot revoke the device.
+		if err := s.DeleteDevice(ctx1, device.ID); wtf.ErrorCode(err) != wtf.EUNAUTHORIZED {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		// Revoke the device & ensure its token no longer works.
+		if err := s.DeleteDevice(ctx0, device.ID); err != nil {
+			t.Fatal(err)
+		} else if _, err := s.FindDeviceByToken(ctx, device.Token); wtf.ErrorCode(err) != wtf.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if _, n, err := s.FindDevices(ctx0, wtf.DeviceFilter{}); err != nil {
+			t.Fatal(err)
+		} else if n != 0 {
+			t.Fatalf("n=%v, want 0", n)
+		}
+	})
+
+	// Ensure a user must be logged in to approve a device.
+	t.Run("ErrUnauthorized", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDeviceAuthorizationService(db)
+
+		auth := &wtf.DeviceAuthorization{}
+		if err := s.CreateDeviceAuthorization(context.Background(), auth); err != nil {
+			t.Fatal(err)
+		} else if err := s.ApproveDeviceAuthorization(context.Background(), auth.UserCode); wtf.ErrorCode(err) != wtf.EUNAUTHORIZED {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure requests cannot be approved after they expire.
+	t.Run("ErrExpired", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDeviceAuthorizationService(db)
+
+		db.Now = func() time.Time { return time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC) }
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane"})
+
+		auth := &wtf.DeviceAuthorization{}
+		if err := s.CreateDeviceAuthorization(context.Background(), auth); err != nil {
+			t.Fatal(err)
+		}
+
+		db.Now = func() time.Time { return time.Date(2000, time.January, 1, 0, 10, 0, 0, time.UTC) }
+		if err := s.ApproveDeviceAuthorization(ctx0, auth.UserCode); wtf.ErrorCode(err) != wtf.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+// MustLoginDevice completes the device flow for the user in ctx and returns
+// the issued device with its token. Fatal on error.
+func MustLoginDevice(tb testing.TB, ctx context.Context, db *sqlite.DB, name string) *wtf.Device {
+	tb.Helper()
+	s := sqlite.NewDeviceAuthorizationService(db)
+
+	auth := &wtf.DeviceAuthorization{DeviceName: name}
+	if err := s.CreateDeviceAuthorization(context.Background(), auth); err != nil {
+		tb.Fatal(err)
+	} else if err := s.ApproveDeviceAuthorization(ctx, auth.UserCode); err != nil {
+		tb.Fatal(err)
+	}
+
+	other, err := s.ExchangeDeviceCode(context.Background(), auth.DeviceCode)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return other.Device
+}
@@ -62,12 +62,9 @@ func (s *DialService) FindDials(ctx context.Context, filter wtf.DialFilter) ([]*
 		return dials, n, err
 	}
 
-	// Iterate over dials and attach associated owner user.
-	// This should be batched up if using a remote database server.
-	for _, dial := range dials {
-		if err := attachDialAssociations(ctx, tx, dial); err != nil {
-			return dials, n, err
-		}
+	// Attach associated owner users in bulk.
+	if err := attachDialsAssociations(ctx, tx, dials); err != nil {
+		return dials, n, err
 	}
 	return dials, n, nil
 }
@@ -206,21 +203,22 @@ func (s *DialService) DialValues(ctx context.Context, id int) ([]int, error) {
 
 // AverageDialValueReport returns a report of the average dial value across
 // all dials that the user is a member of. Average values are computed
-// between start & end time and are slotted into given intervals. The
-// minimum interval size is one minute.
-func (s *DialService) AverageDialValueReport(ctx context.Context, start, end time.Time, interval time.Duration) (*wtf.DialValueReport, error) {
+// between start & end time and are slotted into given intervals. Returns
+// EINVALID if the interval is invalid.
+func (s *DialService) AverageDialValueReport(ctx context.Context, start, end time.Time, interval wtf.ReportInterval) (*wtf.DialValueReport, error) {
+	if err := interval.Validate(); err != nil {
+		return nil, err
+	}
+
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback()
 
-	// Ensure start/end line up with the interval unit.
-	start = start.Truncate(interval).UTC()
-	end = end.Truncate(interval).UTC()
-
-	// Compute the number of slots between start & end.
-	slotN := int(end.Sub(start) / interval)
+	// Compute slot boundaries which line up with the interval unit.
+	slots := interval.Slots(start, end)
+	slotN := len(slots) - 1
 	report := &wtf.DialValueReport{
 		Records: make([]*wtf.DialValueRecord, slotN),
 	}
@@ -231,14 +229,14 @@ func (s *DialService) AverageDialValueReport(ctx context.Context, start, end tim
 		return nil, fmt.Errorf("find dials: %w", err)
 	}
 
-	// Iterate over each dial and compute value at each slot.
-	valuesSlice := make([][]int, len(dials))
+	// Compute the value of every dial at each slot.
+	ids := make([]int, len(dials))
 	for i, dial := range dials {
-		values, err := findDialValueSlotsBetween(ctx, tx, dial.ID, start, end, interval)
-		if err != nil {
-			return nil, fmt.Errorf("dial values between: id=%d err=%w", dial.ID, err)
-		}
-		valuesSlice[i] = values
+		ids[i] = dial.ID
+	}
+	valuesByID, stats, err := findDialsValueSlots(ctx, tx, ids, slots)
+	if err != nil {
+		return nil, fmt.Errorf("dial value slots: %w", err)
 	}
 
 	// Compute average for each slot.
@@ -246,19 +244,63 @@ func (s *DialService) AverageDialValueReport(ctx context.Context, start, end tim
 		var avg int
 		if len(dials) != 0 {
 			var sum int
-			for j := range dials {
-				sum += valuesSlice[j][i]
+			for _, dial := range dials {
+				sum += valuesByID[dial.ID][i]
 			}
-			avg = sum / len(valuesSlice)
+			avg = sum / len(dials)
 		}
 
 		// Append record for avg value at a given time.
 		report.Records[i] = &wtf.DialValueRecord{
-			Timestamp: start.Add(time.Duration(i) * interval),
+			Timestamp: slots[i],
 			Value:     avg,
 		}
+		stats[i].apply(report.Records[i])
+	}
+
+	return report, nil
+}
+
+// DialValueReport returns a report of the value of a single dial between
+// start & end time, slotted into the given intervals.
+//
+// Returns ENOTFOUND if dial does not exist or the user is not a member.
+// Returns EINVALID if the interval is invalid.
+func (s *DialService) DialValueReport(ctx context.Context, id int, start, end time.Time, interval wtf.ReportInterval) (*wtf.DialValueReport, error) {
+	if err := interval.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Ensure dial exists & user has permission to view it.
+	if _, err := findDialByID(ctx, tx, id); err != nil {
+		return nil, err
+	}
+
+	// Compute the dial value at each slot. Slot boundaries line up with the
+	// interval unit.
+	slots := interval.Slots(start, end)
+	valuesByID, stats, err := findDialsValueSlots(ctx, tx, []int{id}, slots)
+	if err != nil {
+		return nil, fmt.Errorf("dial value slots: id=%d err=%w", id, err)
 	}
+	values := valuesByID[id]
 
+	report := &wtf.DialValueReport{
+		Records: make([]*wtf.DialValueRecord, len(values)),
+	}
+	for i, value := range values {
+		report.Records[i] = &wtf.DialValueRecord{
+			Timestamp: slots[i],
+			Value:     value,
+		}
+		stats[i].apply(report.Records[i])
+	}
 	return report, nil
 }
 
@@ -292,6 +334,10 @@ func checkDialExists(ctx context.Context, tx *Tx, id int) error {
 // findDials retrieves a list of matching dials. Also returns a total matching
 // count which may different from the number of results if filter.Limit is set.
 func findDials(ctx context.Context, tx *Tx, filter wtf.DialFilter) (_ []*wtf.Dial, n int, err error) {
+	if err := filter.Validate(); err != nil {
+		return nil, 0, err
+	}
+
 	// Build WHERE clause. Each part of the WHERE clause is AND-ed together.
 	// Values are appended to an arg list to avoid SQL injection.
 	where, args := []string{"1 = 1"}, []interface{}{}
@@ -299,33 +345,120 @@ func findDials(ctx context.Context, tx *Tx, filter wtf.DialFilter) (_ []*wtf.Dia
 		where, args = append(where, "id = ?"), append(args, *v)
 	}
 
-	// Limit to dials user is a member of unless searching by invite code.
+	if v := filter.UserID; v != nil {
+		where, args = append(where, "user_id = ?"), append(args, *v)
+	}
+	if v := filter.Query; v != nil && *v != "" {
+		where, args = append(where, `name LIKE ? ESCAPE '\'`), append(args, "%"+escapeLike(*v)+"%")
+	}
+	if v := filter.MinValue; v != nil {
+		where, args = append(where, "value >= ?"), append(args, *v)
+	}
+	if v := filter.MaxValue; v != nil {
+		where, args = append(where, "value <= ?"), append(args, *v)
+	}
+	if v := filter.UpdatedSince; v != nil {
+		where, args = append(where, "updated_at >= ?"), append(args, (*NullTime)(v))
+	}
+
+	// Limit to dials user is a member of unless searching by invite code,
+	// public share token or badge token.
 	if v := filter.InviteCode; v != nil {
 		where, args = append(where, "invite_code = ?"), append(args, *v)
+	} else if v := filter.ShareToken; v != nil {
+		where, args = append(where, "share_token = ?"), append(args, *v)
+	} else if v := filter.BadgeToken; v != nil {
+		where, args = append(where, "badge_token = ?"), append(args, *v)
 	} else {
-		userID := wtf.UserIDFromContext(ctx)
-		where = append(where, `(
-			id IN (SELECT dial_id FROM dial_memberships dm WHERE dm.user_id = ?)
-		)`)
-		args = append(args, userID, userID)
+		where, args = appendDialAccessClause(ctx, where, args)
+	}
+
+	// Determine sorting & page from the filter's cursor, if any. Dials are
+	// ordered by ID if no sort option is specified.
+	var columns []string
+	switch filter.SortBy {
+	case wtf.DialSortByNameAsc, wtf.DialSortByNameDesc:
+		columns = []string{"name COLLATE NOCASE"}
+	case wtf.DialSortByValueAsc, wtf.DialSortByValueDesc:
+		columns = []string{"value"}
+	case wtf.DialSortByUpdatedAtAsc, wtf.DialSortByUpdatedAtDesc:
+		columns = []string{"updated_at"}
 	}
+	desc := strings.HasSuffix(filter.SortBy, "_desc")
+
+	q, err := newCursorQuery(columns, desc, filter.After, filter.Before)
+	if err != nil {
+		return nil, 0, err
+	}
+	return queryDials(ctx, tx, where, args, q, filter.Limit, filter.Offset)
+}
+
+// escapeLike escapes the wildcard characters of a LIKE pattern so that s is
+// matched literally. The pattern must use a backslash as the escape character.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// findDialsByIDs returns a lookup of dials by ID. Only dials the user is a
+// member of are returned. Dials are fetched in batches so that associations
+// for a list of objects can be attached with a fixed number of queries.
+func findDialsByIDs(ctx context.Context, tx *Tx, ids []int) (map[int]*wtf.Dial, error) {
+	m := make(map[int]*wtf.Dial, len(ids))
+	if err := batchIDs(ids, func(ids []int) error {
+		where, args := appendDialAccessClause(ctx, []string{"id IN " + formatInClause(len(ids))}, intArgs(ids))
+		dials, _, err := queryDials(ctx, tx, where, args, &cursorQuery{}, 0, 0)
+		for _, dial := range dials {
+			m[dial.ID] = dial
+		}
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// appendDialAccessClause restricts a dial query to dials the current user is
+// a member of. Administrative contexts can see all dials.
+func appendDialAccessClause(ctx context.Context, where []string, args []interface{}) ([]string, []interface{}) {
+	if wtf.IsAdminContext(ctx) {
+		return where, args
+	}
+	where = append(where, `(
+		id IN (SELECT dial_id FROM dial_memberships dm WHERE dm.user_id = ?)
+	)`)
+	return where, append(args, wtf.UserIDFromContext(ctx))
+}
+
+// queryDials executes a query for dials matching the WHERE clause segments.
+// Results are ordered & paged by q. The total count excludes the cursor so
+// that it is the same for every page.
+func queryDials(ctx context.Context, tx *Tx, where []string, args []interface{}, q *cursorQuery, limit, offset int) (_ []*wtf.Dial, n int, err error) {
+	cursorWhere, cursorArgs := q.where()
 
 	// Execue query with limiting WHERE clause and LIMIT/OFFSET injected.
 	rows, err := tx.QueryContext(ctx, `
-		SELECT 
+		SELECT
 		    id,
 		    user_id,
 		    name,
 		    value,
 		    invite_code,
+		    share_token,
+		    share_members,
+		    badge_token,
+		    version,
 		    created_at,
 		    updated_at,
-		    COUNT(*) OVER()
-		FROM dials
-		WHERE `+strings.Join(where, " AND ")+`
-		ORDER BY id ASC
-		`+FormatLimitOffset(filter.Limit, filter.Offset),
-		args...,
+		    n
+		FROM (
+		    SELECT *, COUNT(*) OVER() AS n
+		    FROM dials
+		    WHERE `+strings.Join(where, " AND ")+`
+		)
+		WHERE `+cursorWhere+`
+		ORDER BY `+q.orderBy()+`
+		`+FormatLimitOffset(limit, offset),
+		append(args, cursorArgs...)...,
 	)
 	if err != nil {
 		return nil, n, FormatError(err)
@@ -336,24 +469,42 @@ func findDials(ctx context.Context, tx *Tx, filter wtf.DialFilter) (_ []*wtf.Dia
 	dials := make([]*wtf.Dial, 0)
 	for rows.Next() {
 		var dial wtf.Dial
+		var shareToken, badgeToken sql.NullString
 		if rows.Scan(
 			&dial.ID,
 			&dial.UserID,
 			&dial.Name,
 			&dial.Value,
 			&dial.InviteCode,
+			&shareToken,
+			&dial.ShareMembers,
+			&badgeToken,
+			&dial.Version,
 			(*NullTime)(&dial.CreatedAt),
 			(*NullTime)(&dial.UpdatedAt),
 			&n,
 		); err != nil {
 			return nil, 0, err
 		}
+
+		// Only the owner may see the share & badge tokens so members cannot
+		// publish or rotate them.
+		if wtf.CanEditDial(ctx, &dial) || wtf.IsAdminContext(ctx) {
+			dial.ShareToken, dial.BadgeToken = shareToken.String, badgeToken.String
+		}
 		dials = append(dials, &dial)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, 0, err
 	}
 
+	// Restore the sort order if rows were fetched backward from a cursor.
+	if q.before {
+		for i, j := 0, len(dials)-1; i < j; i, j = i+1, j-1 {
+			dials[i], dials[j] = dials[j], dials[i]
+		}
+	}
+
 	return dials, n, nil
 }
 
@@ -369,6 +520,7 @@ func createDial(ctx context.Context, tx *Tx, dial *wtf.Dial) error {
 	// Set timestamps to current time.
 	dial.CreatedAt = tx.now
 	dial.UpdatedAt = dial.CreatedAt
+	dial.Version = 1
 
 	// Perform basic field validation.
 	if err := dial.Validate(); err != nil {
@@ -381,14 +533,16 @@ func createDial(ctx context.Context, tx *Tx, dial *wtf.Dial) error {
 			user_id,
 			name,
 			invite_code,
+			version,
 			created_at,
 			updated_at
 		)
-		VALUES (?, ?, ?, ?, ?)
+		VALUES (?, ?, ?, ?, ?, ?)
 	`,
 		dial.UserID,
 		dial.Name,
 		dial.InviteCode,
+		dial.Version,
 		(*NullTime)(&dial.CreatedAt),
 		(*NullTime)(&dial.UpdatedAt),
 	)
@@ -425,27 +579,58 @@ func updateDial(ctx context.Context, tx *Tx, id int, upd wtf.DialUpdate) (*wtf.D
 		return dial, err
 	} else if !wtf.CanEditDial(ctx, dial) {
 		return dial, wtf.Errorf(wtf.EUNAUTHORIZED, "You must be the owner can edit a dial.")
+	} else if upd.Version != nil && *upd.Version != dial.Version {
+		return dial, wtf.Errorf(wtf.ECONFLICT, "Dial has been modified since it was last read.")
 	}
 
 	// Update fields, if set.
 	if v := upd.Name; v != nil {
 		dial.Name = *v
 	}
+	if v := upd.ShareMembers; v != nil {
+		dial.ShareMembers = *v
+	}
+
+	// Update the public link & badge tokens.
+	if err := updateDialToken(&dial.ShareToken, upd.Shared, upd.RotateShareToken, "Dial must be shared to rotate its link."); err != nil {
+		return dial, err
+	} else if err := updateDialToken(&dial.BadgeToken, upd.Badge, upd.RotateBadgeToken, "Dial must have a badge to rotate its token."); err != nil {
+		return dial, err
+	}
 	dial.UpdatedAt = tx.now
+	dial.Version++
 
 	// Perform basic field validation.
 	if err := dial.Validate(); err != nil {
 		return dial, err
 	}
 
+	// Tokens are nullable and have UNIQUE constraints so ensure we store
+	// disabled tokens as NULLs.
+	var shareToken, badgeToken *string
+	if dial.IsShared() {
+		shareToken = &dial.ShareToken
+	}
+	if dial.HasBadge() {
+		badgeToken = &dial.BadgeToken
+	}
+
 	// Execute update query.
 	if _, err := tx.ExecContext(ctx, `
 		UPDATE dials
 		SET name = ?,
+		    share_token = ?,
+		    share_members = ?,
+		    badge_token = ?,
+		    version = ?,
 		    updated_at = ?
 		WHERE id = ?
 	`,
 		dial.Name,
+		shareToken,
+		dial.ShareMembers,
+		badgeToken,
+		dial.Version,
 		(*NullTime)(&dial.UpdatedAt),
 		id,
 	); err != nil {
@@ -455,6 +640,35 @@ func updateDial(ctx context.Context, tx *Tx, id int, upd wtf.DialUpdate) (*wtf.D
 	return dial, nil
 }
 
+// updateDialToken enables, disables or rotates a dial's public token. A new
+// token is generated when the token is first enabled or rotated. Disabling
+// removes the token so that old URLs are never reused. Returns EINVALID with
+// msg if rotating a disabled token.
+func updateDialToken(token *string, enable *bool, rotate bool, msg string) (err error) {
+	enabled := *token != ""
+	if enable != nil {
+		enabled = *enable
+	}
+
+	if !enabled && rotate {
+		return wtf.Errorf(wtf.EINVALID, msg)
+	} else if !enabled {
+		*token = ""
+	} else if *token == "" || rotate {
+		*token, err = generateDialToken()
+	}
+	return err
+}
+
+// generateDialToken returns a random token for a dial's public URLs.
+func generateDialToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
 // deleteDial permanently deletes a dial by ID. Returns EUNAUTHORIZED if user
 // does not own the dial.
 func deleteDial(ctx context.Context, tx *Tx, id int) error {
@@ -531,6 +745,11 @@ func refreshDialValue(ctx context.Context, tx *Tx, id int) error {
 		return fmt.Errorf("publish dial event: %w", err)
 	}
 
+	// Compare the new value against the dial's history.
+	if err := detectDialAnomaly(ctx, tx, id, newValue, tx.now); err != nil {
+		return fmt.Errorf("detect anomaly: %w", err)
+	}
+
 	return nil
 }
 
@@ -549,94 +768,17 @@ func insertDialValue(ctx context.Context, tx *Tx, id int, value int, timestamp t
 	); err != nil {
 		return FormatError(err)
 	}
-	return nil
-}
-
-// findDialValueSlotsBetween returns the value of a dial at given intervals in a time range.
-//
-// This function is implemented naively so that we build a set of slots, insert
-// values when they've changed, and then we backfill the empty slots with the
-// previous value.
-//
-// There's probably a fancier way to do this in SQL but this was pretty easy.
-func findDialValueSlotsBetween(ctx context.Context, tx *Tx, id int, start, end time.Time, interval time.Duration) ([]int, error) {
-	values := make([]int, end.Sub(start)/interval)
-	if len(values) == 0 {
-		return values, nil
-	}
 
-	// Mark slots empty. We'll fill them in later.
-	for i := range values {
-		values[i] = -1
-	}
-
-	// Determine initial value at start of report time range.
-	var value int
-	if err := tx.QueryRowContext(ctx, `
-		SELECT value
-		FROM dial_values
-		WHERE dial_id = ?
-		  AND "timestamp" <= ?
-		ORDER BY "timestamp" DESC
-		LIMIT 1
-		`,
-		id,
-		(*NullTime)(&start),
-	).Scan(
-		&value,
-	); err != nil && err != sql.ErrNoRows {
-		return nil, err
+	// Keep the hourly & daily summaries up to date for reporting.
+	if err := refreshDialValueRollups(ctx, tx, id, value, timestamp); err != nil {
+		return fmt.Errorf("refresh rollups: %w", err)
 	}
-	values[0] = value
-
-	// Find all values between start & end.
-	rows, err := tx.QueryContext(ctx, `
-		SELECT value, "timestamp"
-		FROM dial_values
-		WHERE dial_id = ?
-		  AND "timestamp" >= ?
-		  AND "timestamp" < ?
-		ORDER BY "timestamp" ASC
-	`,
-		id,
-		(*NullTime)(&start),
-		(*NullTime)(&end),
-	)
-	if err != nil {
-		return nil, FormatError(err)
-	}
-	defer rows.Close()
-
-	// Iterate over rows and assign values to slots.
-	for rows.Next() {
-		var timestamp time.Time
-		if rows.Scan(&value, (*NullTime)(&timestamp)); err != nil {
-			return nil, err
-		}
-
-		i := int(timestamp.Sub(start) / interval)
-		values[i] = value
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	} else if err := rows.Close(); err != nil {
-		return nil, err
-	}
-
-	// Iterate over values to fill empty slots.
-	var lastValue int
-	for i, v := range values {
-		if v != -1 {
-			lastValue = v
-			continue
-		}
-		values[i] = lastValue
-	}
-
-	return values, nil
+	return nil
 }
 
-// publishDialEvent publishes event to the dial members.
+// publishDialEvent publishes event to the dial members once tx commits so
+// that subscribers & caches never read the state from before the change.
+// Members are read within the transaction.
 func publishDialEvent(ctx context.Context, tx *Tx, id int, event wtf.Event) error {
 	// Find all users who are members of the dial.
 	rows, err := tx.QueryContext(ctx, `SELECT user_id FROM dial_memberships WHERE dial_id = ?`, id)
@@ -645,25 +787,49 @@ func publishDialEvent(ctx context.Context, tx *Tx, id int, event wtf.Event) erro
 	}
 	defer rows.Close()
 
-	// Iterate over users and publish event.
+	// Iterate over users and publish event after commit.
+	var userIDs []int
 	for rows.Next() {
 		var userID int
 		if err := rows.Scan(&userID); err != nil {
 			return err
 		}
-		tx.db.EventService.PublishEvent(userID, event)
+		userIDs = append(userIDs, userID)
 	}
-
 	if err := rows.Err(); err != nil {
 		return err
 	}
+
+	tx.afterCommit(func() {
+		for _, userID := range userIDs {
+			tx.db.EventService.PublishEvent(userID, event)
+		}
+	})
 	return nil
 }
 
 // attachDialAssociations is a helper function to look up and attach the owner user to the dial.
 func attachDialAssociations(ctx context.Context, tx *Tx, dial *wtf.Dial) (err error) {
-	if dial.User, err = findUserByID(ctx, tx, dial.UserID); err != nil {
+	return attachDialsAssociations(ctx, tx, []*wtf.Dial{dial})
+}
+
+// attachDialsAssociations attaches the owner user to each dial. Owners are
+// fetched with a single query per batch rather than one query per dial.
+func attachDialsAssociations(ctx context.Context, tx *Tx, dials []*wtf.Dial) error {
+	ids := make([]int, len(dials))
+	for i, dial := range dials {
+		ids[i] = dial.UserID
+	}
+
+	users, err := findUsersByIDs(ctx, tx, ids)
+	if err != nil {
 		return fmt.Errorf("attach dial user: %w", err)
 	}
+
+	for _, dial := range dials {
+		if dial.User = users[dial.UserID]; dial.User == nil {
+			return fmt.Errorf("attach dial user: %w", &wtf.Error{Code: wtf.ENOTFOUND, Message: "User not found."})
+		}
+	}
 	return nil
 }
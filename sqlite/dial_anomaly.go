@@ -0,0 +1,310 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// DialBaselineReplayN is the number of historical values replayed to seed
+// the baseline of a dial which does not have one yet.
+const DialBaselineReplayN = 100
+
+// Ensure service implements interface.
+var _ wtf.DialAnomalyService = (*DialAnomalyService)(nil)
+
+// DialAnomalyService represents a service for finding detected anomalies.
+type DialAnomalyService struct {
+	db *DB
+}
+
+// NewDialAnomalyService returns a new instance of DialAnomalyService.
+func NewDialAnomalyService(db *DB) *DialAnomalyService {
+	return &DialAnomalyService{db: db}
+}
+
+// FindDialAnomalies retrieves a list of anomalies based on a filter. Only
+// returns anomalies for dials that the user is a member of.
+func (s *DialAnomalyService) FindDialAnomalies(ctx context.Context, filter wtf.DialAnomalyFilter) ([]*wtf.DialAnomaly, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+	return findDialAnomalies(ctx, tx, filter)
+}
+
+// findDialAnomalies returns a list of anomalies matching a filter. Also
+// returns a count of total matching anomalies which may differ if
+// filter.Limit is set.
+func findDialAnomalies(ctx context.Context, tx *Tx, filter wtf.DialAnomalyFilter) (_ []*wtf.DialAnomaly, n int, err error) {
+	// Build WHERE clause. Each part of the WHERE clause is AND-ed together.
+	// Values are appended to an arg list to avoid SQL injection.
+	where, args := []string{"1 = 1"}, []interface{}{}
+	if v := filter.DialID; v != nil {
+		where, args = append(where, "dial_id = ?"), append(args, *v)
+	}
+	if v := filter.Since; v != nil {
+		where, args = append(where, `"timestamp" >= ?`), append(args, (*NullTime)(v))
+	}
+
+	// Limit to dials the user is a member of.
+	if !wtf.IsAdminContext(ctx) {
+		where = append(where, `dial_id IN (SELECT dm.dial_id FROM dial_memberships dm WHERE dm.user_id = ?)`)
+		args = append(args, wtf.UserIDFromContext(ctx))
+	}
+
+	// Execute query with limiting WHERE clause and LIMIT/OFFSET injected.
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+		    id,
+		    dial_id,
+		    value,
+		    baseline,
+		    stddev,
+		    score,
+		    "timestamp",
+		    COUNT(*) OVER()
+		FROM dial_anomalies
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY "timestamp" ASC, id ASC
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, n, FormatError(err)
+	}
+	defer rows.Close()
+
+	// Iterate over rows and deserialize into DialAnomaly objects.
+	anomalies := make([]*wtf.DialAnomaly, 0)
+	for rows.Next() {
+		var anomaly wtf.DialAnomaly
+		if err := rows.Scan(
+			&anomaly.ID,
+			&anomaly.DialID,
+			&anomaly.Value,
+			&anomaly.Baseline,
+			&anomaly.StdDev,
+			&anomaly.Score,
+			(*NullTime)(&anomaly.Timestamp),
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+		anomalies = append(anomalies, &anomaly)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return anomalies, n, nil
+}
+
+// dialBaseline represents a dial's baseline along with the value of the
+// minute which has not been observed by the baseline yet.
+type dialBaseline struct {
+	wtf.DialBaseline
+
+	// Last value recorded within the pending minute. The timestamp is zero
+	// if no value is pending.
+	pendingValue     int
+	pendingTimestamp time.Time
+}
+
+// detectDialAnomaly scores a new dial value against the dial's rolling
+// baseline and updates the baseline. If the value is anomalous then it is
+// stored & an event is published to the dial's members.
+//
+// Values are recorded once per minute so the baseline observes the last value
+// of each minute once the next minute starts. A value within the same minute
+// replaces the pending value & its anomaly, if any. This matches the baseline
+// replayed from history which excludes the minute being scored.
+func detectDialAnomaly(ctx context.Context, tx *Tx, id, value int, timestamp time.Time) error {
+	detector := tx.db.AnomalyDetector
+	if detector == nil {
+		return nil
+	}
+
+	timestamp = timestamp.Truncate(time.Minute)
+
+	baseline, err := findDialBaseline(ctx, tx, detector, id, timestamp)
+	if err != nil {
+		return fmt.Errorf("find baseline: %w", err)
+	}
+
+	// Observe the pending value once a different minute starts.
+	if !baseline.pendingTimestamp.IsZero() && !baseline.pendingTimestamp.Equal(timestamp) {
+		detector.Observe(&baseline.DialBaseline, baseline.pendingValue)
+	}
+	baseline.pendingValue, baseline.pendingTimestamp = value, timestamp
+
+	// Score the value against a copy so it is not observed until its minute ends.
+	other := baseline.DialBaseline
+	mean, stddev := other.Mean, other.StdDev()
+	score, anomalous := detector.Observe(&other, value)
+
+	if err := upsertDialBaseline(ctx, tx, id, baseline); err != nil {
+		return fmt.Errorf("upsert baseline: %w", err)
+	} else if err := deleteDialAnomalyAt(ctx, tx, id, timestamp); err != nil {
+		return fmt.Errorf("delete anomaly: %w", err)
+	} else if !anomalous {
+		return nil
+	}
+
+	anomaly := &wtf.DialAnomaly{
+		DialID:    id,
+		Value:     value,
+		Baseline:  roundStat(mean),
+		StdDev:    roundStat(stddev),
+		Score:     roundStat(score),
+		Timestamp: timestamp,
+	}
+	if err := createDialAnomaly(ctx, tx, anomaly); err != nil {
+		return fmt.Errorf("create anomaly: %w", err)
+	}
+
+	// Notify members so they can mark the anomaly on their charts.
+	if err := publishDialEvent(ctx, tx, id, wtf.Event{
+		Type:    wtf.EventTypeDialAnomalyDetected,
+		Payload: &wtf.DialAnomalyDetectedPayload{Anomaly: anomaly},
+	}); err != nil {
+		return fmt.Errorf("publish dial event: %w", err)
+	}
+	return nil
+}
+
+// findDialBaseline returns the stored baseline for a dial. If the dial has no
+// baseline yet then one is seeded by replaying the dial's recent values
+// recorded before timestamp.
+func findDialBaseline(ctx context.Context, tx *Tx, detector *wtf.AnomalyDetector, id int, timestamp time.Time) (*dialBaseline, error) {
+	var baseline dialBaseline
+	var pendingValue sql.NullInt64
+	if err := tx.QueryRowContext(ctx, `
+		SELECT mean, variance, n, pending_value, pending_timestamp
+		FROM dial_baselines
+		WHERE dial_id = ?
+	`,
+		id,
+	).Scan(
+		&baseline.Mean,
+		&baseline.Variance,
+		&baseline.N,
+		&pendingValue,
+		(*NullTime)(&baseline.pendingTimestamp),
+	); err == nil {
+		baseline.pendingValue = int(pendingValue.Int64)
+		return &baseline, nil
+	} else if err != sql.ErrNoRows {
+		return nil, FormatError(err)
+	}
+
+	// Read the most recent values in reverse and replay them oldest first.
+	rows, err := tx.QueryContext(ctx, `
+		SELECT value
+		FROM dial_values
+		WHERE dial_id = ?
+		  AND "timestamp" < ?
+		ORDER BY "timestamp" DESC
+		LIMIT ?
+	`,
+		id, (*NullTime)(&timestamp), DialBaselineReplayN,
+	)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer rows.Close()
+
+	var values []int
+	for rows.Next() {
+		var value int
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := len(values) - 1; i >= 0; i-- {
+		detector.Observe(&baseline.DialBaseline, values[i])
+	}
+	return &baseline, nil
+}
+
+// upsertDialBaseline stores the baseline for a dial.
+func upsertDialBaseline(ctx context.Context, tx *Tx, id int, baseline *dialBaseline) error {
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO dial_baselines (dial_id, mean, variance, n, pending_value, pending_timestamp, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (dial_id) DO UPDATE SET
+		    mean = excluded.mean,
+		    variance = excluded.variance,
+		    n = excluded.n,
+		    pending_value = excluded.pending_value,
+		    pending_timestamp = excluded.pending_timestamp,
+		    updated_at = excluded.updated_at
+	`,
+		id,
+		baseline.Mean,
+		baseline.Variance,
+		baseline.N,
+		baseline.pendingValue,
+		(*NullTime)(&baseline.pendingTimestamp),
+		(*NullTime)(&tx.now),
+	); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
+
+// deleteDialAnomalyAt removes the anomaly recorded for a dial at timestamp so
+// that it can be replaced by the latest value of the minute.
+func deleteDialAnomalyAt(ctx context.Context, tx *Tx, id int, timestamp time.Time) error {
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM dial_anomalies
+		WHERE dial_id = ? AND "timestamp" = ?
+	`,
+		id, (*NullTime)(&timestamp),
+	); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
+
+// createDialAnomaly inserts an anomaly into the database.
+func createDialAnomaly(ctx context.Context, tx *Tx, anomaly *wtf.DialAnomaly) error {
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO dial_anomalies (
+			dial_id,
+			value,
+			baseline,
+			stddev,
+			score,
+			"timestamp"
+		)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`,
+		anomaly.DialID,
+		anomaly.Value,
+		anomaly.Baseline,
+		anomaly.StdDev,
+		anomaly.Score,
+		(*NullTime)(&anomaly.Timestamp),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	anomaly.ID = int(id)
+	return nil
+}
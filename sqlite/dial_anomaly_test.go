@@ -0,0 +1,196 @@
+package sqlite_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/mock"
+	"github.com/benbjohnson/wtf/sqlite"
+)
+
+func TestDialAnomalyService_FindDialAnomalies(t *testing.T) {
+	// Ensure a spike from a steady baseline is flagged, stored & published.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialAnomalyService(db)
+
+		var events []wtf.Event
+		db.EventService = &mock.EventService{
+			PublishEventFn: func(userID int, event wtf.Event) {
+				if event.Type == wtf.EventTypeDialAnomalyDetected {
+					events = append(events, event)
+				}
+			},
+		}
+
+		start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		db.Now = func() time.Time { return start }
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "john"})
+		dial0 := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL0"})
+		MustCreateDial(t, ctx1, db, &wtf.Dial{Name: "DIAL1"})
+
+		// Establish a steady baseline around 50.
+		for i, value := range []int{50, 52, 48, 51, 49, 50, 53, 47, 50, 52, 48, 51} {
+			MustSetDialMembershipValueAt(t, ctx0, db, 1, value, start.Add(time.Duration(i+1)*time.Minute))
+		}
+		if len(events) != 0 {
+			t.Fatalf("unexpected events: %#v", events)
+		}
+
+		// Spike the value & return to normal.
+		spikeAt := start.Add(20 * time.Minute)
+		MustSetDialMembershipValueAt(t, ctx0, db, 1, 95, spikeAt)
+		MustSetDialMembershipValueAt(t, ctx0, db, 1, 50, spikeAt.Add(time.Minute))
+
+		anomalies, n, err := s.FindDialAnomalies(ctx0, wtf.DialAnomalyFilter{DialID: &dial0.ID})
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 1; got != want {
+			t.Fatalf("n=%v, want %v", got, want)
+		} else if got, want := len(anomalies), 1; got != want {
+			t.Fatalf("len=%v, want %v", got, want)
+		}
+
+		anomaly := anomalies[0]
+		if got, want := anomaly.Value, 95; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		} else if !anomaly.Timestamp.Equal(spikeAt) {
+			t.Fatalf("Timestamp=%v, want %v", anomaly.Timestamp, spikeAt)
+		} else if anomaly.Score < wtf.DefaultAnomalyThreshold {
+			t.Fatalf("unexpected score: %v", anomaly.Score)
+		} else if anomaly.Baseline < 45 || anomaly.Baseline > 55 {
+			t.Fatalf("unexpected baseline: %v", anomaly.Baseline)
+		}
+
+		// Ensure the event carries the stored anomaly.
+		if got, want := len(events), 1; got != want {
+			t.Fatalf("len(events)=%v, want %v", got, want)
+		} else if got, want := events[0].Payload, (&wtf.DialAnomalyDetectedPayload{Anomaly: anomaly}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("payload=%#v, want %#v", got, want)
+		}
+
+		// Ensure anomalies can be filtered by time.
+		since := spikeAt.Add(time.Second)
+		if _, n, err := s.FindDialAnomalies(ctx0, wtf.DialAnomalyFilter{Since: &since}); err != nil {
+			t.Fatal(err)
+		} else if n != 0 {
+			t.Fatalf("n=%v, want 0", n)
+		}
+
+		// Ensure other users cannot see anomalies for dials they do not belong to.
+		if _, n, err := s.FindDialAnomalies(ctx1, wtf.DialAnomalyFilter{}); err != nil {
+			t.Fatal(err)
+		} else if n != 0 {
+			t.Fatalf("n=%v, want 0", n)
+		}
+	})
+
+	// Ensure values are not flagged while the baseline is warming up.
+	t.Run("Warmup", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialAnomalyService(db)
+
+		start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		db.Now = func() time.Time { return start }
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane"})
+		MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL0"})
+
+		for i, value := range []int{50, 51, 100, 0} {
+			MustSetDialMembershipValueAt(t, ctx0, db, 1, value, start.Add(time.Duration(i+1)*time.Minute))
+		}
+
+		if _, n, err := s.FindDialAnomalies(ctx0, wtf.DialAnomalyFilter{}); err != nil {
+			t.Fatal(err)
+		} else if n != 0 {
+			t.Fatalf("n=%v, want 0", n)
+		}
+	})
+
+	// Ensure several values within a minute are only observed once so the
+	// baseline matches one replayed from history.
+	t.Run("SameMinute", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialAnomalyService(db)
+		detector := db.AnomalyDetector
+
+		start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		db.Now = func() time.Time { return start }
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane"})
+		dial0 := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL0"})
+		dial1 := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL1"})
+		dial2 := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL2"})
+
+		// Write one value per minute to the first dial & several values per
+		// minute to the second. Spikes within a minute are replaced by the
+		// last value of the minute.
+		values := []int{50, 52, 48, 51, 49, 50, 53, 47, 50, 52, 48, 51}
+		for i, value := range values {
+			t0 := start.Add(time.Duration(i+1) * time.Minute)
+			MustSetDialMembershipValueAt(t, ctx0, db, 1, value, t0)
+			MustSetDialMembershipValueAt(t, ctx0, db, 2, 0, t0)
+			MustSetDialMembershipValueAt(t, ctx0, db, 2, 100, t0.Add(10*time.Second))
+			MustSetDialMembershipValueAt(t, ctx0, db, 2, value, t0.Add(20*time.Second))
+		}
+
+		// Write the third dial's history without a baseline so it is replayed.
+		db.AnomalyDetector = nil
+		for i, value := range values {
+			MustSetDialMembershipValueAt(t, ctx0, db, 3, value, start.Add(time.Duration(i+1)*time.Minute))
+		}
+		db.AnomalyDetector = detector
+
+		spikeAt := start.Add(20 * time.Minute)
+		for _, membershipID := range []int{1, 2, 3} {
+			MustSetDialMembershipValueAt(t, ctx0, db, membershipID, 95, spikeAt)
+		}
+
+		var want *wtf.DialAnomaly
+		for _, dialID := range []int{dial0.ID, dial1.ID, dial2.ID} {
+			anomalies, _, err := s.FindDialAnomalies(ctx0, wtf.DialAnomalyFilter{DialID: &dialID})
+			if err != nil {
+				t.Fatal(err)
+			} else if got, want := len(anomalies), 1; got != want {
+				t.Fatalf("dial %d: len=%v, want %v", dialID, got, want)
+			} else if !anomalies[0].Timestamp.Equal(spikeAt) {
+				t.Fatalf("dial %d: Timestamp=%v, want %v", dialID, anomalies[0].Timestamp, spikeAt)
+			}
+
+			if want == nil {
+				want = anomalies[0]
+			} else if got := anomalies[0]; got.Baseline != want.Baseline || got.StdDev != want.StdDev || got.Score != want.Score {
+				t.Fatalf("dial %d: anomaly=%#v, want %#v", dialID, got, want)
+			}
+		}
+	})
+
+	// Ensure detection can be disabled.
+	t.Run("Disabled", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		db.AnomalyDetector = nil
+		s := sqlite.NewDialAnomalyService(db)
+
+		start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		db.Now = func() time.Time { return start }
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane"})
+		MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL0"})
+
+		for i := 0; i < 20; i++ {
+			MustSetDialMembershipValueAt(t, ctx0, db, 1, 50+i%2, start.Add(time.Duration(i+1)*time.Minute))
+		}
+		MustSetDialMembershipValueAt(t, ctx0, db, 1, 100, start.Add(time.Hour))
+
+		if _, n, err := s.FindDialAnomalies(ctx0, wtf.DialAnomalyFilter{}); err != nil {
+			t.Fatal(err)
+		} else if n != 0 {
+			t.Fatalf("n=%v, want 0", n)
+		}
+	})
+}
@@ -0,0 +1,124 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// ImportDials creates dials from a list of import rows on behalf of the
+// current user. Invalid rows are reported in the result instead of aborting
+// the import. No changes are made if opt.DryRun is set.
+//
+// Returns EUNAUTHORIZED if the user is not logged in.
+func (s *DialService) ImportDials(ctx context.Context, rows []*wtf.DialImportRow, opt wtf.DialImportOptions) (*wtf.DialImportResult, error) {
+	// Ensure a user is logged in to own the new dials.
+	if wtf.UserIDFromContext(ctx) == 0 {
+		return nil, wtf.Errorf(wtf.EUNAUTHORIZED, "You must be logged in to import dials.")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result := &wtf.DialImportResult{
+		Dials:  make([]*wtf.Dial, 0),
+		Errors: make([]*wtf.DialImportError, 0),
+		DryRun: opt.DryRun,
+	}
+
+	// Validate each row & group valid rows by dial name. Names are kept in
+	// the order they first appear so dials are created in file order.
+	var names []string
+	groups := make(map[string][]*wtf.DialImportRow)
+	for _, row := range rows {
+		if err := row.Validate(tx.now); err != nil {
+			result.AddError(row.Row, err)
+			continue
+		}
+
+		if _, ok := groups[row.Name]; !ok {
+			names = append(names, row.Name)
+		}
+		groups[row.Name] = append(groups[row.Name], row)
+	}
+
+	// Create each dial & backfill its historical values.
+	for _, name := range names {
+		dial, n, err := importDial(ctx, tx, name, groups[name], opt.DryRun)
+		if err != nil {
+			return nil, fmt.Errorf("import dial: row=%d err=%w", groups[name][0].Row, err)
+		}
+		result.Dials = append(result.Dials, dial)
+		result.ValueN += n
+	}
+
+	// Discard all changes if this is only a dry run.
+	if opt.DryRun {
+		return result, nil
+	} else if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// importDial creates a single dial from a group of validated rows. Returns the
+// dial & the number of values backfilled. The dial is not saved if dryRun is set.
+func importDial(ctx context.Context, tx *Tx, name string, rows []*wtf.DialImportRow, dryRun bool) (*wtf.Dial, int, error) {
+	userID := wtf.UserIDFromContext(ctx)
+	dial := &wtf.Dial{UserID: userID, Name: name}
+
+	// Extract historical values & sort them so the latest value is last.
+	var values []*wtf.DialImportRow
+	for _, row := range rows {
+		if row.Value != nil {
+			values = append(values, row)
+		}
+	}
+	sort.SliceStable(values, func(i, j int) bool { return values[i].Timestamp.Before(*values[j].Timestamp) })
+
+	// Only compute the resulting value during a dry run.
+	if dryRun {
+		if len(values) > 0 {
+			dial.Value = *values[len(values)-1].Value
+		}
+		return dial, len(values), nil
+	}
+
+	// Create dial. This also creates the owner's membership.
+	if err := createDial(ctx, tx, dial); err != nil {
+		return nil, 0, err
+	}
+
+	// Record each historical value.
+	for _, row := range values {
+		if err := insertDialValue(ctx, tx, dial.ID, *row.Value, *row.Timestamp); err != nil {
+			return nil, 0, fmt.Errorf("insert historical value: %w", err)
+		}
+	}
+
+	// Carry the latest value forward as the owner's current value.
+	if len(values) > 0 {
+		memberships, _, err := findDialMemberships(ctx, tx, wtf.DialMembershipFilter{DialID: &dial.ID, UserID: &userID})
+		if err != nil {
+			return nil, 0, err
+		} else if len(memberships) == 0 {
+			return nil, 0, fmt.Errorf("owner membership not found")
+		} else if _, err := updateDialMembership(ctx, tx, memberships[0].ID, wtf.DialMembershipUpdate{Value: values[len(values)-1].Value}); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	// Re-read the dial to include the computed value & owner.
+	other, err := findDialByID(ctx, tx, dial.ID)
+	if err != nil {
+		return nil, 0, err
+	} else if err := attachDialAssociations(ctx, tx, other); err != nil {
+		return nil, 0, err
+	}
+	return other, len(values), nil
+}
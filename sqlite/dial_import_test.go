@@ -0,0 +1,107 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/sqlite"
+)
+
+func TestDialService_ImportDials(t *testing.T) {
+	// Ensure valid rows are imported & invalid rows are reported.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		db.Now = func() time.Time {
+			return time.Date(2000, time.January, 2, 0, 0, 0, 0, time.UTC)
+		}
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+
+		t0 := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		t1 := time.Date(2000, time.January, 1, 12, 0, 0, 0, time.UTC)
+		future := time.Date(2000, time.January, 3, 0, 0, 0, 0, time.UTC)
+		result, err := s.ImportDials(ctx0, []*wtf.DialImportRow{
+			{Row: 2, Name: "DIAL0", Value: intPtr(80), Timestamp: &t1},
+			{Row: 3, Name: "DIAL0", Value: intPtr(20), Timestamp: &t0},
+			{Row: 4, Name: "DIAL1"},
+			{Row: 5, Name: ""},
+			{Row: 6, Name: "DIAL2", Value: intPtr(10), Timestamp: &future},
+		}, wtf.DialImportOptions{})
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := len(result.Dials), 2; got != want {
+			t.Fatalf("len(Dials)=%v, want %v", got, want)
+		} else if got, want := result.ValueN, 2; got != want {
+			t.Fatalf("ValueN=%v, want %v", got, want)
+		} else if got, want := len(result.Errors), 2; got != want {
+			t.Fatalf("len(Errors)=%v, want %v", got, want)
+		} else if got, want := result.Errors[0].Row, 5; got != want {
+			t.Fatalf("Errors[0].Row=%v, want %v", got, want)
+		} else if got, want := result.Errors[1].Code, wtf.EINVALID; got != want {
+			t.Fatalf("Errors[1].Code=%v, want %v", got, want)
+		}
+
+		// Ensure latest value is carried forward as the current value.
+		if dial := MustFindDialByID(t, ctx0, db, result.Dials[0].ID); dial.Name != "DIAL0" {
+			t.Fatalf("unexpected name: %s", dial.Name)
+		} else if got, want := dial.Value, 80; got != want {
+			t.Fatalf("Value=%v, want %v", got, want)
+		}
+
+		// Ensure historical values are backfilled.
+		report, err := s.DialValueReport(ctx0, result.Dials[0].ID, t0, t0.Add(24*time.Hour), wtf.ReportInterval{Duration: 12 * time.Hour})
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := report.Records[0].Value, 20; got != want {
+			t.Fatalf("Records[0].Value=%v, want %v", got, want)
+		} else if got, want := report.Records[1].Value, 80; got != want {
+			t.Fatalf("Records[1].Value=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure a dry run validates rows but does not create dials.
+	t.Run("DryRun", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+
+		if result, err := s.ImportDials(ctx0, []*wtf.DialImportRow{{Row: 2, Name: "DIAL0"}}, wtf.DialImportOptions{DryRun: true}); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(result.Dials), 1; got != want {
+			t.Fatalf("len(Dials)=%v, want %v", got, want)
+		} else if !result.DryRun {
+			t.Fatal("expected dry run")
+		}
+
+		if _, n, err := s.FindDials(ctx0, wtf.DialFilter{}); err != nil {
+			t.Fatal(err)
+		} else if n != 0 {
+			t.Fatalf("unexpected dial count: %d", n)
+		}
+	})
+
+	// Ensure importing requires a logged in user.
+	t.Run("ErrUnauthorized", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		if _, err := s.ImportDials(context.Background(), []*wtf.DialImportRow{{Row: 2, Name: "DIAL0"}}, wtf.DialImportOptions{}); wtf.ErrorCode(err) != wtf.EUNAUTHORIZED {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+// intPtr returns a pointer to v.
+func intPtr(v int) *int {
+	return &v
+}
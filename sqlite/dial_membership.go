@@ -56,12 +56,9 @@ func (s *DialMembershipService) FindDialMemberships(ctx context.Context, filter
 		return memberships, n, err
 	}
 
-	// Attach dial & user to each returned membership.
-	// This should be batched up if you were to use a remote database server.
-	for _, membership := range memberships {
-		if err := attachDialMembershipAssociations(ctx, tx, membership); err != nil {
-			return memberships, n, err
-		}
+	// Attach dial & user to each returned membership in bulk.
+	if err := attachDialMembershipsAssociations(ctx, tx, memberships); err != nil {
+		return memberships, n, err
 	}
 	return memberships, n, nil
 }
@@ -158,37 +155,57 @@ func findDialMemberships(ctx context.Context, tx *Tx, filter wtf.DialMembershipF
 		d.user_id = ? OR
 		dm.dial_id IN (SELECT dm1.dial_id FROM dial_memberships dm1 WHERE dm1.user_id = ?)
 	)`)
-	args = append(args, userID, userID, userID)
+	args = append(args, userID, userID)
 
-	// Determine sorting.
-	var sortBy string
+	// Determine sorting. By default, the current user's membership is sorted
+	// first and then the remaining memberships are ordered by user name.
+	var q *cursorQuery
 	switch filter.SortBy {
 	case wtf.DialMembershipSortByUpdatedAtDesc:
-		sortBy = "dm.updated_at DESC"
+		q, err = newCursorQuery([]string{"updated_at"}, true, filter.After, filter.Before)
 	default:
-		// Sort current user's membership first and then order by user name.
-		sortBy = `CASE dm.user_id WHEN ? THEN 0 ELSE 1 END ASC, u.name ASC`
-		args = append(args, userID)
+		q, err = newCursorQuery([]string{"sort_rank", "user_name"}, false, filter.After, filter.Before)
+	}
+	if err != nil {
+		return nil, 0, err
 	}
+	cursorWhere, cursorArgs := q.where()
 
-	// Query for all matching membership rows.
+	// Query for all matching membership rows. The total count is computed
+	// before the cursor is applied so that it is the same for every page.
 	rows, err := tx.QueryContext(ctx, `
-		SELECT 
-		    dm.id,
-		    dm.dial_id,
-		    dm.user_id,
-		    dm.value,
-		    dm.created_at,
-		    dm.updated_at,
-		    d.user_id AS dial_user_id,
-		    COUNT(*) OVER()
-		FROM dial_memberships dm
-		INNER JOIN dials d ON dm.dial_id = d.id
-		INNER JOIN users u ON dm.user_id = u.id
-		WHERE `+strings.Join(where, " AND ")+`
-		ORDER BY `+sortBy+`
+		SELECT
+		    id,
+		    dial_id,
+		    user_id,
+		    value,
+		    version,
+		    created_at,
+		    updated_at,
+		    dial_user_id,
+		    n
+		FROM (
+		    SELECT
+		        dm.id,
+		        dm.dial_id,
+		        dm.user_id,
+		        dm.value,
+		        dm.version,
+		        dm.created_at,
+		        dm.updated_at,
+		        d.user_id AS dial_user_id,
+		        CASE dm.user_id WHEN ? THEN 0 ELSE 1 END AS sort_rank,
+		        u.name AS user_name,
+		        COUNT(*) OVER() AS n
+		    FROM dial_memberships dm
+		    INNER JOIN dials d ON dm.dial_id = d.id
+		    INNER JOIN users u ON dm.user_id = u.id
+		    WHERE `+strings.Join(where, " AND ")+`
+		)
+		WHERE `+cursorWhere+`
+		ORDER BY `+q.orderBy()+`
 		`+FormatLimitOffset(filter.Limit, filter.Offset),
-		args...,
+		append(append([]interface{}{userID}, args...), cursorArgs...)...,
 	)
 	if err != nil {
 		return nil, n, FormatError(err)
@@ -205,6 +222,7 @@ func findDialMemberships(ctx context.Context, tx *Tx, filter wtf.DialMembershipF
 			&membership.DialID,
 			&membership.UserID,
 			&membership.Value,
+			&membership.Version,
 			(*NullTime)(&membership.CreatedAt),
 			(*NullTime)(&membership.UpdatedAt),
 			&dialUserID,
@@ -219,6 +237,13 @@ func findDialMemberships(ctx context.Context, tx *Tx, filter wtf.DialMembershipF
 		return nil, 0, err
 	}
 
+	// Restore the sort order if rows were fetched backward from a cursor.
+	if q.before {
+		for i, j := 0, len(memberships)-1; i < j; i, j = i+1, j-1 {
+			memberships[i], memberships[j] = memberships[j], memberships[i]
+		}
+	}
+
 	return memberships, n, nil
 }
 
@@ -228,6 +253,7 @@ func createDialMembership(ctx context.Context, tx *Tx, membership *wtf.DialMembe
 	// Update timestamps to current time.
 	membership.CreatedAt = tx.now
 	membership.UpdatedAt = membership.CreatedAt
+	membership.Version = 1
 
 	// Perform basic field validation.
 	if err := membership.Validate(); err != nil {
@@ -252,14 +278,16 @@ func createDialMembership(ctx context.Context, tx *Tx, membership *wtf.DialMembe
 			dial_id,
 			user_id,
 			value,
+			version,
 			created_at,
 			updated_at
 		)
-		VALUES (?, ?, ?, ?, ?)
+		VALUES (?, ?, ?, ?, ?, ?)
 	`,
 		membership.DialID,
 		membership.UserID,
 		membership.Value,
+		membership.Version,
 		(*NullTime)(&membership.CreatedAt),
 		(*NullTime)(&membership.UpdatedAt),
 	)
@@ -289,6 +317,8 @@ func updateDialMembership(ctx context.Context, tx *Tx, id int, upd wtf.DialMembe
 		return membership, err
 	} else if membership.UserID != wtf.UserIDFromContext(ctx) {
 		return membership, wtf.Errorf(wtf.EUNAUTHORIZED, "You do not have permission to update the dial membership.")
+	} else if upd.Version != nil && *upd.Version != membership.Version {
+		return membership, wtf.Errorf(wtf.ECONFLICT, "Dial membership has been modified since it was last read.")
 	}
 
 	// Save state of membership to compare later in the function.
@@ -306,6 +336,7 @@ func updateDialMembership(ctx context.Context, tx *Tx, id int, upd wtf.DialMembe
 
 	// Set last updated date to current time.
 	membership.UpdatedAt = tx.now
+	membership.Version++
 
 	// Perform basic field validation.
 	if err := membership.Validate(); err != nil {
@@ -316,16 +347,23 @@ func updateDialMembership(ctx context.Context, tx *Tx, id int, upd wtf.DialMembe
 	if _, err := tx.ExecContext(ctx, `
 		UPDATE dial_memberships
 		SET value = ?,
+		    version = ?,
 		    updated_at = ?
 		WHERE id = ?
 	`,
 		membership.Value,
+		membership.Version,
 		(*NullTime)(&membership.UpdatedAt),
 		id,
 	); err != nil {
 		return membership, FormatError(err)
 	}
 
+	// Record the member as contributing to the dial's history.
+	if err := insertDialValueMember(ctx, tx, membership.DialID, membership.UserID, tx.now); err != nil {
+		return membership, fmt.Errorf("insert dial value member: %w", err)
+	}
+
 	// Ensure computed dial value is up to date.
 	if err := refreshDialValue(ctx, tx, membership.DialID); err != nil {
 		return membership, fmt.Errorf("refresh dial value: %w", err)
@@ -380,11 +418,35 @@ func deleteDialMembership(ctx context.Context, tx *Tx, id int) error {
 	return nil
 }
 
+// attachDialMembershipAssociations attaches the dial & user to a membership.
 func attachDialMembershipAssociations(ctx context.Context, tx *Tx, membership *wtf.DialMembership) (err error) {
-	if membership.Dial, err = findDialByID(ctx, tx, membership.DialID); err != nil {
+	return attachDialMembershipsAssociations(ctx, tx, []*wtf.DialMembership{membership})
+}
+
+// attachDialMembershipsAssociations attaches the dial & user to each
+// membership. Dials & users are each fetched with a single query per batch
+// rather than two queries per membership.
+func attachDialMembershipsAssociations(ctx context.Context, tx *Tx, memberships []*wtf.DialMembership) error {
+	dialIDs, userIDs := make([]int, len(memberships)), make([]int, len(memberships))
+	for i, membership := range memberships {
+		dialIDs[i], userIDs[i] = membership.DialID, membership.UserID
+	}
+
+	dials, err := findDialsByIDs(ctx, tx, dialIDs)
+	if err != nil {
 		return fmt.Errorf("attach membership dial: %w", err)
-	} else if membership.User, err = findUserByID(ctx, tx, membership.UserID); err != nil {
+	}
+	users, err := findUsersByIDs(ctx, tx, userIDs)
+	if err != nil {
 		return fmt.Errorf("attach membership user: %w", err)
 	}
+
+	for _, membership := range memberships {
+		if membership.Dial = dials[membership.DialID]; membership.Dial == nil {
+			return fmt.Errorf("attach membership dial: %w", &wtf.Error{Code: wtf.ENOTFOUND, Message: "Dial not found."})
+		} else if membership.User = users[membership.UserID]; membership.User == nil {
+			return fmt.Errorf("attach membership user: %w", &wtf.Error{Code: wtf.ENOTFOUND, Message: "User not found."})
+		}
+	}
 	return nil
 }
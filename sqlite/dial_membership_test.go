@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/mock"
 	"github.com/benbjohnson/wtf/sqlite"
 )
 
@@ -117,6 +118,71 @@ func TestDialMembershipService_UpdateDialMembership(t *testing.T) {
 		}
 	})
 
+	// Ensure events are published once the change is visible to other readers
+	// & are not published if the transaction fails.
+	t.Run("PublishAfterCommit", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialMembershipService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane", Email: "jane@gmail.com"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+
+		var values []int
+		db.EventService = &mock.EventService{
+			PublishEventFn: func(userID int, event wtf.Event) {
+				if event.Type != wtf.EventTypeDialMembershipValueChanged {
+					return
+				}
+				other, err := sqlite.NewDialService(db).FindDialByID(ctx0, dial.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				values = append(values, other.Value)
+			},
+		}
+
+		value, version := 40, 1
+		if _, err := s.UpdateDialMembership(ctx0, 1, wtf.DialMembershipUpdate{Value: &value, Version: &version}); err != nil {
+			t.Fatal(err)
+		}
+
+		value = 60
+		if _, err := s.UpdateDialMembership(ctx0, 1, wtf.DialMembershipUpdate{Value: &value, Version: &version}); wtf.ErrorCode(err) != wtf.ECONFLICT {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if got, want := values, []int{40}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("values=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure a stale version is rejected.
+	t.Run("ErrConflict", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialMembershipService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane", Email: "jane@gmail.com"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jim", Email: "jim@gmail.com"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		membership := MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID, Value: 50})
+
+		value, version := 10, 1
+		if other, err := s.UpdateDialMembership(ctx1, membership.ID, wtf.DialMembershipUpdate{Value: &value, Version: &version}); err != nil {
+			t.Fatal(err)
+		} else if got, want := other.Version, 2; got != want {
+			t.Fatalf("Version=%v, want %v", got, want)
+		}
+
+		value = 20
+		if _, err := s.UpdateDialMembership(ctx1, membership.ID, wtf.DialMembershipUpdate{Value: &value, Version: &version}); wtf.ErrorCode(err) != wtf.ECONFLICT {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if other := MustFindDialMembershipByID(t, ctx1, db, membership.ID); other.Value != 10 {
+			t.Fatalf("Value=%v, want %v", other.Value, 10)
+		}
+	})
+
 	// Ensure historical values are stored with a resolution of 1 minute.
 	t.Run("DialValueRollup", func(t *testing.T) {
 		db := MustOpenDB(t)
@@ -299,6 +365,48 @@ func TestDialMembershipService_FindDialMemberships(t *testing.T) {
 			t.Fatalf("[].ID=%v, want %v", got, want)
 		}
 	})
+
+	// Ensure memberships can be paged using cursors in the default order.
+	t.Run("Cursor", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialMembershipService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "zoe"})
+		dial0 := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL0"})
+		for _, name := range []string{"carl", "amy", "bob"} {
+			_, ctx := MustCreateUser(t, ctx, db, &wtf.User{Name: name})
+			MustCreateDialMembership(t, ctx, db, &wtf.DialMembership{DialID: dial0.ID})
+		}
+
+		// The current user is listed first and then other members by name.
+		filter := wtf.DialMembershipFilter{DialID: &dial0.ID, Limit: 2}
+		a, n, err := s.FindDialMemberships(ctx0, filter)
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := membershipUserNames(a), []string{"zoe", "amy"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("names=%v, want %v", got, want)
+		} else if got, want := n, 4; got != want {
+			t.Fatalf("n=%v, want %v", got, want)
+		}
+
+		after := filter
+		after.After = filter.Cursor(ctx0, a[1]).String()
+		if a, _, err = s.FindDialMemberships(ctx0, after); err != nil {
+			t.Fatal(err)
+		} else if got, want := membershipUserNames(a), []string{"bob", "carl"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("names=%v, want %v", got, want)
+		}
+
+		before := filter
+		before.Before = filter.Cursor(ctx0, a[0]).String()
+		if a, _, err = s.FindDialMemberships(ctx0, before); err != nil {
+			t.Fatal(err)
+		} else if got, want := membershipUserNames(a), []string{"zoe", "amy"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("names=%v, want %v", got, want)
+		}
+	})
 }
 
 func TestDialMembershipService_DeleteDialMembership(t *testing.T) {
@@ -425,3 +533,12 @@ func MustSetDialMembershipValue(tb testing.TB, ctx context.Context, db *sqlite.D
 		tb.Fatal(err)
 	}
 }
+
+// membershipUserNames returns the user names of a list of memberships.
+func membershipUserNames(a []*wtf.DialMembership) []string {
+	names := make([]string, len(a))
+	for i := range a {
+		names[i] = a[i].User.Name
+	}
+	return names
+}
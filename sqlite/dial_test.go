@@ -120,6 +120,132 @@ func TestDialService_UpdateDial(t *testing.T) {
 			t.Fatalf("mismatch: %#v != %#v", uu, other)
 		}
 	})
+
+	// Ensure an update with an expected version is applied and increments
+	// the version, and that a stale version is rejected.
+	t.Run("Version", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane", Email: "jane@gmail.com"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "NAME"})
+		if got, want := dial.Version, 1; got != want {
+			t.Fatalf("Version=%v, want %v", got, want)
+		}
+
+		name, version := "NAME2", 1
+		if uu, err := s.UpdateDial(ctx0, dial.ID, wtf.DialUpdate{Name: &name, Version: &version}); err != nil {
+			t.Fatal(err)
+		} else if got, want := uu.Version, 2; got != want {
+			t.Fatalf("Version=%v, want %v", got, want)
+		}
+
+		// Update again using the original, now stale, version.
+		name = "NAME3"
+		if _, err := s.UpdateDial(ctx0, dial.ID, wtf.DialUpdate{Name: &name, Version: &version}); wtf.ErrorCode(err) != wtf.ECONFLICT {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if other := MustFindDialByID(t, ctx0, db, dial.ID); other.Name != "NAME2" || other.Version != 2 {
+			t.Fatalf("unexpected dial: %#v", other)
+		}
+	})
+
+	// Ensure a public link can be enabled, rotated & disabled by the owner.
+	t.Run("Share", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane", Email: "jane@gmail.com"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "NAME"})
+		if dial.IsShared() {
+			t.Fatal("expected dial to not be shared")
+		}
+
+		shared := true
+		uu, err := s.UpdateDial(ctx0, dial.ID, wtf.DialUpdate{Shared: &shared})
+		if err != nil {
+			t.Fatal(err)
+		} else if uu.ShareToken == "" {
+			t.Fatal("expected share token")
+		}
+		token := uu.ShareToken
+
+		// Ensure the dial can be found by anyone with the token but the
+		// token itself is only visible to the owner.
+		if a, _, err := s.FindDials(ctx, wtf.DialFilter{ShareToken: &token}); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(a), 1; got != want {
+			t.Fatalf("len=%v, want %v", got, want)
+		} else if got, want := a[0].ShareToken, ""; got != want {
+			t.Fatalf("ShareToken=%v, want %v", got, want)
+		}
+
+		// Rotating replaces the token so the previous link stops working.
+		if uu, err = s.UpdateDial(ctx0, dial.ID, wtf.DialUpdate{RotateShareToken: true}); err != nil {
+			t.Fatal(err)
+		} else if uu.ShareToken == "" || uu.ShareToken == token {
+			t.Fatalf("unexpected share token: %q", uu.ShareToken)
+		} else if a, _, err := s.FindDials(ctx, wtf.DialFilter{ShareToken: &token}); err != nil {
+			t.Fatal(err)
+		} else if len(a) != 0 {
+			t.Fatalf("expected previous token to be invalid")
+		}
+
+		// Disabling removes the token.
+		shared = false
+		if uu, err = s.UpdateDial(ctx0, dial.ID, wtf.DialUpdate{Shared: &shared}); err != nil {
+			t.Fatal(err)
+		} else if uu.IsShared() {
+			t.Fatal("expected dial to not be shared")
+		}
+	})
+
+	// Ensure a badge token is independent of the public link.
+	t.Run("Badge", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane", Email: "jane@gmail.com"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "NAME"})
+
+		enabled := true
+		uu, err := s.UpdateDial(ctx0, dial.ID, wtf.DialUpdate{Badge: &enabled})
+		if err != nil {
+			t.Fatal(err)
+		} else if !uu.HasBadge() {
+			t.Fatal("expected badge token")
+		} else if uu.IsShared() {
+			t.Fatal("expected dial to not be shared")
+		}
+
+		if a, _, err := s.FindDials(ctx, wtf.DialFilter{BadgeToken: &uu.BadgeToken}); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(a), 1; got != want {
+			t.Fatalf("len=%v, want %v", got, want)
+		} else if a, _, err := s.FindDials(ctx, wtf.DialFilter{ShareToken: &uu.BadgeToken}); err != nil {
+			t.Fatal(err)
+		} else if len(a) != 0 {
+			t.Fatal("expected badge token to not grant a public link")
+		}
+	})
+
+	// Ensure a link cannot be rotated before it is enabled.
+	t.Run("ErrRotateUnshared", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane", Email: "jane@gmail.com"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "NAME"})
+		if _, err := s.UpdateDial(ctx0, dial.ID, wtf.DialUpdate{RotateShareToken: true}); wtf.ErrorCode(err) != wtf.EINVALID {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
 }
 
 func TestDialService_FindDials(t *testing.T) {
@@ -175,6 +301,28 @@ func TestDialService_FindDials(t *testing.T) {
 		}
 	})
 
+	// Ensure an admin context can see all dials.
+	t.Run("Admin", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "john", Email: "john@gmail.com"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane", Email: "jane@gmail.com"})
+
+		MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "dial0"})
+		MustCreateDial(t, ctx1, db, &wtf.Dial{Name: "dial1"})
+
+		s := sqlite.NewDialService(db)
+		if a, n, err := s.FindDials(wtf.NewContextWithAdmin(ctx), wtf.DialFilter{}); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(a), 2; got != want {
+			t.Fatalf("len=%v, want %v", got, want)
+		} else if got, want := n, 2; got != want {
+			t.Fatalf("n=%v, want %v", got, want)
+		}
+	})
+
 	// Ensure dial can be found by invite code even if not logged in.
 	t.Run("InviteCode", func(t *testing.T) {
 		db := MustOpenDB(t)
@@ -197,6 +345,130 @@ func TestDialService_FindDials(t *testing.T) {
 			t.Fatalf("n=%v, want %v", got, want)
 		}
 	})
+
+	// Ensure dials can be paged forward & backward using cursors.
+	t.Run("Cursor", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "john"})
+		for _, name := range []string{"dial0", "dial1", "dial2", "dial3", "dial4"} {
+			MustCreateDial(t, ctx0, db, &wtf.Dial{Name: name})
+		}
+
+		s := sqlite.NewDialService(db)
+		filter := wtf.DialFilter{Limit: 2}
+		a, n, err := s.FindDials(ctx0, filter)
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := dialNames(a), []string{"dial0", "dial1"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("names=%v, want %v", got, want)
+		} else if got, want := n, 5; got != want {
+			t.Fatalf("n=%v, want %v", got, want)
+		}
+
+		// Fetch the following page. The total count is unaffected by the cursor.
+		a, n, err = s.FindDials(ctx0, wtf.DialFilter{Limit: 2, After: filter.Cursor(a[1]).String()})
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := dialNames(a), []string{"dial2", "dial3"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("names=%v, want %v", got, want)
+		} else if got, want := n, 5; got != want {
+			t.Fatalf("n=%v, want %v", got, want)
+		}
+
+		// Page backward from the last dial.
+		a, _, err = s.FindDials(ctx0, wtf.DialFilter{Limit: 3, Before: filter.Cursor(&wtf.Dial{ID: 5}).String()})
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := dialNames(a), []string{"dial1", "dial2", "dial3"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("names=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure dials can be searched by name, value, owner & update time.
+	t.Run("Search", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+
+		ctx := context.Background()
+		user0, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "john"})
+		user1, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+
+		db.Now = func() time.Time { return time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC) }
+		MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "Backend Deploys"})
+		dial1 := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "frontend_build"})
+
+		db.Now = func() time.Time { return time.Date(2000, time.January, 2, 0, 0, 0, 0, time.UTC) }
+		dial2 := MustCreateDial(t, ctx1, db, &wtf.Dial{Name: "Release 100%"})
+		MustCreateDialMembership(t, ctx0, db, &wtf.DialMembership{DialID: dial2.ID, UserID: user0.ID})
+
+		s := sqlite.NewDialService(db)
+		if err := s.SetDialMembershipValue(ctx0, dial1.ID, 80); err != nil {
+			t.Fatal(err)
+		} else if err := s.SetDialMembershipValue(ctx0, dial2.ID, 40); err != nil {
+			t.Fatal(err)
+		} else if err := s.SetDialMembershipValue(ctx1, dial2.ID, 40); err != nil {
+			t.Fatal(err)
+		}
+		for _, tt := range []struct {
+			name   string
+			filter wtf.DialFilter
+			want   []string
+		}{
+			{"Query", wtf.DialFilter{Query: stringPtr("END")}, []string{"Backend Deploys", "frontend_build"}},
+			{"QueryWildcard", wtf.DialFilter{Query: stringPtr("100%")}, []string{"Release 100%"}},
+			{"QueryLiteral", wtf.DialFilter{Query: stringPtr("_")}, []string{"frontend_build"}},
+			{"MinValue", wtf.DialFilter{MinValue: intPtr(30)}, []string{"frontend_build", "Release 100%"}},
+			{"ValueRange", wtf.DialFilter{MinValue: intPtr(30), MaxValue: intPtr(50)}, []string{"Release 100%"}},
+			{"Owner", wtf.DialFilter{UserID: &user1.ID}, []string{"Release 100%"}},
+			{"UpdatedSince", wtf.DialFilter{UpdatedSince: timePtr(time.Date(2000, time.January, 2, 0, 0, 0, 0, time.UTC))}, []string{"frontend_build", "Release 100%"}},
+			{"SortByName", wtf.DialFilter{SortBy: wtf.DialSortByNameAsc}, []string{"Backend Deploys", "frontend_build", "Release 100%"}},
+			{"SortByValueDesc", wtf.DialFilter{SortBy: wtf.DialSortByValueDesc}, []string{"frontend_build", "Release 100%", "Backend Deploys"}},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				if a, _, err := s.FindDials(ctx0, tt.filter); err != nil {
+					t.Fatal(err)
+				} else if got := dialNames(a); !reflect.DeepEqual(got, tt.want) {
+					t.Fatalf("names=%v, want %v", got, tt.want)
+				}
+			})
+		}
+
+		// Ensure sorted results can be paged by cursor.
+		filter := wtf.DialFilter{SortBy: wtf.DialSortByNameDesc, Limit: 2}
+		if a, _, err := s.FindDials(ctx0, filter); err != nil {
+			t.Fatal(err)
+		} else if got, want := dialNames(a), []string{"Release 100%", "frontend_build"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("names=%v, want %v", got, want)
+		} else if a, _, err = s.FindDials(ctx0, wtf.DialFilter{SortBy: filter.SortBy, Limit: 2, After: filter.Cursor(a[1]).String()}); err != nil {
+			t.Fatal(err)
+		} else if got, want := dialNames(a), []string{"Backend Deploys"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("names=%v, want %v", got, want)
+		}
+
+		// Ensure unknown sort options are rejected.
+		if _, _, err := s.FindDials(ctx0, wtf.DialFilter{SortBy: "bad"}); wtf.ErrorCode(err) != wtf.EINVALID {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure invalid cursors are rejected.
+	t.Run("ErrInvalidCursor", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "john"})
+
+		s := sqlite.NewDialService(db)
+		if _, _, err := s.FindDials(ctx0, wtf.DialFilter{After: "!!!"}); wtf.ErrorCode(err) != wtf.EINVALID {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if _, _, err := s.FindDials(ctx0, wtf.DialFilter{After: wtf.Cursor{Keys: []interface{}{"x"}, ID: 1}.String()}); wtf.ErrorCode(err) != wtf.EINVALID {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if _, _, err := s.FindDials(ctx0, wtf.DialFilter{After: wtf.Cursor{ID: 1}.String(), Before: wtf.Cursor{ID: 2}.String()}); wtf.ErrorCode(err) != wtf.EINVALID {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
 }
 
 func TestDialService_DeleteDial(t *testing.T) {
@@ -257,13 +529,221 @@ func TestDialService_AverageDialValueReport(t *testing.T) {
 		// Generate hourly report.
 		start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
 		end := time.Date(2000, time.January, 1, 5, 0, 0, 0, time.UTC)
-		report, err := s.AverageDialValueReport(ctx0, start, end, time.Hour)
+		report, err := s.AverageDialValueReport(ctx0, start, end, wtf.ReportInterval{Duration: time.Hour})
 		if err != nil {
 			t.Fatal(err)
-		} else if got, want := report.Records[0], (&wtf.DialValueRecord{Value: 0, Timestamp: time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)}); !reflect.DeepEqual(got, want) {
+		} else if got, want := report.Records[0], (&wtf.DialValueRecord{Value: 0, Timestamp: time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC), Count: 1}); !reflect.DeepEqual(got, want) {
 			t.Fatalf("[]=%#v, want %#v", got, want)
 		}
 	})
+
+	// Ensure statistics combine the values of every dial within each slot.
+	t.Run("Stats", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		db.Now = func() time.Time { return time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC) }
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane"})
+		MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL0"})
+		MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL1"})
+		membership0 := MustFindDialMembershipByID(t, ctx0, db, 1)
+		membership1 := MustFindDialMembershipByID(t, ctx0, db, 2)
+
+		MustSetDialMembershipValueAt(t, ctx0, db, membership0.ID, 10, time.Date(2000, time.January, 1, 0, 10, 0, 0, time.UTC))
+		MustSetDialMembershipValueAt(t, ctx0, db, membership1.ID, 20, time.Date(2000, time.January, 1, 0, 20, 0, 0, time.UTC))
+		MustSetDialMembershipValueAt(t, ctx0, db, membership0.ID, 60, time.Date(2000, time.January, 1, 0, 30, 0, 0, time.UTC))
+
+		// Hourly slots are read from rollups so the median & standard
+		// deviation are computed from each dial's hourly average.
+		start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		report, err := s.AverageDialValueReport(ctx0, start, start.Add(2*time.Hour), wtf.ReportInterval{Duration: time.Hour})
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := report.Records[0], (&wtf.DialValueRecord{
+			Value: 40, Timestamp: start, Min: 0, Max: 60, Median: 23.33, StdDev: 6.53, Count: 5, Members: 1,
+		}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("[0]=%#v, want %#v", got, want)
+		} else if got, want := report.Records[1], (&wtf.DialValueRecord{
+			Value: 40, Timestamp: start.Add(time.Hour), Min: 20, Max: 60, Median: 40, StdDev: 20, Count: 2,
+		}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("[1]=%#v, want %#v", got, want)
+		}
+	})
+}
+
+func TestDialService_DialValueReport(t *testing.T) {
+	// Ensure each member who set a value within a slot is counted once,
+	// including members who have since left the dial.
+	t.Run("Members", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		db.Now = func() time.Time { return start }
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "john"})
+		_, ctx2 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jill"})
+		dial0 := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL0"})
+		membership1 := MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial0.ID})
+		MustCreateDialMembership(t, ctx2, db, &wtf.DialMembership{DialID: dial0.ID})
+
+		// Set values within the first 15 minutes. The member who leaves is
+		// still counted & the member who never sets a value is not.
+		MustSetDialMembershipValueAt(t, ctx0, db, 1, 10, start.Add(1*time.Minute))
+		MustSetDialMembershipValueAt(t, ctx0, db, 1, 20, start.Add(2*time.Minute))
+		MustSetDialMembershipValueAt(t, ctx1, db, membership1.ID, 30, start.Add(3*time.Minute))
+		if err := sqlite.NewDialMembershipService(db).DeleteDialMembership(ctx1, membership1.ID); err != nil {
+			t.Fatal(err)
+		}
+		MustSetDialMembershipValueAt(t, ctx0, db, 1, 40, start.Add(20*time.Minute))
+
+		report, err := s.DialValueReport(ctx0, dial0.ID, start, start.Add(45*time.Minute), wtf.ReportInterval{Duration: 15 * time.Minute})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var members []int
+		for _, record := range report.Records {
+			members = append(members, record.Members)
+		}
+		if got, want := members, []int{2, 1, 0}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("members=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure we can compute the value of a single dial across time.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		db.Now = func() time.Time {
+			return time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		}
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "joe"})
+
+		dial0 := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL0"})
+		membership0 := MustFindDialMembershipByID(t, ctx0, db, 1)
+		MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial0.ID})
+
+		// Update value after two hours (avg 50).
+		db.Now = func() time.Time {
+			return time.Date(2000, time.January, 1, 2, 0, 0, 0, time.UTC)
+		}
+		MustSetDialMembershipValue(t, ctx0, db, membership0.ID, 100)
+
+		// Generate hourly report.
+		start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		end := time.Date(2000, time.January, 1, 4, 0, 0, 0, time.UTC)
+		report, err := s.DialValueReport(ctx1, dial0.ID, start, end, wtf.ReportInterval{Duration: time.Hour})
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := len(report.Records), 4; got != want {
+			t.Fatalf("len=%v, want %v", got, want)
+		} else if got, want := report.Records[1], (&wtf.DialValueRecord{Value: 0, Timestamp: time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC), Count: 1}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("[1]=%#v, want %#v", got, want)
+		} else if got, want := report.Records[2].Members, 1; got != want {
+			t.Fatalf("[2].Members=%v, want %v", got, want)
+		} else if got, want := report.Records[3], (&wtf.DialValueRecord{Value: 50, Timestamp: time.Date(2000, time.January, 1, 3, 0, 0, 0, time.UTC), Min: 50, Max: 50, Median: 50, Count: 1}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("[3]=%#v, want %#v", got, want)
+		}
+	})
+
+	// Ensure daily slots are aligned to midnight in the interval's time zone
+	// across a daylight saving time change.
+	t.Run("Day", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		loc, err := time.LoadLocation("America/New_York")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		db.Now = func() time.Time { return time.Date(2021, time.March, 13, 0, 0, 0, 0, time.UTC) }
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane"})
+		dial0 := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL0"})
+		membership0 := MustFindDialMembershipByID(t, ctx0, db, 1)
+
+		// Set values shortly before & after local midnight. The clocks move
+		// forward on March 14th so the offset changes from -5h to -4h.
+		MustSetDialMembershipValueAt(t, ctx0, db, membership0.ID, 10, time.Date(2021, time.March, 13, 23, 30, 0, 0, loc))
+		MustSetDialMembershipValueAt(t, ctx0, db, membership0.ID, 20, time.Date(2021, time.March, 14, 23, 30, 0, 0, loc))
+		MustSetDialMembershipValueAt(t, ctx0, db, membership0.ID, 30, time.Date(2021, time.March, 15, 0, 30, 0, 0, loc))
+
+		start := time.Date(2021, time.March, 13, 12, 0, 0, 0, loc)
+		end := time.Date(2021, time.March, 16, 12, 0, 0, 0, loc)
+		if report, err := s.DialValueReport(ctx0, dial0.ID, start, end, wtf.ReportInterval{Unit: wtf.ReportUnitDay, Location: loc}); err != nil {
+			t.Fatal(err)
+		} else if got, want := reportValues(report), []int{10, 20, 30}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("values=%v, want %v", got, want)
+		} else if got, want := report.Records[1].Timestamp, time.Date(2021, time.March, 14, 5, 0, 0, 0, time.UTC); !got.Equal(want) {
+			t.Fatalf("[1].Timestamp=%s, want %s", got, want)
+		} else if got, want := report.Records[2].Timestamp, time.Date(2021, time.March, 15, 4, 0, 0, 0, time.UTC); !got.Equal(want) {
+			t.Fatalf("[2].Timestamp=%s, want %s", got, want)
+		}
+	})
+
+	// Ensure monthly slots are aligned to the first of each month.
+	t.Run("Month", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		db.Now = func() time.Time { return time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC) }
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane"})
+		dial0 := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL0"})
+		membership0 := MustFindDialMembershipByID(t, ctx0, db, 1)
+		MustSetDialMembershipValueAt(t, ctx0, db, membership0.ID, 10, time.Date(2000, time.January, 15, 0, 0, 0, 0, time.UTC))
+		MustSetDialMembershipValueAt(t, ctx0, db, membership0.ID, 40, time.Date(2000, time.March, 1, 0, 0, 0, 0, time.UTC))
+
+		start := time.Date(2000, time.January, 20, 0, 0, 0, 0, time.UTC)
+		end := time.Date(2000, time.April, 1, 0, 0, 0, 0, time.UTC)
+		if report, err := s.DialValueReport(ctx0, dial0.ID, start, end, wtf.ReportInterval{Unit: wtf.ReportUnitMonth}); err != nil {
+			t.Fatal(err)
+		} else if got, want := reportValues(report), []int{10, 10, 40}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("values=%v, want %v", got, want)
+		} else if got, want := report.Records[1].Timestamp, time.Date(2000, time.February, 1, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+			t.Fatalf("[1].Timestamp=%s, want %s", got, want)
+		}
+	})
+
+	// Ensure an error is returned for an invalid interval.
+	t.Run("ErrInvalidInterval", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane"})
+		dial0 := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL0"})
+
+		start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		if _, err := s.DialValueReport(ctx0, dial0.ID, start, start.Add(time.Hour), wtf.ReportInterval{Unit: "fortnight"}); wtf.ErrorCode(err) != wtf.EINVALID {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure a user cannot view the report of a dial they are not a member of.
+	t.Run("ErrNotFound", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "joe"})
+		dial0 := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL0"})
+
+		start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		if _, err := s.DialValueReport(ctx1, dial0.ID, start, start.Add(time.Hour), wtf.ReportInterval{Duration: time.Minute}); wtf.ErrorCode(err) != wtf.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
 }
 
 // MustFindDialByID finds a dial by ID. Fatal on error.
@@ -284,3 +764,22 @@ func MustCreateDial(tb testing.TB, ctx context.Context, db *sqlite.DB, dial *wtf
 	}
 	return dial
 }
+
+// dialNames returns the names of a list of dials.
+func dialNames(a []*wtf.Dial) []string {
+	names := make([]string, len(a))
+	for i := range a {
+		names[i] = a[i].Name
+	}
+	return names
+}
+
+// stringPtr returns a pointer to v.
+func stringPtr(v string) *string {
+	return &v
+}
+
+// timePtr returns a pointer to v.
+func timePtr(v time.Time) *time.Time {
+	return &v
+}
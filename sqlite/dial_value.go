@@ -0,0 +1,681 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// RetentionInterval is the time between checks for expired dial values.
+const RetentionInterval = 1 * time.Hour
+
+// dialValueTable represents a table storing historical dial values at a given
+// resolution. Raw values are stored per-minute and are rolled up into hourly
+// & daily summary tables as they are written.
+type dialValueTable struct {
+	name        string        // table name
+	valueColumn string        // column holding the value at end of each row's period
+	resolution  time.Duration // period covered by each row
+}
+
+// Tables used for storing dial value history, from finest to coarsest.
+var (
+	dialValuesRaw    = dialValueTable{name: "dial_values", valueColumn: "value", resolution: time.Minute}
+	dialValuesHourly = dialValueTable{name: "dial_values_hourly", valueColumn: "last_value", resolution: time.Hour}
+	dialValuesDaily  = dialValueTable{name: "dial_values_daily", valueColumn: "last_value", resolution: 24 * time.Hour}
+)
+
+// dialValueTableFor returns the coarsest table that can answer a report with
+// the given slot boundaries. A rollup table can only be used when every
+// boundary is aligned to its resolution so that every row fits within one
+// slot. For example, daily slots in a time zone other than UTC are read from
+// the hourly table, or from raw values if the zone has a fractional offset.
+func dialValueTableFor(slots []time.Time) dialValueTable {
+	for _, table := range []dialValueTable{dialValuesDaily, dialValuesHourly} {
+		if slotsAligned(slots, table.resolution) {
+			return table
+		}
+	}
+	return dialValuesRaw
+}
+
+// slotsAligned returns true if every slot boundary is a multiple of d.
+func slotsAligned(slots []time.Time, d time.Duration) bool {
+	for _, t := range slots {
+		if !t.Truncate(d).Equal(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// DialValueRollup represents a summary of dial values recorded within a period.
+type DialValueRollup struct {
+	Timestamp time.Time // start of period
+	Min       int
+	Max       int
+	Avg       int
+	Count     int
+	Last      int // most recent value within the period
+}
+
+// DialValueRollups returns hourly or daily summaries of the values for a dial.
+// The resolution must be either one hour or one day. This is only used for testing.
+func (s *DialService) DialValueRollups(ctx context.Context, id int, resolution time.Duration) ([]*DialValueRollup, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var table dialValueTable
+	switch resolution {
+	case dialValuesHourly.resolution:
+		table = dialValuesHourly
+	case dialValuesDaily.resolution:
+		table = dialValuesDaily
+	default:
+		return nil, fmt.Errorf("invalid rollup resolution: %s", resolution)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT "timestamp", min_value, max_value, CAST(ROUND(CAST(sum_value AS REAL) / count) AS INTEGER), count, last_value
+		FROM `+table.name+`
+		WHERE dial_id = ?
+		ORDER BY "timestamp"
+	`, id)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer rows.Close()
+
+	var a []*DialValueRollup
+	for rows.Next() {
+		var r DialValueRollup
+		if err := rows.Scan((*NullTime)(&r.Timestamp), &r.Min, &r.Max, &r.Avg, &r.Count, &r.Last); err != nil {
+			return nil, FormatError(err)
+		}
+		a = append(a, &r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, FormatError(err)
+	}
+	return a, nil
+}
+
+// PruneDialValues removes raw per-minute dial values which are older than
+// the retention window. Values remain available in the hourly & daily
+// rollup tables. Returns the number of rows removed. No values are removed
+// if retention is disabled.
+func (db *DB) PruneDialValues(ctx context.Context) (int, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	cutoff := rawDialValueCutoff(tx)
+	if cutoff.IsZero() {
+		return 0, nil
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM dial_values WHERE "timestamp" < ?`, (*NullTime)(&cutoff))
+	if err != nil {
+		return 0, FormatError(err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	// Members are still counted from their hourly rows after this.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM dial_value_members WHERE "timestamp" < ?`, (*NullTime)(&cutoff)); err != nil {
+		return 0, FormatError(err)
+	}
+	return int(n), tx.Commit()
+}
+
+// monitorRetention runs in a goroutine and periodically removes expired raw dial values.
+func (db *DB) monitorRetention() {
+	ticker := time.NewTicker(RetentionInterval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := db.PruneDialValues(db.ctx); err != nil {
+			log.Printf("dial value retention error: %s", err)
+		} else if n > 0 {
+			log.Printf("dial value retention: removed=%d", n)
+		}
+
+		select {
+		case <-db.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// rawDialValueCutoff returns the time before which raw dial values are
+// removed. The cutoff is aligned to the hour so that hourly rollups are only
+// ever computed from a complete set of raw values. Returns a zero time if
+// retention is disabled.
+func rawDialValueCutoff(tx *Tx) time.Time {
+	if tx.db.RawRetention <= 0 {
+		return time.Time{}
+	}
+	return tx.now.Add(-tx.db.RawRetention).Truncate(time.Hour)
+}
+
+// refreshDialValueRollups updates the hourly & daily rollups containing the
+// given timestamp after a raw value has been written.
+//
+// If raw values for the hour are still retained then the hourly rollup is
+// recomputed from them so that overwritten values are not double counted.
+// Otherwise, such as when importing old data, the value is merged into the
+// existing rollup. The daily rollup is always recomputed from its hours.
+func refreshDialValueRollups(ctx context.Context, tx *Tx, id, value int, timestamp time.Time) error {
+	hour := timestamp.UTC().Truncate(time.Hour)
+	if hour.Before(rawDialValueCutoff(tx)) {
+		if err := mergeDialValueHourly(ctx, tx, id, value, hour, timestamp); err != nil {
+			return fmt.Errorf("merge hourly: %w", err)
+		}
+	} else if err := refreshDialValueHourly(ctx, tx, id, hour); err != nil {
+		return fmt.Errorf("refresh hourly: %w", err)
+	}
+
+	if err := refreshDialValueDaily(ctx, tx, id, hour.Truncate(24*time.Hour)); err != nil {
+		return fmt.Errorf("refresh daily: %w", err)
+	}
+	return nil
+}
+
+// refreshDialValueHourly recomputes a single hourly rollup from raw values.
+func refreshDialValueHourly(ctx context.Context, tx *Tx, id int, hour time.Time) error {
+	end := hour.Add(time.Hour)
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO dial_values_hourly (dial_id, "timestamp", min_value, max_value, sum_value, count, last_value, last_timestamp)
+		SELECT ?, ?, MIN(value), MAX(value), SUM(value), COUNT(*),
+		       (SELECT value FROM dial_values WHERE dial_id = ? AND "timestamp" >= ? AND "timestamp" < ? ORDER BY "timestamp" DESC LIMIT 1),
+		       MAX("timestamp")
+		FROM dial_values
+		WHERE dial_id = ? AND "timestamp" >= ? AND "timestamp" < ?
+		ON CONFLICT (dial_id, "timestamp") DO UPDATE SET
+		    min_value = excluded.min_value,
+		    max_value = excluded.max_value,
+		    sum_value = excluded.sum_value,
+		    count = excluded.count,
+		    last_value = excluded.last_value,
+		    last_timestamp = excluded.last_timestamp
+	`,
+		id, (*NullTime)(&hour),
+		id, (*NullTime)(&hour), (*NullTime)(&end),
+		id, (*NullTime)(&hour), (*NullTime)(&end),
+	); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
+
+// mergeDialValueHourly adds a single value to an hourly rollup.
+func mergeDialValueHourly(ctx context.Context, tx *Tx, id, value int, hour, timestamp time.Time) error {
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO dial_values_hourly (dial_id, "timestamp", min_value, max_value, sum_value, count, last_value, last_timestamp)
+		VALUES (?, ?, ?, ?, ?, 1, ?, ?)
+		ON CONFLICT (dial_id, "timestamp") DO UPDATE SET
+		    min_value = MIN(min_value, excluded.min_value),
+		    max_value = MAX(max_value, excluded.max_value),
+		    sum_value = sum_value + excluded.sum_value,
+		    count = count + 1,
+		    last_value = CASE WHEN excluded.last_timestamp >= last_timestamp THEN excluded.last_value ELSE last_value END,
+		    last_timestamp = MAX(last_timestamp, excluded.last_timestamp)
+	`,
+		id, (*NullTime)(&hour), value, value, value, value, (*NullTime)(&timestamp),
+	); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
+
+// refreshDialValueDaily recomputes a single daily rollup from hourly rollups.
+func refreshDialValueDaily(ctx context.Context, tx *Tx, id int, day time.Time) error {
+	end := day.Add(24 * time.Hour)
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO dial_values_daily (dial_id, "timestamp", min_value, max_value, sum_value, count, last_value, last_timestamp)
+		SELECT ?, ?, MIN(min_value), MAX(max_value), SUM(sum_value), SUM(count),
+		       (SELECT last_value FROM dial_values_hourly WHERE dial_id = ? AND "timestamp" >= ? AND "timestamp" < ? ORDER BY "timestamp" DESC LIMIT 1),
+		       MAX(last_timestamp)
+		FROM dial_values_hourly
+		WHERE dial_id = ? AND "timestamp" >= ? AND "timestamp" < ?
+		ON CONFLICT (dial_id, "timestamp") DO UPDATE SET
+		    min_value = excluded.min_value,
+		    max_value = excluded.max_value,
+		    sum_value = excluded.sum_value,
+		    count = excluded.count,
+		    last_value = excluded.last_value,
+		    last_timestamp = excluded.last_timestamp
+	`,
+		id, (*NullTime)(&day),
+		id, (*NullTime)(&day), (*NullTime)(&end),
+		id, (*NullTime)(&day), (*NullTime)(&end),
+	); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
+
+// insertDialValueMember records that a member set their value on a dial.
+// Members are stored per minute, like raw values, and per hour so they can
+// still be counted once raw history is removed by retention.
+func insertDialValueMember(ctx context.Context, tx *Tx, dialID, userID int, timestamp time.Time) error {
+	minute := timestamp.UTC().Truncate(time.Minute)
+	hour := minute.Truncate(time.Hour)
+	for _, arg := range []struct {
+		table     string
+		timestamp time.Time
+	}{
+		{"dial_value_members", minute},
+		{"dial_value_members_hourly", hour},
+	} {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO `+arg.table+` (dial_id, user_id, "timestamp")
+			VALUES (?, ?, ?)
+			ON CONFLICT DO NOTHING
+		`,
+			dialID, userID, (*NullTime)(&arg.timestamp),
+		); err != nil {
+			return FormatError(err)
+		}
+	}
+	return nil
+}
+
+// findDialValuesBefore returns the most recent value of each dial recorded
+// in the table before t, keyed by dial ID. Raw values fall back to the hourly
+// rollups in case older values have been removed by retention. Dials without
+// a value are not included in the map.
+func findDialValuesBefore(ctx context.Context, tx *Tx, ids []int, table dialValueTable, t time.Time) (map[int]int, error) {
+	// Rollup rows cover a whole period so only rows ending by t are used.
+	// Raw rows are single points in time so a row at t is included.
+	//
+	// SQLite returns the bare value column from the row matching MAX().
+	op := "<"
+	if table == dialValuesRaw {
+		op = "<="
+	}
+	m, err := queryDialValuesBefore(ctx, tx, `
+		SELECT dial_id, `+table.valueColumn+`, MAX("timestamp")
+		FROM `+table.name+`
+		WHERE dial_id IN `+formatInClause(len(ids))+`
+		  AND "timestamp" `+op+` ?
+		GROUP BY dial_id
+	`, ids, t)
+	if err != nil || table != dialValuesRaw {
+		return m, err
+	}
+
+	// Look up dials which have no retained raw values before t.
+	var missing []int
+	for _, id := range ids {
+		if _, ok := m[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) == 0 {
+		return m, nil
+	}
+
+	other, err := queryDialValuesBefore(ctx, tx, `
+		SELECT dial_id, last_value, MAX("timestamp")
+		FROM dial_values_hourly
+		WHERE dial_id IN `+formatInClause(len(missing))+`
+		  AND last_timestamp <= ?
+		GROUP BY dial_id
+	`, missing, t)
+	if err != nil {
+		return nil, err
+	}
+	for id, value := range other {
+		m[id] = value
+	}
+	return m, nil
+}
+
+// queryDialValuesBefore executes a query returning one value per dial.
+func queryDialValuesBefore(ctx context.Context, tx *Tx, query string, ids []int, t time.Time) (map[int]int, error) {
+	rows, err := tx.QueryContext(ctx, query, append(intArgs(ids), (*NullTime)(&t))...)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+	defer rows.Close()
+
+	m := make(map[int]int, len(ids))
+	for rows.Next() {
+		var id, value int
+		var timestamp string
+		if err := rows.Scan(&id, &value, &timestamp); err != nil {
+			return nil, err
+		}
+		m[id] = value
+	}
+	return m, rows.Err()
+}
+
+// dialValueStats accumulates the values observed within a report slot.
+type dialValueStats struct {
+	min, max int
+	samples  []dialValueSample
+
+	// Number of distinct members who set a value within the slot.
+	members int
+}
+
+// dialValueSample represents a value observed within a slot. Hourly rollups
+// are added as their average value weighted by the number of values in them.
+type dialValueSample struct {
+	value  float64
+	weight int
+}
+
+// add adds a sample along with the range of the values it summarizes.
+func (s *dialValueStats) add(value float64, weight, min, max int) {
+	if len(s.samples) == 0 || min < s.min {
+		s.min = min
+	}
+	if len(s.samples) == 0 || max > s.max {
+		s.max = max
+	}
+	s.samples = append(s.samples, dialValueSample{value: value, weight: weight})
+}
+
+// apply sets the statistics of the slot on record. The median & standard
+// deviation are rounded to two decimal places.
+func (s *dialValueStats) apply(record *wtf.DialValueRecord) {
+	record.Min, record.Max, record.Members = s.min, s.max, s.members
+
+	var sum float64
+	for _, sample := range s.samples {
+		record.Count += sample.weight
+		sum += sample.value * float64(sample.weight)
+	}
+	if record.Count == 0 {
+		return
+	}
+
+	// Compute the population standard deviation around the weighted mean.
+	mean := sum / float64(record.Count)
+	var variance float64
+	for _, sample := range s.samples {
+		variance += float64(sample.weight) * (sample.value - mean) * (sample.value - mean)
+	}
+	record.StdDev = roundStat(math.Sqrt(variance / float64(record.Count)))
+
+	// The median is the middle value if every sample is expanded by its
+	// weight. The two middle values are averaged for an even count.
+	sort.Slice(s.samples, func(i, j int) bool { return s.samples[i].value < s.samples[j].value })
+	record.Median = roundStat((s.at((record.Count-1)/2) + s.at(record.Count/2)) / 2)
+}
+
+// at returns the value at index i if every sample is expanded by its weight.
+func (s *dialValueStats) at(i int) float64 {
+	for _, sample := range s.samples {
+		if i < sample.weight {
+			return sample.value
+		}
+		i -= sample.weight
+	}
+	return 0
+}
+
+// roundStat rounds a statistic to two decimal places.
+func roundStat(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// dialValueRow represents a raw value or a rollup read for a report.
+type dialValueRow struct {
+	dialID    int
+	timestamp time.Time
+	avg       float64
+	count     int
+	min, max  int
+	last      int
+
+	// True if a raw value was recorded at the very start of the row's period.
+	// Always true for raw values.
+	atStart bool
+}
+
+// findDialsValueSlots returns the value of each dial at the end of each slot,
+// keyed by dial ID, along with statistics of the values observed within each
+// slot combined across all dials. Slots are given as a list of boundaries
+// where each slot ends at the next boundary so that slots may vary in length.
+//
+// Values are read from the coarsest table which supports the slots so that
+// long reports read hourly or daily rollups instead of every raw value. Each
+// rollup is added to the statistics as its average weighted by its count.
+// All dials are read with a fixed number of queries per batch of dials.
+func findDialsValueSlots(ctx context.Context, tx *Tx, ids []int, slots []time.Time) (map[int][]int, []*dialValueStats, error) {
+	slotN := len(slots) - 1
+	valuesByID := make(map[int][]int, len(ids))
+	stats := make([]*dialValueStats, slotN)
+	for i := range stats {
+		stats[i] = &dialValueStats{}
+	}
+	if slotN == 0 {
+		return valuesByID, stats, nil
+	}
+
+	start, end := slots[0], slots[slotN]
+	table := dialValueTableFor(slots)
+	members := make([]map[int]struct{}, slotN)
+	for i := range members {
+		members[i] = make(map[int]struct{})
+	}
+	if err := batchIDs(ids, func(ids []int) error {
+		// Determine the value of each dial at the start of the report.
+		current, err := findDialValuesBefore(ctx, tx, ids, table, start)
+		if err != nil {
+			return err
+		}
+
+		rowsByID, err := findDialValueRows(ctx, tx, ids, table, start, end)
+		if err != nil {
+			return err
+		}
+
+		// Walk each dial's rows in order & add them to their slots. The value
+		// carried over from the previous slot is included unless a new value
+		// was recorded at the very start of the slot. The last value within a
+		// slot is its value.
+		for _, id := range ids {
+			value, rows := current[id], rowsByID[id]
+			values := make([]int, slotN)
+			for i, j := 0, 0; i < slotN; i++ {
+				if j >= len(rows) || !rows[j].atStart || !rows[j].timestamp.Equal(slots[i]) {
+					stats[i].add(float64(value), 1, value, value)
+				}
+				for ; j < len(rows) && rows[j].timestamp.Before(slots[i+1]); j++ {
+					stats[i].add(rows[j].avg, rows[j].count, rows[j].min, rows[j].max)
+					value = rows[j].last
+				}
+				values[i] = value
+			}
+			valuesByID[id] = values
+		}
+
+		// Add the members who set a value to each slot. Members are counted
+		// once per slot even if they set values on several dials.
+		return findDialValueMembers(ctx, tx, members, ids, table, slots)
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	for i := range stats {
+		stats[i].members = len(members[i])
+	}
+	return valuesByID, stats, nil
+}
+
+// findDialValueMembers adds the ID of each member who set a value on the
+// dials to the set of the slot containing the time it was set. Hourly rows
+// are read if the report uses rollups & for the period before the raw
+// retention cutoff, in which case each hour is added to the slot containing
+// its start.
+func findDialValueMembers(ctx context.Context, tx *Tx, members []map[int]struct{}, ids []int, table dialValueTable, slots []time.Time) error {
+	start, end := slots[0], slots[len(slots)-1]
+
+	hourlyEnd := start
+	if table != dialValuesRaw {
+		hourlyEnd = end
+	} else if cutoff := rawDialValueCutoff(tx); cutoff.After(start) {
+		hourlyEnd = cutoff
+		if end.Before(hourlyEnd) {
+			hourlyEnd = end
+		}
+	}
+
+	if hourlyEnd.After(start) {
+		if err := queryDialValueMembers(ctx, tx, members, "dial_value_members_hourly", ids, slots, start, hourlyEnd); err != nil {
+			return fmt.Errorf("hourly members: %w", err)
+		}
+	}
+	if end.After(hourlyEnd) {
+		if err := queryDialValueMembers(ctx, tx, members, "dial_value_members", ids, slots, hourlyEnd, end); err != nil {
+			return fmt.Errorf("members: %w", err)
+		}
+	}
+	return nil
+}
+
+// queryDialValueMembers reads members from a table between start & end and
+// adds them to the set of their slot.
+func queryDialValueMembers(ctx context.Context, tx *Tx, members []map[int]struct{}, table string, ids []int, slots []time.Time, start, end time.Time) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT DISTINCT user_id, "timestamp"
+		FROM `+table+`
+		WHERE dial_id IN `+formatInClause(len(ids))+`
+		  AND "timestamp" >= ?
+		  AND "timestamp" < ?
+	`,
+		append(intArgs(ids), (*NullTime)(&start), (*NullTime)(&end))...,
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID int
+		var timestamp time.Time
+		if err := rows.Scan(&userID, (*NullTime)(&timestamp)); err != nil {
+			return err
+		}
+		i := sort.Search(len(slots), func(k int) bool { return slots[k].After(timestamp) }) - 1
+		members[i][userID] = struct{}{}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+// findDialValueRows returns the rows of each dial between start & end in
+// chronological order, keyed by dial ID. Rollup tables are read directly.
+// For raw values, hourly rollups are returned for the period before the raw
+// retention cutoff & raw values after it.
+//
+// Rollups do not store when their first value was recorded so the raw value
+// at the start of each period is looked up by primary key. If it has been
+// removed by retention then the period is assumed to start with a value so
+// the carried value is not counted twice.
+func findDialValueRows(ctx context.Context, tx *Tx, ids []int, table dialValueTable, start, end time.Time) (map[int][]*dialValueRow, error) {
+	m := make(map[int][]*dialValueRow, len(ids))
+
+	if table != dialValuesRaw {
+		if err := queryDialValueRows(ctx, tx, m, `
+			SELECT dial_id, "timestamp", CAST(sum_value AS REAL) / count, count, min_value, max_value, last_value,
+			       EXISTS (SELECT 1 FROM dial_values v WHERE v.dial_id = r.dial_id AND v."timestamp" = r."timestamp")
+			FROM `+table.name+` r
+			WHERE dial_id IN `+formatInClause(len(ids))+`
+			  AND "timestamp" >= ?
+			  AND "timestamp" < ?
+			ORDER BY dial_id ASC, "timestamp" ASC
+		`, ids, start, end); err != nil {
+			return nil, fmt.Errorf("%s: %w", table.name, err)
+		}
+		markPrunedDialValueRows(m, rawDialValueCutoff(tx))
+		return m, nil
+	}
+
+	// Rollups are always older than raw values so they are read first.
+	if cutoff := rawDialValueCutoff(tx); cutoff.After(start) {
+		hourlyEnd := end
+		if cutoff.Before(hourlyEnd) {
+			hourlyEnd = cutoff
+		}
+		if err := queryDialValueRows(ctx, tx, m, `
+			SELECT dial_id, "timestamp", CAST(sum_value AS REAL) / count, count, min_value, max_value, last_value,
+			       EXISTS (SELECT 1 FROM dial_values v WHERE v.dial_id = r.dial_id AND v."timestamp" = r."timestamp")
+			FROM dial_values_hourly r
+			WHERE dial_id IN `+formatInClause(len(ids))+`
+			  AND "timestamp" >= ?
+			  AND "timestamp" < ?
+			ORDER BY dial_id ASC, "timestamp" ASC
+		`, ids, start, hourlyEnd); err != nil {
+			return nil, fmt.Errorf("hourly: %w", err)
+		}
+		markPrunedDialValueRows(m, cutoff)
+		start = cutoff
+	}
+
+	if err := queryDialValueRows(ctx, tx, m, `
+		SELECT dial_id, "timestamp", value, 1, value, value, value, 1
+		FROM dial_values
+		WHERE dial_id IN `+formatInClause(len(ids))+`
+		  AND "timestamp" >= ?
+		  AND "timestamp" < ?
+		ORDER BY dial_id ASC, "timestamp" ASC
+	`, ids, start, end); err != nil {
+		return nil, fmt.Errorf("raw: %w", err)
+	}
+	return m, nil
+}
+
+// markPrunedDialValueRows marks rows before the raw retention cutoff as
+// starting with a value since their raw values can no longer be checked.
+func markPrunedDialValueRows(m map[int][]*dialValueRow, cutoff time.Time) {
+	for _, rows := range m {
+		for _, row := range rows {
+			if row.timestamp.Before(cutoff) {
+				row.atStart = true
+			}
+		}
+	}
+}
+
+// queryDialValueRows executes a query for value rows between start & end and
+// appends the results to m.
+func queryDialValueRows(ctx context.Context, tx *Tx, m map[int][]*dialValueRow, query string, ids []int, start, end time.Time) error {
+	rows, err := tx.QueryContext(ctx, query, append(intArgs(ids), (*NullTime)(&start), (*NullTime)(&end))...)
+	if err != nil {
+		return FormatError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row dialValueRow
+		if err := rows.Scan(&row.dialID, (*NullTime)(&row.timestamp), &row.avg, &row.count, &row.min, &row.max, &row.last, &row.atStart); err != nil {
+			return err
+		}
+		m[row.dialID] = append(m[row.dialID], &row)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return rows.Close()
+}
@@ -0,0 +1,136 @@
+package sqlite_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/sqlite"
+)
+
+func TestDialService_DialValueRollups(t *testing.T) {
+	// Ensure hourly & daily rollups are maintained as values are written.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewDialService(db)
+
+		db.Now = func() time.Time { return time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC) }
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane"})
+		dial0 := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL0"})
+		membership0 := MustFindDialMembershipByID(t, ctx0, db, 1)
+
+		// Write values within the first hour. The value at 00:20 is
+		// overwritten within the same minute so it should only count once.
+		MustSetDialMembershipValueAt(t, ctx0, db, membership0.ID, 20, time.Date(2000, time.January, 1, 0, 10, 0, 0, time.UTC))
+		MustSetDialMembershipValueAt(t, ctx0, db, membership0.ID, 40, time.Date(2000, time.January, 1, 0, 20, 0, 0, time.UTC))
+		MustSetDialMembershipValueAt(t, ctx0, db, membership0.ID, 80, time.Date(2000, time.January, 1, 0, 20, 30, 0, time.UTC))
+
+		// Write a value in the next hour.
+		MustSetDialMembershipValueAt(t, ctx0, db, membership0.ID, 60, time.Date(2000, time.January, 1, 1, 5, 0, 0, time.UTC))
+
+		if rollups, err := s.DialValueRollups(ctx0, dial0.ID, time.Hour); err != nil {
+			t.Fatal(err)
+		} else if got, want := rollups, []*sqlite.DialValueRollup{
+			{Timestamp: time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC), Min: 0, Max: 80, Avg: 33, Count: 3, Last: 80},
+			{Timestamp: time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC), Min: 60, Max: 60, Avg: 60, Count: 1, Last: 60},
+		}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("hourly=%#v, want %#v", got, want)
+		}
+
+		if rollups, err := s.DialValueRollups(ctx0, dial0.ID, 24*time.Hour); err != nil {
+			t.Fatal(err)
+		} else if got, want := rollups, []*sqlite.DialValueRollup{
+			{Timestamp: time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC), Min: 0, Max: 80, Avg: 40, Count: 4, Last: 60},
+		}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("daily=%#v, want %#v", got, want)
+		}
+	})
+}
+
+func TestDB_PruneDialValues(t *testing.T) {
+	// Ensure expired raw values are removed & reports fall back to rollups.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		db.RawRetention = 24 * time.Hour
+		s := sqlite.NewDialService(db)
+
+		db.Now = func() time.Time { return time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC) }
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane"})
+		dial0 := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL0"})
+		membership0 := MustFindDialMembershipByID(t, ctx0, db, 1)
+		MustSetDialMembershipValueAt(t, ctx0, db, membership0.ID, 30, time.Date(2000, time.January, 1, 2, 0, 0, 0, time.UTC))
+		MustSetDialMembershipValueAt(t, ctx0, db, membership0.ID, 70, time.Date(2000, time.January, 2, 2, 0, 0, 0, time.UTC))
+
+		// Remove values older than one day.
+		db.Now = func() time.Time { return time.Date(2000, time.January, 3, 0, 0, 0, 0, time.UTC) }
+		if n, err := db.PruneDialValues(context.Background()); err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 2; got != want {
+			t.Fatalf("n=%v, want %v", got, want)
+		}
+
+		if values, err := s.DialValues(ctx0, dial0.ID); err != nil {
+			t.Fatal(err)
+		} else if got, want := values, []int{70}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("values=%v, want %v", got, want)
+		}
+
+		// Ensure the daily report is still available from rollups. The
+		// standard deviation is computed from the daily averages.
+		start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+		if report, err := s.DialValueReport(ctx0, dial0.ID, start, start.Add(72*time.Hour), wtf.ReportInterval{Duration: 24 * time.Hour}); err != nil {
+			t.Fatal(err)
+		} else if got, want := reportValues(report), []int{30, 70, 70}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("values=%v, want %v", got, want)
+		} else if got, want := report.Records[0], (&wtf.DialValueRecord{
+			Value: 30, Timestamp: start, Min: 0, Max: 30, Median: 15, StdDev: 0, Count: 2, Members: 1,
+		}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("[0]=%#v, want %#v", got, want)
+		} else if got, want := report.Records[1], (&wtf.DialValueRecord{
+			Value: 70, Timestamp: start.Add(24 * time.Hour), Min: 30, Max: 70, Median: 50, StdDev: 20, Count: 2, Members: 1,
+		}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("[1]=%#v, want %#v", got, want)
+		}
+
+		// Ensure a per-minute report within the pruned range uses the
+		// last rolled up value as its starting point.
+		start = time.Date(2000, time.January, 1, 12, 0, 0, 0, time.UTC)
+		if report, err := s.DialValueReport(ctx0, dial0.ID, start, start.Add(2*time.Minute), wtf.ReportInterval{Duration: time.Minute}); err != nil {
+			t.Fatal(err)
+		} else if got, want := reportValues(report), []int{30, 30}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("values=%v, want %v", got, want)
+		}
+	})
+
+	// Ensure no values are removed when retention is disabled.
+	t.Run("Disabled", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+
+		if n, err := db.PruneDialValues(context.Background()); err != nil {
+			t.Fatal(err)
+		} else if n != 0 {
+			t.Fatalf("n=%v, want 0", n)
+		}
+	})
+}
+
+// MustSetDialMembershipValueAt sets the value of a membership at a given time. Fatal on error.
+func MustSetDialMembershipValueAt(tb testing.TB, ctx context.Context, db *sqlite.DB, id, value int, now time.Time) {
+	tb.Helper()
+	db.Now = func() time.Time { return now }
+	MustSetDialMembershipValue(tb, ctx, db, id, value)
+}
+
+// reportValues returns the values of each record in a report.
+func reportValues(report *wtf.DialValueReport) []int {
+	a := make([]int, len(report.Records))
+	for i, r := range report.Records {
+		a[i] = r.Value
+	}
+	return a
+}
@@ -0,0 +1,216 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// Ensure service implements interface.
+var _ wtf.IdempotencyKeyService = (*IdempotencyKeyService)(nil)
+
+// IdempotencyKeyService represents a service for storing request results by
+// idempotency key.
+type IdempotencyKeyService struct {
+	db *DB
+}
+
+// NewIdempotencyKeyService returns a new instance of IdempotencyKeyService.
+func NewIdempotencyKeyService(db *DB) *IdempotencyKeyService {
+	return &IdempotencyKeyService{db: db}
+}
+
+// FindIdempotencyKey retrieves an unexpired key for the current user.
+// Returns ENOTFOUND if the key does not exist or has expired.
+func (s *IdempotencyKeyService) FindIdempotencyKey(ctx context.Context, key string) (*wtf.IdempotencyKey, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	return findIdempotencyKey(ctx, tx, wtf.UserIDFromContext(ctx), key)
+}
+
+// CreateIdempotencyKey reserves a key for the current user. Returns
+// EUNAUTHORIZED if no user is logged in. Returns ECONFLICT if an unexpired
+// key already exists for the user.
+func (s *IdempotencyKeyService) CreateIdempotencyKey(ctx context.Context, key *wtf.IdempotencyKey) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Clear out expired keys so the table does not grow unbounded and so
+	// expired keys can be reused.
+	if err := deleteExpiredIdempotencyKeys(ctx, tx); err != nil {
+		return err
+	}
+
+	if err := createIdempotencyKey(ctx, tx, key); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// CompleteIdempotencyKey stores the response of the original request.
+// Returns ENOTFOUND if the key does not exist for the current user.
+func (s *IdempotencyKeyService) CompleteIdempotencyKey(ctx context.Context, id int, statusCode int, contentType, location string, body []byte) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE idempotency_keys
+		SET status_code = ?,
+		    content_type = ?,
+		    location = ?,
+		    body = ?,
+		    updated_at = ?
+		WHERE id = ? AND user_id = ?
+	`,
+		statusCode,
+		contentType,
+		location,
+		body,
+		(*NullTime)(&tx.now),
+		id,
+		wtf.UserIDFromContext(ctx),
+	)
+	if err != nil {
+		return FormatError(err)
+	} else if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return wtf.Errorf(wtf.ENOTFOUND, "Idempotency key not found.")
+	}
+	return tx.Commit()
+}
+
+// DeleteIdempotencyKey removes a key so the request can be retried.
+// Returns ENOTFOUND if the key does not exist for the current user.
+func (s *IdempotencyKeyService) DeleteIdempotencyKey(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE id = ? AND user_id = ?`, id, wtf.UserIDFromContext(ctx))
+	if err != nil {
+		return FormatError(err)
+	} else if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return wtf.Errorf(wtf.ENOTFOUND, "Idempotency key not found.")
+	}
+	return tx.Commit()
+}
+
+// findIdempotencyKey returns an unexpired key for a user.
+// Returns ENOTFOUND if no key exists or if it has expired.
+func findIdempotencyKey(ctx context.Context, tx *Tx, userID int, key string) (*wtf.IdempotencyKey, error) {
+	var k wtf.IdempotencyKey
+	if err := tx.QueryRowContext(ctx, `
+		SELECT
+		    id,
+		    user_id,
+		    "key",
+		    fingerprint,
+		    status_code,
+		    content_type,
+		    location,
+		    body,
+		    expires_at,
+		    created_at,
+		    updated_at
+		FROM idempotency_keys
+		WHERE user_id = ? AND "key" = ?
+	`,
+		userID,
+		key,
+	).Scan(
+		&k.ID,
+		&k.UserID,
+		&k.Key,
+		&k.Fingerprint,
+		&k.StatusCode,
+		&k.ContentType,
+		&k.Location,
+		&k.Body,
+		(*NullTime)(&k.ExpiresAt),
+		(*NullTime)(&k.CreatedAt),
+		(*NullTime)(&k.UpdatedAt),
+	); err == sql.ErrNoRows {
+		return nil, wtf.Errorf(wtf.ENOTFOUND, "Idempotency key not found.")
+	} else if err != nil {
+		return nil, FormatError(err)
+	}
+
+	// Treat expired keys as if they do not exist.
+	if k.Expired(tx.now) {
+		return nil, wtf.Errorf(wtf.ENOTFOUND, "Idempotency key has expired.")
+	}
+	return &k, nil
+}
+
+// createIdempotencyKey inserts a new, uncompleted key for the current user.
+func createIdempotencyKey(ctx context.Context, tx *Tx, key *wtf.IdempotencyKey) error {
+	// Assign key to the current user.
+	userID := wtf.UserIDFromContext(ctx)
+	if userID == 0 {
+		return wtf.Errorf(wtf.EUNAUTHORIZED, "You must be logged in to use an idempotency key.")
+	}
+	key.UserID = userID
+
+	// Clear any stored response & set expiration based on the current time.
+	key.StatusCode, key.ContentType, key.Location, key.Body = 0, "", "", nil
+	key.ExpiresAt = tx.now.Add(wtf.IdempotencyKeyExpiry)
+	key.CreatedAt = tx.now
+	key.UpdatedAt = key.CreatedAt
+
+	// Perform basic field validation.
+	if err := key.Validate(); err != nil {
+		return err
+	}
+
+	// Insert row into database.
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO idempotency_keys (
+			user_id,
+			"key",
+			fingerprint,
+			expires_at,
+			created_at,
+			updated_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`,
+		key.UserID,
+		key.Key,
+		key.Fingerprint,
+		(*NullTime)(&key.ExpiresAt),
+		(*NullTime)(&key.CreatedAt),
+		(*NullTime)(&key.UpdatedAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	// Read back new ID into caller argument.
+	if key.ID, err = lastInsertID(result); err != nil {
+		return err
+	}
+	return nil
+}
+
+// deleteExpiredIdempotencyKeys removes all keys that have expired.
+func deleteExpiredIdempotencyKeys(ctx context.Context, tx *Tx) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= ?`, (*NullTime)(&tx.now)); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
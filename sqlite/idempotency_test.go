@@ -0,0 +1,132 @@
+package sqlite_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/sqlite"
+)
+
+func TestIdempotencyKeyService(t *testing.T) {
+	// Ensure a key can be reserved, completed & then found with its response.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewIdempotencyKeyService(db)
+
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane"})
+
+		key := &wtf.IdempotencyKey{Key: "KEY", Fingerprint: "FP"}
+		if err := s.CreateIdempotencyKey(ctx0, key); err != nil {
+			t.Fatal(err)
+		} else if got, want := key.ID, 1; got != want {
+			t.Fatalf("ID=%v, want %v", got, want)
+		}
+
+		// Ensure key is incomplete until the response is stored.
+		if other, err := s.FindIdempotencyKey(ctx0, "KEY"); err != nil {
+			t.Fatal(err)
+		} else if other.Completed() {
+			t.Fatal("expected incomplete key")
+		}
+
+		if err := s.CompleteIdempotencyKey(ctx0, key.ID, 201, "application/json", "/dials/1", []byte(`{"id":1}`)); err != nil {
+			t.Fatal(err)
+		}
+
+		if other, err := s.FindIdempotencyKey(ctx0, "KEY"); err != nil {
+			t.Fatal(err)
+		} else if !other.Completed() {
+			t.Fatal("expected completed key")
+		} else if got, want := other.Fingerprint, "FP"; got != want {
+			t.Fatalf("Fingerprint=%v, want %v", got, want)
+		} else if got, want := other.StatusCode, 201; got != want {
+			t.Fatalf("StatusCode=%v, want %v", got, want)
+		} else if got, want := other.ContentType, "application/json"; got != want {
+			t.Fatalf("ContentType=%v, want %v", got, want)
+		} else if got, want := other.Location, "/dials/1"; got != want {
+			t.Fatalf("Location=%v, want %v", got, want)
+		} else if got, want := other.Body, []byte(`{"id":1}`); !reflect.DeepEqual(got, want) {
+			t.Fatalf("Body=%s, want %s", got, want)
+		}
+	})
+
+	// Ensure a key cannot be reserved twice by the same user.
+	t.Run("ErrConflict", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewIdempotencyKeyService(db)
+
+		ctx := context.Background()
+		_, ctx0 := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, ctx, db, &wtf.User{Name: "john"})
+
+		if err := s.CreateIdempotencyKey(ctx0, &wtf.IdempotencyKey{Key: "KEY", Fingerprint: "FP"}); err != nil {
+			t.Fatal(err)
+		} else if err := s.CreateIdempotencyKey(ctx0, &wtf.IdempotencyKey{Key: "KEY", Fingerprint: "FP"}); wtf.ErrorCode(err) != wtf.ECONFLICT {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		// Ensure another user can use the same key.
+		if err := s.CreateIdempotencyKey(ctx1, &wtf.IdempotencyKey{Key: "KEY", Fingerprint: "FP"}); err != nil {
+			t.Fatal(err)
+		} else if _, err := s.FindIdempotencyKey(ctx1, "NO_SUCH_KEY"); wtf.ErrorCode(err) != wtf.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure a key can be reused after it expires.
+	t.Run("Expired", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewIdempotencyKeyService(db)
+
+		db.Now = func() time.Time { return time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC) }
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane"})
+
+		if err := s.CreateIdempotencyKey(ctx0, &wtf.IdempotencyKey{Key: "KEY", Fingerprint: "FP"}); err != nil {
+			t.Fatal(err)
+		}
+
+		db.Now = func() time.Time { return time.Date(2000, time.January, 2, 0, 0, 0, 0, time.UTC) }
+		if _, err := s.FindIdempotencyKey(ctx0, "KEY"); wtf.ErrorCode(err) != wtf.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if err := s.CreateIdempotencyKey(ctx0, &wtf.IdempotencyKey{Key: "KEY", Fingerprint: "FP2"}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	// Ensure a key can be released so the request can be retried.
+	t.Run("Delete", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewIdempotencyKeyService(db)
+
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane"})
+
+		key := &wtf.IdempotencyKey{Key: "KEY", Fingerprint: "FP"}
+		if err := s.CreateIdempotencyKey(ctx0, key); err != nil {
+			t.Fatal(err)
+		} else if err := s.DeleteIdempotencyKey(ctx0, key.ID); err != nil {
+			t.Fatal(err)
+		} else if err := s.DeleteIdempotencyKey(ctx0, key.ID); wtf.ErrorCode(err) != wtf.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if err := s.CreateIdempotencyKey(ctx0, &wtf.IdempotencyKey{Key: "KEY", Fingerprint: "FP"}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	// Ensure a user must be logged in to reserve a key.
+	t.Run("ErrUnauthorized", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewIdempotencyKeyService(db)
+
+		if err := s.CreateIdempotencyKey(context.Background(), &wtf.IdempotencyKey{Key: "KEY", Fingerprint: "FP"}); wtf.ErrorCode(err) != wtf.EUNAUTHORIZED {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
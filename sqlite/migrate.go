@@ -0,0 +1,392 @@
+package sqlite
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// Migration file extensions. Each version has an up file which is applied
+// when the database is opened and an optional down file used for rollback.
+const (
+	MigrationUpExt   = ".sql"
+	MigrationDownExt = ".down.sql"
+)
+
+// Migration represents a single embedded migration and its state in a database.
+type Migration struct {
+	// Version is the file name without extension, e.g. "00000001".
+	Version string
+
+	// Name is the path of the up file. It is stored in the "migrations"
+	// table to track which migrations have been applied.
+	Name string
+
+	// SHA-256 checksum of the up file, hex encoded.
+	Checksum string
+
+	// Set if the migration has a down file and can be rolled back.
+	Reversible bool
+
+	// Set if the migration has been applied to the database.
+	Applied bool
+
+	// Set if the migration was applied with a different checksum which
+	// means the file has been edited since it was applied.
+	Modified bool
+}
+
+// Migrations returns the embedded migrations in execution order along with
+// their state in the database at dsn. The database is not migrated so this
+// can be used to list pending migrations without applying them. If the
+// database does not exist then every migration is pending & no file is created.
+func Migrations(ctx context.Context, dsn string) ([]*Migration, error) {
+	if _, err := os.Stat(dsnFilename(dsn)); os.IsNotExist(err) {
+		return findMigrations(ctx, nil, migrationFS)
+	} else if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return findMigrations(ctx, db, migrationFS)
+}
+
+// Rollback reverts applied migrations newer than version by executing their
+// down files in reverse order. Returns the migrations that were reverted. If
+// dryRun is set then the migrations are returned but nothing is executed.
+//
+// Returns ENOTFOUND if version does not exist. Returns EINVALID if any of the
+// migrations to revert do not have a down file or have been modified. Returns
+// ECONFLICT if the database is in use, unless this is a dry run.
+func Rollback(ctx context.Context, dsn, version string, dryRun bool) ([]*Migration, error) {
+	filename := dsnFilename(dsn)
+	if _, err := os.Stat(filename); err != nil {
+		return nil, err
+	}
+
+	// Ensure the server is not running before changing the schema under it.
+	if !dryRun {
+		if err := checkDatabaseUnused(ctx, filename); err != nil {
+			return nil, err
+		}
+	}
+
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return rollback(ctx, db, migrationFS, version, dryRun)
+}
+
+// migrate sets up migration tracking and executes pending migration files.
+//
+// Migration files are embedded in the sqlite/migration folder and are executed
+// in lexigraphical order.
+//
+// Once a migration is run, its name & checksum are stored in the 'migrations'
+// table so it is not re-executed. Previously applied migrations are verified
+// against their checksum so edits to an applied file are caught instead of
+// silently diverging from the schema. Migrations run in a transaction to
+// prevent partial migrations.
+func (db *DB) migrate() error {
+	// Ensure the 'migrations' table exists so we don't duplicate migrations.
+	if err := createMigrationsTable(db.db); err != nil {
+		return fmt.Errorf("cannot create migrations table: %w", err)
+	}
+
+	migrations, err := findMigrations(db.ctx, db.db, migrationFS)
+	if err != nil {
+		return err
+	}
+
+	// Loop over all migration files and execute them in order.
+	for _, m := range migrations {
+		if m.Modified {
+			return fmt.Errorf("migration has been modified since it was applied: name=%q", m.Name)
+		} else if err := db.migrateFile(m); err != nil {
+			return fmt.Errorf("migration error: name=%q err=%w", m.Name, err)
+		}
+	}
+	return nil
+}
+
+// migrateFile runs a single migration file within a transaction. On success,
+// the migration file name & checksum are saved to the "migrations" table to
+// prevent re-running.
+func (db *DB) migrateFile(m *Migration) error {
+	tx, err := db.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Ensure migration has not already been run. Migrations applied before
+	// checksums were tracked have their checksum filled in.
+	var checksum sql.NullString
+	if err := tx.QueryRow(`SELECT checksum FROM migrations WHERE name = ?`, m.Name).Scan(&checksum); err == nil {
+		if checksum.Valid {
+			return nil // already run migration, skip
+		} else if _, err := tx.Exec(`UPDATE migrations SET checksum = ? WHERE name = ?`, m.Checksum, m.Name); err != nil {
+			return err
+		}
+		return tx.Commit()
+	} else if err != sql.ErrNoRows {
+		return err
+	}
+
+	// Read and execute migration file.
+	if buf, err := fs.ReadFile(migrationFS, m.Name); err != nil {
+		return err
+	} else if _, err := tx.Exec(string(buf)); err != nil {
+		return err
+	}
+
+	// Insert record into migrations to prevent re-running migration.
+	if _, err := tx.Exec(`INSERT INTO migrations (name, checksum) VALUES (?, ?)`, m.Name, m.Checksum); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// rollback reverts applied migrations newer than version.
+func rollback(ctx context.Context, db *sql.DB, fsys fs.FS, version string, dryRun bool) ([]*Migration, error) {
+	migrations, err := findMigrations(ctx, db, fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find applied migrations after the target version. Validate all of them
+	// before executing anything so a rollback is not stopped partway.
+	var found bool
+	var reverts []*Migration
+	for _, m := range migrations {
+		if m.Version == version {
+			found = true
+		} else if m.Version > version && m.Applied {
+			if !m.Reversible {
+				return nil, wtf.Errorf(wtf.EINVALID, "Migration %s cannot be rolled back.", m.Version)
+			} else if m.Modified {
+				return nil, wtf.Errorf(wtf.EINVALID, "Migration %s has been modified since it was applied.", m.Version)
+			}
+			reverts = append(reverts, m)
+		}
+	}
+	if !found {
+		return nil, wtf.Errorf(wtf.ENOTFOUND, "Migration version not found: %s", version)
+	}
+
+	// Revert newest migrations first.
+	sort.Slice(reverts, func(i, j int) bool { return reverts[i].Version > reverts[j].Version })
+	if dryRun {
+		return reverts, nil
+	}
+
+	for _, m := range reverts {
+		if err := rollbackFile(ctx, db, fsys, m); err != nil {
+			return nil, fmt.Errorf("rollback error: name=%q err=%w", m.Name, err)
+		}
+	}
+	return reverts, nil
+}
+
+// rollbackFile executes the down file of a migration within a transaction and
+// removes the migration from the "migrations" table.
+//
+// Down files rebuild tables since SQLite cannot drop columns. Foreign keys are
+// disabled on the connection so dropping a rebuilt table does not cascade to
+// the rows referencing it. The pragma has no effect inside a transaction so
+// it is set first & references are verified before committing.
+func rollbackFile(ctx context.Context, db *sql.DB, fsys fs.FS, m *Migration) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `PRAGMA foreign_keys = OFF;`); err != nil {
+		return fmt.Errorf("foreign keys pragma: %w", err)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if buf, err := fs.ReadFile(fsys, migrationDownName(m.Name)); err != nil {
+		return err
+	} else if _, err := tx.ExecContext(ctx, string(buf)); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM migrations WHERE name = ?`, m.Name); err != nil {
+		return err
+	}
+
+	// Ensure the rebuilt tables did not leave any dangling references.
+	rows, err := tx.QueryContext(ctx, `PRAGMA foreign_key_check;`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	if rows.Next() {
+		var table string
+		var rowid sql.NullInt64
+		var parent string
+		var fkid int
+		if err := rows.Scan(&table, &rowid, &parent, &fkid); err != nil {
+			return err
+		}
+		return fmt.Errorf("foreign key violation: table=%q rowid=%d parent=%q", table, rowid.Int64, parent)
+	} else if err := rows.Err(); err != nil {
+		return err
+	} else if err := rows.Close(); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// createMigrationsTable creates the "migrations" table if it does not exist.
+// The checksum column is added to tables created by earlier versions.
+func createMigrationsTable(db *sql.DB) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS migrations (name TEXT PRIMARY KEY, checksum TEXT);`); err != nil {
+		return err
+	}
+
+	if ok, err := hasMigrationChecksumColumn(context.Background(), db); err != nil {
+		return err
+	} else if !ok {
+		if _, err := db.Exec(`ALTER TABLE migrations ADD COLUMN checksum TEXT;`); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findMigrations returns all migrations in fsys in execution order along with
+// their state in the database.
+func findMigrations(ctx context.Context, db *sql.DB, fsys fs.FS) ([]*Migration, error) {
+	applied, err := findAppliedMigrations(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := fs.Glob(fsys, "migration/*"+MigrationUpExt)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	migrations := make([]*Migration, 0, len(names))
+	for _, name := range names {
+		// Down files share the up extension so skip them here.
+		if strings.HasSuffix(name, MigrationDownExt) {
+			continue
+		}
+
+		buf, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(buf)
+
+		m := &Migration{
+			Version:  strings.TrimSuffix(path.Base(name), MigrationUpExt),
+			Name:     name,
+			Checksum: hex.EncodeToString(sum[:]),
+		}
+
+		if _, err := fs.Stat(fsys, migrationDownName(name)); err == nil {
+			m.Reversible = true
+		}
+
+		if checksum, ok := applied[name]; ok {
+			m.Applied = true
+			m.Modified = checksum != "" && checksum != m.Checksum
+		}
+		migrations = append(migrations, m)
+	}
+	return migrations, nil
+}
+
+// findAppliedMigrations returns the checksum of each migration applied to the
+// database by name. Checksums are blank for migrations applied before they
+// were tracked. Returns an empty set if db is nil or if the migrations table
+// does not exist.
+func findAppliedMigrations(ctx context.Context, db *sql.DB) (map[string]string, error) {
+	if db == nil {
+		return map[string]string{}, nil
+	}
+
+	var n int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'migrations'`).Scan(&n); err != nil {
+		return nil, err
+	} else if n == 0 {
+		return map[string]string{}, nil
+	}
+
+	query := `SELECT name, checksum FROM migrations`
+	if ok, err := hasMigrationChecksumColumn(ctx, db); err != nil {
+		return nil, err
+	} else if !ok {
+		query = `SELECT name, NULL FROM migrations`
+	}
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	m := make(map[string]string)
+	for rows.Next() {
+		var name string
+		var checksum sql.NullString
+		if err := rows.Scan(&name, &checksum); err != nil {
+			return nil, err
+		}
+		m[name] = checksum.String
+	}
+	return m, rows.Err()
+}
+
+// hasMigrationChecksumColumn returns true if the "migrations" table has
+// a checksum column.
+func hasMigrationChecksumColumn(ctx context.Context, db *sql.DB) (bool, error) {
+	var n int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM pragma_table_info('migrations') WHERE name = 'checksum'`).Scan(&n); err != nil {
+		return false, err
+	}
+	return n != 0, nil
+}
+
+// dsnFilename returns the database file of dsn without any query parameters.
+func dsnFilename(dsn string) string {
+	if i := strings.IndexByte(dsn, '?'); i >= 0 {
+		return dsn[:i]
+	}
+	return dsn
+}
+
+// migrationDownName returns the path of the down file for an up file.
+func migrationDownName(name string) string {
+	return strings.TrimSuffix(name, MigrationUpExt) + MigrationDownExt
+}
@@ -0,0 +1,253 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+	"github.com/benbjohnson/wtf/sqlite"
+)
+
+func TestMigrations(t *testing.T) {
+	// Ensure every embedded migration is applied & can be reversed.
+	t.Run("OK", func(t *testing.T) {
+		dsn := MustOpenCloseDB(t)
+
+		migrations, err := sqlite.Migrations(context.Background(), dsn)
+		if err != nil {
+			t.Fatal(err)
+		} else if len(migrations) == 0 {
+			t.Fatal("expected migrations")
+		}
+
+		for _, m := range migrations {
+			if !m.Applied {
+				t.Fatalf("expected applied: %s", m.Version)
+			} else if m.Modified {
+				t.Fatalf("unexpected modified: %s", m.Version)
+			} else if !m.Reversible {
+				t.Fatalf("expected down file: %s", m.Version)
+			}
+		}
+	})
+
+	// Ensure migrations are listed as pending on a missing database without
+	// creating it.
+	t.Run("Pending", func(t *testing.T) {
+		dsn := filepath.Join(t.TempDir(), "db")
+		migrations, err := sqlite.Migrations(context.Background(), dsn)
+		if err != nil {
+			t.Fatal(err)
+		} else if len(migrations) == 0 {
+			t.Fatal("expected migrations")
+		}
+		for _, m := range migrations {
+			if m.Applied {
+				t.Fatalf("unexpected applied: %s", m.Version)
+			}
+		}
+
+		if _, err := os.Stat(dsn); !os.IsNotExist(err) {
+			t.Fatalf("expected database to not be created: %v", err)
+		}
+	})
+
+	// Ensure an edited migration is detected & prevents the database from opening.
+	t.Run("ErrModified", func(t *testing.T) {
+		dsn := MustOpenCloseDB(t)
+		MustExec(t, dsn, `UPDATE migrations SET checksum = 'xyz' WHERE name = 'migration/00000000.sql'`)
+
+		if migrations, err := sqlite.Migrations(context.Background(), dsn); err != nil {
+			t.Fatal(err)
+		} else if !migrations[0].Modified {
+			t.Fatal("expected modified")
+		}
+
+		db := sqlite.NewDB(dsn)
+		if err := db.Open(); err == nil {
+			t.Fatal("expected error")
+		}
+		db.Close()
+	})
+
+	// Ensure migrations applied before checksums were tracked are accepted.
+	t.Run("LegacyChecksum", func(t *testing.T) {
+		dsn := MustOpenCloseDB(t)
+		MustExec(t, dsn, `UPDATE migrations SET checksum = NULL`)
+
+		db := sqlite.NewDB(dsn)
+		if err := db.Open(); err != nil {
+			t.Fatal(err)
+		}
+		MustCloseDB(t, db)
+
+		var n int
+		MustQueryRow(t, dsn, `SELECT COUNT(*) FROM migrations WHERE checksum IS NULL`, &n)
+		if n != 0 {
+			t.Fatalf("expected checksums to be filled, %d remain", n)
+		}
+	})
+}
+
+func TestRollback(t *testing.T) {
+	// Ensure migrations after a version are reverted & can be reapplied.
+	// Rows in rebuilt tables & the rows referencing them are kept.
+	t.Run("OK", func(t *testing.T) {
+		dsn := filepath.Join(t.TempDir(), "db")
+		db := sqlite.NewDB(dsn)
+		if err := db.Open(); err != nil {
+			t.Fatal(err)
+		}
+		_, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane"})
+		_, ctx1 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "john"})
+		dial := MustCreateDial(t, ctx0, db, &wtf.Dial{Name: "DIAL"})
+		membership := MustCreateDialMembership(t, ctx1, db, &wtf.DialMembership{DialID: dial.ID, Value: 10})
+		MustSetDialMembershipValueAt(t, ctx1, db, membership.ID, 20, time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC))
+		MustCloseDB(t, db)
+
+		var dialN, membershipN, valueN int
+		MustQueryRow(t, dsn, `SELECT (SELECT COUNT(*) FROM dials), (SELECT COUNT(*) FROM dial_memberships), (SELECT COUNT(*) FROM dial_values)`, &dialN, &membershipN, &valueN)
+		if dialN != 1 || membershipN != 2 || valueN == 0 {
+			t.Fatalf("unexpected counts: dials=%d memberships=%d values=%d", dialN, membershipN, valueN)
+		}
+
+		all, err := sqlite.Migrations(context.Background(), dsn)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Ensure all later migrations are reverted, newest first. Foreign
+		// keys are enabled by the DSN to ensure rollback disables them.
+		if migrations, err := sqlite.Rollback(context.Background(), dsn+"?_foreign_keys=on", "00000000", false); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(migrations), len(all)-1; got != want {
+			t.Fatalf("len=%v, want %v", got, want)
+		} else if got, want := migrations[0].Version, all[len(all)-1].Version; got != want {
+			t.Fatalf("Version=%v, want %v", got, want)
+		} else if got, want := migrations[len(migrations)-1].Version, "00000001"; got != want {
+			t.Fatalf("Version=%v, want %v", got, want)
+		}
+
+		var n int
+		MustQueryRow(t, dsn, `SELECT COUNT(*) FROM sqlite_master WHERE name = 'device_authorizations'`, &n)
+		if n != 0 {
+			t.Fatal("expected table to be dropped")
+		}
+
+		var otherDialN, otherMembershipN, otherValueN int
+		MustQueryRow(t, dsn, `SELECT (SELECT COUNT(*) FROM dials), (SELECT COUNT(*) FROM dial_memberships), (SELECT COUNT(*) FROM dial_values)`, &otherDialN, &otherMembershipN, &otherValueN)
+		if otherDialN != dialN || otherMembershipN != membershipN || otherValueN != valueN {
+			t.Fatalf("rows removed: dials=%d memberships=%d values=%d", otherDialN, otherMembershipN, otherValueN)
+		}
+
+		// Reopen to apply the migration again.
+		db = sqlite.NewDB(dsn)
+		if err := db.Open(); err != nil {
+			t.Fatal(err)
+		}
+		MustCloseDB(t, db)
+
+		MustQueryRow(t, dsn, `SELECT COUNT(*) FROM sqlite_master WHERE name = 'device_authorizations'`, &n)
+		if n != 1 {
+			t.Fatal("expected table to be recreated")
+		}
+	})
+
+	// Ensure a dry run does not change the database.
+	t.Run("DryRun", func(t *testing.T) {
+		dsn := MustOpenCloseDB(t)
+
+		if migrations, err := sqlite.Rollback(context.Background(), dsn, "00000000", true); err != nil {
+			t.Fatal(err)
+		} else if len(migrations) == 0 {
+			t.Fatal("expected migrations")
+		}
+
+		if migrations, err := sqlite.Migrations(context.Background(), dsn); err != nil {
+			t.Fatal(err)
+		} else if !migrations[1].Applied {
+			t.Fatal("expected migration to remain applied")
+		}
+	})
+
+	// Ensure an unknown version returns an error.
+	t.Run("ErrNotFound", func(t *testing.T) {
+		dsn := MustOpenCloseDB(t)
+		if _, err := sqlite.Rollback(context.Background(), dsn, "99999999", false); wtf.ErrorCode(err) != wtf.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Ensure a missing database is reported instead of being created.
+	t.Run("ErrDatabaseNotExist", func(t *testing.T) {
+		dsn := filepath.Join(t.TempDir(), "db")
+		if _, err := sqlite.Rollback(context.Background(), dsn, "00000000", false); !os.IsNotExist(err) {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if _, err := os.Stat(dsn); !os.IsNotExist(err) {
+			t.Fatalf("expected database to not be created: %v", err)
+		}
+	})
+
+	// Ensure migrations cannot be rolled back while the database is open,
+	// although a dry run is allowed.
+	t.Run("ErrDatabaseOpen", func(t *testing.T) {
+		dsn := filepath.Join(t.TempDir(), "db")
+		db := sqlite.NewDB(dsn)
+		if err := db.Open(); err != nil {
+			t.Fatal(err)
+		}
+		defer MustCloseDB(t, db)
+		MustCreateUser(t, context.Background(), db, &wtf.User{Name: "jane"})
+
+		if _, err := sqlite.Rollback(context.Background(), dsn, "00000000", true); err != nil {
+			t.Fatal(err)
+		} else if _, err := sqlite.Rollback(context.Background(), dsn, "00000000", false); wtf.ErrorCode(err) != wtf.ECONFLICT {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+// MustOpenCloseDB creates a fully migrated database in a temporary directory
+// and returns its path. Fatal on error.
+func MustOpenCloseDB(tb testing.TB) string {
+	tb.Helper()
+	dsn := filepath.Join(tb.TempDir(), "db")
+	db := sqlite.NewDB(dsn)
+	if err := db.Open(); err != nil {
+		tb.Fatal(err)
+	}
+	MustCloseDB(tb, db)
+	return dsn
+}
+
+// MustExec executes a query directly against the database at dsn. Fatal on error.
+func MustExec(tb testing.TB, dsn, query string) {
+	tb.Helper()
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(query); err != nil {
+		tb.Fatal(err)
+	}
+}
+
+// MustQueryRow scans a single row directly from the database at dsn. Fatal on error.
+func MustQueryRow(tb testing.TB, dsn, query string, dest ...interface{}) {
+	tb.Helper()
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer db.Close()
+
+	if err := db.QueryRow(query).Scan(dest...); err != nil {
+		tb.Fatal(err)
+	}
+}
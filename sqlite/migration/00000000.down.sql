@@ -0,0 +1,5 @@
+DROP TABLE dial_memberships;
+DROP TABLE dial_values;
+DROP TABLE dials;
+DROP TABLE auths;
+DROP TABLE users;
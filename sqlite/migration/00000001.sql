@@ -0,0 +1,9 @@
+CREATE TABLE device_authorizations (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	device_code TEXT NOT NULL UNIQUE,
+	user_code   TEXT NOT NULL UNIQUE,
+	user_id     INTEGER REFERENCES users (id) ON DELETE CASCADE,
+	expires_at  TEXT NOT NULL,
+	created_at  TEXT NOT NULL,
+	updated_at  TEXT NOT NULL
+);
@@ -0,0 +1,2 @@
+DROP TABLE dial_values_daily;
+DROP TABLE dial_values_hourly;
@@ -0,0 +1,54 @@
+-- Rollups of dial_values by hour & day. Each row summarizes the values
+-- recorded within the bucket starting at "timestamp".
+CREATE TABLE dial_values_hourly (
+	dial_id         INTEGER NOT NULL REFERENCES dials (id) ON DELETE CASCADE,
+	"timestamp"     TEXT NOT NULL, -- start of hour
+	min_value       INTEGER NOT NULL,
+	max_value       INTEGER NOT NULL,
+	sum_value       INTEGER NOT NULL,
+	count           INTEGER NOT NULL,
+	last_value      INTEGER NOT NULL,
+	last_timestamp  TEXT NOT NULL,
+
+	PRIMARY KEY (dial_id, "timestamp")
+);
+
+CREATE TABLE dial_values_daily (
+	dial_id         INTEGER NOT NULL REFERENCES dials (id) ON DELETE CASCADE,
+	"timestamp"     TEXT NOT NULL, -- start of day, UTC
+	min_value       INTEGER NOT NULL,
+	max_value       INTEGER NOT NULL,
+	sum_value       INTEGER NOT NULL,
+	count           INTEGER NOT NULL,
+	last_value      INTEGER NOT NULL,
+	last_timestamp  TEXT NOT NULL,
+
+	PRIMARY KEY (dial_id, "timestamp")
+);
+
+-- Backfill rollups from existing values.
+INSERT INTO dial_values_hourly (dial_id, "timestamp", min_value, max_value, sum_value, count, last_value, last_timestamp)
+SELECT dial_id, strftime('%Y-%m-%dT%H:00:00Z', "timestamp"), MIN(value), MAX(value), SUM(value), COUNT(*), 0, MAX("timestamp")
+FROM dial_values
+GROUP BY 1, 2;
+
+UPDATE dial_values_hourly
+SET last_value = (
+	SELECT dv.value
+	FROM dial_values dv
+	WHERE dv.dial_id = dial_values_hourly.dial_id
+	  AND dv."timestamp" = dial_values_hourly.last_timestamp
+);
+
+INSERT INTO dial_values_daily (dial_id, "timestamp", min_value, max_value, sum_value, count, last_value, last_timestamp)
+SELECT dial_id, strftime('%Y-%m-%dT00:00:00Z', "timestamp"), MIN(min_value), MAX(max_value), SUM(sum_value), SUM(count), 0, MAX(last_timestamp)
+FROM dial_values_hourly
+GROUP BY 1, 2;
+
+UPDATE dial_values_daily
+SET last_value = (
+	SELECT h.last_value
+	FROM dial_values_hourly h
+	WHERE h.dial_id = dial_values_daily.dial_id
+	  AND h.last_timestamp = dial_values_daily.last_timestamp
+);
@@ -0,0 +1,37 @@
+-- SQLite cannot drop columns so the tables are rebuilt without them. Foreign
+-- keys must be disabled so that dropping the old tables does not cascade.
+CREATE TABLE dials_new (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id     INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	name        TEXT NOT NULL,
+	invite_code TEXT UNIQUE NOT NULL,
+	value       INTEGER NOT NULL DEFAULT 0,
+	created_at  TEXT NOT NULL,
+	updated_at  TEXT NOT NULL
+);
+
+INSERT INTO dials_new (id, user_id, name, invite_code, value, created_at, updated_at)
+SELECT id, user_id, name, invite_code, value, created_at, updated_at FROM dials;
+
+DROP TABLE dials;
+ALTER TABLE dials_new RENAME TO dials;
+CREATE INDEX dials_user_id_idx ON dials (user_id);
+
+CREATE TABLE dial_memberships_new (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	dial_id    INTEGER NOT NULL REFERENCES dials (id) ON DELETE CASCADE,
+	user_id    INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	value      INTEGER NOT NULL,
+	created_at TEXT NOT NULL,
+	updated_at TEXT NOT NULL,
+
+	UNIQUE(dial_id, user_id)
+);
+
+INSERT INTO dial_memberships_new (id, dial_id, user_id, value, created_at, updated_at)
+SELECT id, dial_id, user_id, value, created_at, updated_at FROM dial_memberships;
+
+DROP TABLE dial_memberships;
+ALTER TABLE dial_memberships_new RENAME TO dial_memberships;
+CREATE INDEX dial_memberships_dial_id_idx ON dial_memberships (dial_id);
+CREATE INDEX dial_memberships_user_id_idx ON dial_memberships (user_id);
@@ -0,0 +1,3 @@
+-- Versions are incremented on each update to detect concurrent edits.
+ALTER TABLE dials ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE dial_memberships ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,17 @@
+CREATE TABLE idempotency_keys (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id      INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	"key"        TEXT NOT NULL,
+	fingerprint  TEXT NOT NULL,
+	status_code  INTEGER NOT NULL DEFAULT 0, -- zero while in progress
+	content_type TEXT NOT NULL DEFAULT '',
+	location     TEXT NOT NULL DEFAULT '',
+	body         BLOB,
+	expires_at   TEXT NOT NULL,
+	created_at   TEXT NOT NULL,
+	updated_at   TEXT NOT NULL,
+
+	UNIQUE(user_id, "key")
+);
+
+CREATE INDEX idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
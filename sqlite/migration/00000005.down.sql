@@ -0,0 +1,3 @@
+DROP INDEX dials_updated_at_idx;
+DROP INDEX dials_value_idx;
+DROP INDEX dials_name_idx;
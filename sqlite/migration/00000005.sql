@@ -0,0 +1,5 @@
+-- Indexes used when searching & sorting dials. Names are matched & sorted
+-- case-insensitively so the index uses the NOCASE collation.
+CREATE INDEX dials_name_idx ON dials (name COLLATE NOCASE);
+CREATE INDEX dials_value_idx ON dials (value);
+CREATE INDEX dials_updated_at_idx ON dials (updated_at);
@@ -0,0 +1,16 @@
+-- SQLite cannot drop columns so the table is rebuilt without it. Foreign
+-- keys must be disabled so that dropping the old table does not cascade.
+CREATE TABLE users_new (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	name       TEXT NOT NULL,
+	email      TEXT UNIQUE,
+	api_key    TEXT NOT NULL UNIQUE,
+	created_at TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);
+
+INSERT INTO users_new (id, name, email, api_key, created_at, updated_at)
+SELECT id, name, email, api_key, created_at, updated_at FROM users;
+
+DROP TABLE users;
+ALTER TABLE users_new RENAME TO users;
@@ -0,0 +1,2 @@
+-- IANA time zone used to align the user's reports. Blank is treated as UTC.
+ALTER TABLE users ADD COLUMN time_zone TEXT NOT NULL DEFAULT '';
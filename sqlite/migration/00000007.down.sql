@@ -0,0 +1,2 @@
+DROP TABLE dial_anomalies;
+DROP TABLE dial_baselines;
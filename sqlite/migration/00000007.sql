@@ -0,0 +1,20 @@
+-- Rolling baseline of each dial's values used to detect anomalies.
+CREATE TABLE dial_baselines (
+	dial_id    INTEGER PRIMARY KEY REFERENCES dials (id) ON DELETE CASCADE,
+	mean       REAL NOT NULL,
+	variance   REAL NOT NULL,
+	n          INTEGER NOT NULL,
+	updated_at TEXT NOT NULL
+);
+
+CREATE TABLE dial_anomalies (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	dial_id     INTEGER NOT NULL REFERENCES dials (id) ON DELETE CASCADE,
+	value       INTEGER NOT NULL,
+	baseline    REAL NOT NULL,
+	stddev      REAL NOT NULL,
+	score       REAL NOT NULL,
+	"timestamp" TEXT NOT NULL
+);
+
+CREATE INDEX dial_anomalies_dial_id_timestamp_idx ON dial_anomalies (dial_id, "timestamp");
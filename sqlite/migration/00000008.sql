@@ -0,0 +1,5 @@
+-- Public read-only share links. Dials without a token are not shared.
+ALTER TABLE dials ADD COLUMN share_token TEXT;
+ALTER TABLE dials ADD COLUMN share_members INTEGER NOT NULL DEFAULT 0;
+
+CREATE UNIQUE INDEX dials_share_token_idx ON dials (share_token);
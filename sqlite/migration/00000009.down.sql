@@ -0,0 +1,25 @@
+-- SQLite cannot drop columns so the table is rebuilt without them. Foreign
+-- keys must be disabled so that dropping the old table does not cascade.
+CREATE TABLE dials_new (
+	id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id       INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	name          TEXT NOT NULL,
+	invite_code   TEXT UNIQUE NOT NULL,
+	value         INTEGER NOT NULL DEFAULT 0,
+	created_at    TEXT NOT NULL,
+	updated_at    TEXT NOT NULL,
+	version       INTEGER NOT NULL DEFAULT 1,
+	share_token   TEXT,
+	share_members INTEGER NOT NULL DEFAULT 0
+);
+
+INSERT INTO dials_new (id, user_id, name, invite_code, value, created_at, updated_at, version, share_token, share_members)
+SELECT id, user_id, name, invite_code, value, created_at, updated_at, version, share_token, share_members FROM dials;
+
+DROP TABLE dials;
+ALTER TABLE dials_new RENAME TO dials;
+CREATE INDEX dials_user_id_idx ON dials (user_id);
+CREATE INDEX dials_name_idx ON dials (name COLLATE NOCASE);
+CREATE INDEX dials_value_idx ON dials (value);
+CREATE INDEX dials_updated_at_idx ON dials (updated_at);
+CREATE UNIQUE INDEX dials_share_token_idx ON dials (share_token);
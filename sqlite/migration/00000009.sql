@@ -0,0 +1,4 @@
+-- Embeddable status badges. Dials without a token have no badge.
+ALTER TABLE dials ADD COLUMN badge_token TEXT;
+
+CREATE UNIQUE INDEX dials_badge_token_idx ON dials (badge_token);
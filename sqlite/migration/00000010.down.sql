@@ -0,0 +1,10 @@
+-- SQLite cannot drop columns so the table is recreated. Baselines are seeded
+-- again from history so their rows do not need to be copied.
+DROP TABLE dial_baselines;
+CREATE TABLE dial_baselines (
+	dial_id    INTEGER PRIMARY KEY REFERENCES dials (id) ON DELETE CASCADE,
+	mean       REAL NOT NULL,
+	variance   REAL NOT NULL,
+	n          INTEGER NOT NULL,
+	updated_at TEXT NOT NULL
+);
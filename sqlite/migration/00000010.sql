@@ -0,0 +1,7 @@
+-- The value of the current minute is held back from the baseline until the
+-- next minute starts so that several changes within a minute are only
+-- observed once. Existing baselines include the latest value so they are
+-- removed & seeded again from history.
+DELETE FROM dial_baselines;
+ALTER TABLE dial_baselines ADD COLUMN pending_value INTEGER;
+ALTER TABLE dial_baselines ADD COLUMN pending_timestamp TEXT;
@@ -0,0 +1,15 @@
+-- SQLite cannot drop columns so the table is recreated. Device authorizations
+-- expire within minutes so their rows do not need to be copied.
+DROP TABLE device_authorizations;
+CREATE TABLE device_authorizations (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	device_code TEXT NOT NULL UNIQUE,
+	user_code   TEXT NOT NULL UNIQUE,
+	user_id     INTEGER REFERENCES users (id) ON DELETE CASCADE,
+	expires_at  TEXT NOT NULL,
+	created_at  TEXT NOT NULL,
+	updated_at  TEXT NOT NULL
+);
+
+DROP INDEX devices_user_id_idx;
+DROP TABLE devices;
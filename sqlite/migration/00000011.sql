@@ -0,0 +1,14 @@
+-- Devices approved through a device authorization. Each device has its own
+-- token so it can be revoked on its own. Only the SHA-256 hash of the token
+-- is stored.
+CREATE TABLE devices (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id    INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	name       TEXT NOT NULL,
+	token_hash TEXT NOT NULL UNIQUE,
+	created_at TEXT NOT NULL
+);
+
+CREATE INDEX devices_user_id_idx ON devices (user_id);
+
+ALTER TABLE device_authorizations ADD COLUMN device_name TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+DROP TABLE dial_value_members_hourly;
+DROP TABLE dial_value_members;
@@ -0,0 +1,21 @@
+-- Members who set their value on a dial, per minute & per hour. These are
+-- used to count the members contributing to each report slot. Minute rows
+-- are removed along with raw values while hourly rows are retained. History
+-- recorded before this migration has no members.
+--
+-- The user ID is not a foreign key so history is kept if the user is removed.
+CREATE TABLE dial_value_members (
+	dial_id      INTEGER NOT NULL REFERENCES dials (id) ON DELETE CASCADE,
+	user_id      INTEGER NOT NULL,
+	"timestamp"  TEXT NOT NULL, -- per-minute precision
+
+	PRIMARY KEY (dial_id, "timestamp", user_id)
+);
+
+CREATE TABLE dial_value_members_hourly (
+	dial_id      INTEGER NOT NULL REFERENCES dials (id) ON DELETE CASCADE,
+	user_id      INTEGER NOT NULL,
+	"timestamp"  TEXT NOT NULL, -- start of hour
+
+	PRIMARY KEY (dial_id, "timestamp", user_id)
+);
@@ -6,11 +6,10 @@ import (
 	"database/sql/driver"
 	"embed"
 	"fmt"
-	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
-	"sort"
+	"strings"
 	"time"
 
 	"github.com/benbjohnson/wtf"
@@ -52,6 +51,14 @@ type DB struct {
 	// Destination for events to be published.
 	EventService wtf.EventService
 
+	// Duration to keep raw per-minute dial values. Older values are removed
+	// and reports fall back to hourly & daily rollups. Zero keeps values forever.
+	RawRetention time.Duration
+
+	// Scores dial values against their history as they change. Anomalous
+	// values are stored & published to dial members. Nil disables detection.
+	AnomalyDetector *wtf.AnomalyDetector
+
 	// Returns the current time. Defaults to time.Now().
 	// Can be mocked for tests.
 	Now func() time.Time
@@ -63,7 +70,8 @@ func NewDB(dsn string) *DB {
 		DSN: dsn,
 		Now: time.Now,
 
-		EventService: wtf.NopEventService(),
+		AnomalyDetector: wtf.NewAnomalyDetector(),
+		EventService:    wtf.NopEventService(),
 	}
 	db.ctx, db.cancel = context.WithCancel(context.Background())
 	return db
@@ -109,72 +117,14 @@ func (db *DB) Open() (err error) {
 	// Monitor stats in background goroutine.
 	go db.monitor()
 
-	return nil
-}
-
-// migrate sets up migration tracking and executes pending migration files.
-//
-// Migration files are embedded in the sqlite/migration folder and are executed
-// in lexigraphical order.
-//
-// Once a migration is run, its name is stored in the 'migrations' table so it
-// is not re-executed. Migrations run in a transaction to prevent partial
-// migrations.
-func (db *DB) migrate() error {
-	// Ensure the 'migrations' table exists so we don't duplicate migrations.
-	if _, err := db.db.Exec(`CREATE TABLE IF NOT EXISTS migrations (name TEXT PRIMARY KEY);`); err != nil {
-		return fmt.Errorf("cannot create migrations table: %w", err)
-	}
-
-	// Read migration files from our embedded file system.
-	// This uses Go 1.16's 'embed' package.
-	names, err := fs.Glob(migrationFS, "migration/*.sql")
-	if err != nil {
-		return err
+	// Remove expired raw dial values in a background goroutine, if enabled.
+	if db.RawRetention > 0 {
+		go db.monitorRetention()
 	}
-	sort.Strings(names)
 
-	// Loop over all migration files and execute them in order.
-	for _, name := range names {
-		if err := db.migrateFile(name); err != nil {
-			return fmt.Errorf("migration error: name=%q err=%w", name, err)
-		}
-	}
 	return nil
 }
 
-// migrate runs a single migration file within a transaction. On success, the
-// migration file name is saved to the "migrations" table to prevent re-running.
-func (db *DB) migrateFile(name string) error {
-	tx, err := db.db.Begin()
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-
-	// Ensure migration has not already been run.
-	var n int
-	if err := tx.QueryRow(`SELECT COUNT(*) FROM migrations WHERE name = ?`, name).Scan(&n); err != nil {
-		return err
-	} else if n != 0 {
-		return nil // already run migration, skip
-	}
-
-	// Read and execute migration file.
-	if buf, err := fs.ReadFile(migrationFS, name); err != nil {
-		return err
-	} else if _, err := tx.Exec(string(buf)); err != nil {
-		return err
-	}
-
-	// Insert record into migrations to prevent re-running migration.
-	if _, err := tx.Exec(`INSERT INTO migrations (name) VALUES (?)`, name); err != nil {
-		return err
-	}
-
-	return tx.Commit()
-}
-
 // Close closes the database connection.
 func (db *DB) Close() error {
 	// Cancel background context.
@@ -254,6 +204,27 @@ type Tx struct {
 	*sql.Tx
 	db  *DB
 	now time.Time
+
+	// Functions to run once the transaction has been committed.
+	committed []func()
+}
+
+// Commit commits the transaction & then runs the functions registered with
+// afterCommit. They are discarded if the commit fails or is rolled back.
+func (tx *Tx) Commit() error {
+	if err := tx.Tx.Commit(); err != nil {
+		return err
+	}
+	for _, fn := range tx.committed {
+		fn()
+	}
+	return nil
+}
+
+// afterCommit registers fn to run once the transaction has been committed.
+// This is used to publish events only after their changes are visible.
+func (tx *Tx) afterCommit(fn func()) {
+	tx.committed = append(tx.committed, fn)
 }
 
 // lastInsertID is a helper function for reading the last inserted ID as an int.
@@ -286,6 +257,79 @@ func (n *NullTime) Value() (driver.Value, error) {
 	return (*time.Time)(n).UTC().Format(time.RFC3339), nil
 }
 
+// cursorQuery represents the ordering of a query paginated by cursor. Rows
+// are ordered by the sort columns followed by the "id" column so that the
+// order is stable even when sort values are equal.
+type cursorQuery struct {
+	columns []string // sort columns, excluding "id"
+	desc    bool     // if true, all columns are sorted in descending order
+
+	cursor *wtf.Cursor // position to page from, if any
+	before bool        // if true, page backward from the cursor
+}
+
+// newCursorQuery returns a query ordered by columns which pages from the
+// "after" or "before" cursor tokens of a filter. Returns EINVALID if both
+// cursors are set or if a cursor does not match the sort columns.
+func newCursorQuery(columns []string, desc bool, after, before string) (*cursorQuery, error) {
+	q := &cursorQuery{columns: columns, desc: desc}
+	if after != "" && before != "" {
+		return nil, wtf.Errorf(wtf.EINVALID, "Only one of after or before may be set.")
+	}
+
+	token := after
+	if before != "" {
+		token, q.before = before, true
+	}
+	if token == "" {
+		return q, nil
+	}
+
+	cursor, err := wtf.ParseCursor(token)
+	if err != nil {
+		return nil, err
+	} else if len(cursor.Keys) != len(columns) {
+		return nil, wtf.Errorf(wtf.EINVALID, "Invalid cursor.")
+	}
+	q.cursor = cursor
+	return q, nil
+}
+
+// where returns a WHERE clause segment & args which restrict rows to those
+// after the cursor. When paging backward, rows before the cursor are used.
+// The segment is always true if there is no cursor.
+func (q *cursorQuery) where() (string, []interface{}) {
+	if q.cursor == nil {
+		return "1 = 1", nil
+	}
+
+	columns := append(append([]string{}, q.columns...), "id")
+	args := append(append([]interface{}{}, q.cursor.Keys...), q.cursor.ID)
+
+	// Row values are compared in order so that later columns only break ties.
+	op := ">"
+	if q.desc != q.before {
+		op = "<"
+	}
+	return fmt.Sprintf("(%s) %s (%s)", strings.Join(columns, ", "), op, strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")), args
+}
+
+// orderBy returns the ORDER BY clause expressions. The order is reversed when
+// paging backward so that the limit applies to the rows closest to the
+// cursor. Results must be reversed by the caller to restore the order.
+func (q *cursorQuery) orderBy() string {
+	dir := "ASC"
+	if q.desc != q.before {
+		dir = "DESC"
+	}
+
+	a := make([]string, 0, len(q.columns)+1)
+	for _, column := range append(append([]string{}, q.columns...), "id") {
+		a = append(a, column+" "+dir)
+	}
+	return strings.Join(a, ", ")
+}
+
 // FormatLimitOffset returns a SQL string for a given limit & offset.
 // Clauses are only added if limit and/or offset are greater than zero.
 func FormatLimitOffset(limit, offset int) string {
@@ -299,6 +343,52 @@ func FormatLimitOffset(limit, offset int) string {
 	return ""
 }
 
+// MaxBatchSize is the maximum number of IDs passed to a single query when
+// loading associations in bulk. This keeps queries under SQLite's limit on
+// the number of bound parameters.
+const MaxBatchSize = 500
+
+// batchIDs calls fn with unique ids split into groups of at most MaxBatchSize.
+func batchIDs(ids []int, fn func(ids []int) error) error {
+	// Remove duplicate IDs so associations shared by many objects are only
+	// fetched once.
+	seen := make(map[int]struct{}, len(ids))
+	unique := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; !ok {
+			seen[id] = struct{}{}
+			unique = append(unique, id)
+		}
+	}
+
+	for len(unique) > 0 {
+		n := len(unique)
+		if n > MaxBatchSize {
+			n = MaxBatchSize
+		}
+		if err := fn(unique[:n]); err != nil {
+			return err
+		}
+		unique = unique[n:]
+	}
+	return nil
+}
+
+// formatInClause returns a parenthesized list of n placeholders for use with
+// an IN expression, e.g. "(?, ?, ?)".
+func formatInClause(n int) string {
+	return "(" + strings.TrimSuffix(strings.Repeat("?, ", n), ", ") + ")"
+}
+
+// intArgs converts a list of integers to query arguments.
+func intArgs(a []int) []interface{} {
+	args := make([]interface{}, len(a))
+	for i := range a {
+		args[i] = a[i]
+	}
+	return args
+}
+
 // FormatError returns err as a WTF error, if possible.
 // Otherwise returns the original error.
 func FormatError(err error) error {
@@ -309,6 +399,8 @@ func FormatError(err error) error {
 	switch err.Error() {
 	case "UNIQUE constraint failed: dial_memberships.dial_id, dial_memberships.user_id":
 		return wtf.Errorf(wtf.ECONFLICT, "Dial membership already exists.")
+	case "UNIQUE constraint failed: idempotency_keys.user_id, idempotency_keys.key":
+		return wtf.Errorf(wtf.ECONFLICT, "Idempotency key already exists.")
 	default:
 		return err
 	}
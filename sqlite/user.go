@@ -110,6 +110,27 @@ func (s *UserService) DeleteUser(ctx context.Context, id int) error {
 	return tx.Commit()
 }
 
+// ResetAPIKey replaces the user's API key with a newly generated key.
+// Returns EUNAUTHORIZED if current user is not the user being updated.
+// Returns ENOTFOUND if user does not exist.
+func (s *UserService) ResetAPIKey(ctx context.Context, id int) (*wtf.User, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	user, err := resetUserAPIKey(ctx, tx, id)
+	if err != nil {
+		return user, err
+	} else if err := attachUserAuths(ctx, tx, user); err != nil {
+		return user, err
+	} else if err := tx.Commit(); err != nil {
+		return user, err
+	}
+	return user, nil
+}
+
 // findUserByID is a helper function to fetch a user by ID.
 // Returns ENOTFOUND if user does not exist.
 func findUserByID(ctx context.Context, tx *Tx, id int) (*wtf.User, error) {
@@ -149,21 +170,57 @@ func findUsers(ctx context.Context, tx *Tx, filter wtf.UserFilter) (_ []*wtf.Use
 		where, args = append(where, "api_key = ?"), append(args, *v)
 	}
 
+	// Users are ordered by ID & paged from the filter's cursor, if any.
+	q, err := newCursorQuery(nil, false, filter.After, filter.Before)
+	if err != nil {
+		return nil, 0, err
+	}
+	return queryUsers(ctx, tx, where, args, q, filter.Limit, filter.Offset)
+}
+
+// findUsersByIDs returns a lookup of users by ID. Users are fetched in
+// batches so that associations for a list of objects can be attached with
+// a fixed number of queries. Missing users are not included in the map.
+func findUsersByIDs(ctx context.Context, tx *Tx, ids []int) (map[int]*wtf.User, error) {
+	m := make(map[int]*wtf.User, len(ids))
+	if err := batchIDs(ids, func(ids []int) error {
+		users, _, err := queryUsers(ctx, tx, []string{"id IN " + formatInClause(len(ids))}, intArgs(ids), &cursorQuery{}, 0, 0)
+		for _, user := range users {
+			m[user.ID] = user
+		}
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// queryUsers executes a query for users matching the WHERE clause segments.
+// Results are ordered & paged by q. The total count excludes the cursor so
+// that it is the same for every page.
+func queryUsers(ctx context.Context, tx *Tx, where []string, args []interface{}, q *cursorQuery, limit, offset int) (_ []*wtf.User, n int, err error) {
+	cursorWhere, cursorArgs := q.where()
+
 	// Execute query to fetch user rows.
 	rows, err := tx.QueryContext(ctx, `
-		SELECT 
+		SELECT
 		    id,
 		    name,
 		    email,
 		    api_key,
+		    time_zone,
 		    created_at,
 		    updated_at,
-		    COUNT(*) OVER()
-		FROM users
-		WHERE `+strings.Join(where, " AND ")+`
-		ORDER BY id ASC
-		`+FormatLimitOffset(filter.Limit, filter.Offset),
-		args...,
+		    n
+		FROM (
+		    SELECT *, COUNT(*) OVER() AS n
+		    FROM users
+		    WHERE `+strings.Join(where, " AND ")+`
+		)
+		WHERE `+cursorWhere+`
+		ORDER BY `+q.orderBy()+`
+		`+FormatLimitOffset(limit, offset),
+		append(args, cursorArgs...)...,
 	)
 	if err != nil {
 		return nil, n, err
@@ -180,6 +237,7 @@ func findUsers(ctx context.Context, tx *Tx, filter wtf.UserFilter) (_ []*wtf.Use
 			&user.Name,
 			&email,
 			&user.APIKey,
+			&user.TimeZone,
 			(*NullTime)(&user.CreatedAt),
 			(*NullTime)(&user.UpdatedAt),
 			&n,
@@ -197,6 +255,13 @@ func findUsers(ctx context.Context, tx *Tx, filter wtf.UserFilter) (_ []*wtf.Use
 		return nil, 0, err
 	}
 
+	// Restore the sort order if rows were fetched backward from a cursor.
+	if q.before {
+		for i, j := 0, len(users)-1; i < j; i, j = i+1, j-1 {
+			users[i], users[j] = users[j], users[i]
+		}
+	}
+
 	return users, n, nil
 }
 
@@ -220,11 +285,10 @@ func createUser(ctx context.Context, tx *Tx, user *wtf.User) error {
 	}
 
 	// Generate random API key.
-	apiKey := make([]byte, 32)
-	if _, err := io.ReadFull(rand.Reader, apiKey); err != nil {
+	var err error
+	if user.APIKey, err = generateAPIKey(); err != nil {
 		return err
 	}
-	user.APIKey = hex.EncodeToString(apiKey)
 
 	// Execute insertion query.
 	result, err := tx.ExecContext(ctx, `
@@ -232,14 +296,16 @@ func createUser(ctx context.Context, tx *Tx, user *wtf.User) error {
 			name,
 			email,
 			api_key,
+			time_zone,
 			created_at,
 			updated_at
 		)
-		VALUES (?, ?, ?, ?, ?)
+		VALUES (?, ?, ?, ?, ?, ?)
 	`,
 		user.Name,
 		email,
 		user.APIKey,
+		user.TimeZone,
 		(*NullTime)(&user.CreatedAt),
 		(*NullTime)(&user.UpdatedAt),
 	)
@@ -261,7 +327,7 @@ func updateUser(ctx context.Context, tx *Tx, id int, upd wtf.UserUpdate) (*wtf.U
 	user, err := findUserByID(ctx, tx, id)
 	if err != nil {
 		return user, err
-	} else if user.ID != wtf.UserIDFromContext(ctx) {
+	} else if user.ID != wtf.UserIDFromContext(ctx) && !wtf.IsAdminContext(ctx) {
 		return nil, wtf.Errorf(wtf.EUNAUTHORIZED, "You are not allowed to update this user.")
 	}
 
@@ -272,6 +338,9 @@ func updateUser(ctx context.Context, tx *Tx, id int, upd wtf.UserUpdate) (*wtf.U
 	if v := upd.Email; v != nil {
 		user.Email = *v
 	}
+	if v := upd.TimeZone; v != nil {
+		user.TimeZone = *v
+	}
 
 	// Set last updated date to current time.
 	user.UpdatedAt = tx.now
@@ -293,11 +362,13 @@ func updateUser(ctx context.Context, tx *Tx, id int, upd wtf.UserUpdate) (*wtf.U
 		UPDATE users
 		SET name = ?,
 		    email = ?,
+		    time_zone = ?,
 		    updated_at = ?
 		WHERE id = ?
 	`,
 		user.Name,
 		email,
+		user.TimeZone,
 		(*NullTime)(&user.UpdatedAt),
 		id,
 	); err != nil {
@@ -313,7 +384,7 @@ func deleteUser(ctx context.Context, tx *Tx, id int) error {
 	// Verify object exists.
 	if user, err := findUserByID(ctx, tx, id); err != nil {
 		return err
-	} else if user.ID != wtf.UserIDFromContext(ctx) {
+	} else if user.ID != wtf.UserIDFromContext(ctx) && !wtf.IsAdminContext(ctx) {
 		return wtf.Errorf(wtf.EUNAUTHORIZED, "You are not allowed to delete this user.")
 	}
 
@@ -324,6 +395,45 @@ func deleteUser(ctx context.Context, tx *Tx, id int) error {
 	return nil
 }
 
+// resetUserAPIKey generates a new API key for a user. Returns EUNAUTHORIZED if
+// current user is not the user being updated.
+func resetUserAPIKey(ctx context.Context, tx *Tx, id int) (*wtf.User, error) {
+	user, err := findUserByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	} else if user.ID != wtf.UserIDFromContext(ctx) && !wtf.IsAdminContext(ctx) {
+		return nil, wtf.Errorf(wtf.EUNAUTHORIZED, "You are not allowed to update this user.")
+	}
+
+	if user.APIKey, err = generateAPIKey(); err != nil {
+		return user, err
+	}
+	user.UpdatedAt = tx.now
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE users
+		SET api_key = ?,
+		    updated_at = ?
+		WHERE id = ?
+	`,
+		user.APIKey,
+		(*NullTime)(&user.UpdatedAt),
+		id,
+	); err != nil {
+		return user, FormatError(err)
+	}
+	return user, nil
+}
+
+// generateAPIKey returns a random hex-encoded API key.
+func generateAPIKey() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
 // attachUserAuths attaches OAuth objects associated with the user.
 func attachUserAuths(ctx context.Context, tx *Tx, user *wtf.User) (err error) {
 	if user.Auths, _, err = findAuths(ctx, tx, wtf.AuthFilter{UserID: &user.ID}); err != nil {
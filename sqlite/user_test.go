@@ -94,6 +94,30 @@ func TestUserService_UpdateUser(t *testing.T) {
 		}
 	})
 
+	// Ensure a user's time zone can be updated & must be a valid IANA name.
+	t.Run("TimeZone", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewUserService(db)
+		user0, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "susy"})
+
+		timeZone := "America/New_York"
+		if _, err := s.UpdateUser(ctx0, user0.ID, wtf.UserUpdate{TimeZone: &timeZone}); err != nil {
+			t.Fatal(err)
+		} else if other, err := s.FindUserByID(ctx0, user0.ID); err != nil {
+			t.Fatal(err)
+		} else if got, want := other.TimeZone, "America/New_York"; got != want {
+			t.Fatalf("TimeZone=%v, want %v", got, want)
+		} else if got, want := other.Location().String(), "America/New_York"; got != want {
+			t.Fatalf("Location=%v, want %v", got, want)
+		}
+
+		timeZone = "Mars/Olympus_Mons"
+		if _, err := s.UpdateUser(ctx0, user0.ID, wtf.UserUpdate{TimeZone: &timeZone}); wtf.ErrorCode(err) != wtf.EINVALID || wtf.ErrorMessage(err) != `Invalid time zone.` {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
 	// Ensure updating a user is restricted only to the current user.
 	t.Run("ErrUnauthorized", func(t *testing.T) {
 		db := MustOpenDB(t)
@@ -152,6 +176,67 @@ func TestUserService_DeleteUser(t *testing.T) {
 			t.Fatalf("unexpected error: %#v", err)
 		}
 	})
+
+	// Ensure an admin context can delete any user.
+	t.Run("Admin", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewUserService(db)
+		user0, _ := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "NAME0"})
+
+		if err := s.DeleteUser(wtf.NewContextWithAdmin(context.Background()), user0.ID); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestUserService_ResetAPIKey(t *testing.T) {
+	// Ensure user can replace their own API key.
+	t.Run("OK", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewUserService(db)
+		user0, ctx0 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "john"})
+		prev := user0.APIKey
+
+		if user, err := s.ResetAPIKey(ctx0, user0.ID); err != nil {
+			t.Fatal(err)
+		} else if user.APIKey == prev || len(user.APIKey) != 64 {
+			t.Fatalf("unexpected api key: %q", user.APIKey)
+		}
+
+		// Ensure the old key no longer authenticates.
+		if _, n, err := s.FindUsers(ctx0, wtf.UserFilter{APIKey: &prev}); err != nil {
+			t.Fatal(err)
+		} else if n != 0 {
+			t.Fatalf("expected old key to be removed")
+		}
+	})
+
+	// Ensure an admin context can reset any user's key.
+	t.Run("Admin", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewUserService(db)
+		user0, _ := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "NAME0"})
+
+		if _, err := s.ResetAPIKey(wtf.NewContextWithAdmin(context.Background()), user0.ID); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	// Ensure resetting a key is restricted only to the current user.
+	t.Run("ErrUnauthorized", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewUserService(db)
+		user0, _ := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "NAME0"})
+		_, ctx1 := MustCreateUser(t, context.Background(), db, &wtf.User{Name: "NAME1"})
+
+		if _, err := s.ResetAPIKey(ctx1, user0.ID); wtf.ErrorCode(err) != wtf.EUNAUTHORIZED {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
 }
 
 func TestUserService_FindUser(t *testing.T) {
@@ -190,6 +275,28 @@ func TestUserService_FindUsers(t *testing.T) {
 			t.Fatalf("n=%v, want %v", got, want)
 		}
 	})
+
+	// Ensure users can be paged using cursors.
+	t.Run("Cursor", func(t *testing.T) {
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewUserService(db)
+
+		ctx := context.Background()
+		MustCreateUser(t, ctx, db, &wtf.User{Name: "john"})
+		user1, _ := MustCreateUser(t, ctx, db, &wtf.User{Name: "jane"})
+		MustCreateUser(t, ctx, db, &wtf.User{Name: "frank"})
+
+		if a, n, err := s.FindUsers(ctx, wtf.UserFilter{After: wtf.Cursor{ID: user1.ID}.String(), Limit: 1}); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(a), 1; got != want {
+			t.Fatalf("len=%v, want %v", got, want)
+		} else if got, want := a[0].Name, "frank"; got != want {
+			t.Fatalf("name=%v, want %v", got, want)
+		} else if got, want := n, 3; got != want {
+			t.Fatalf("n=%v, want %v", got, want)
+		}
+	})
 }
 
 // MustCreateUser creates a user in the database. Fatal on error.
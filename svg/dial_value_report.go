@@ -0,0 +1,184 @@
+package svg
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/wtf"
+)
+
+// Chart defaults & limits.
+const (
+	DefaultChartWidth  = 600
+	DefaultChartHeight = 300
+
+	MinChartSize = 100
+	MaxChartSize = 2000
+)
+
+// Margins around the plot area, in pixels. The left & bottom margins leave
+// room for the axis labels & the top margin for the title.
+const (
+	marginTop    = 30
+	marginRight  = 10
+	marginBottom = 30
+	marginLeft   = 35
+)
+
+// xLabelN is the maximum number of labels drawn on the time axis.
+const xLabelN = 6
+
+// Band represents a range of WTF values drawn as a colored background band.
+type Band struct {
+	Min, Max int
+	Color    string
+}
+
+// Bands are the threshold bands drawn behind the chart. They match the
+// colors used by dial badges on the dashboard.
+var Bands = []Band{
+	{Min: 0, Max: 25, Color: "#00d27a"},
+	{Min: 25, Max: 50, Color: "#27bcfd"},
+	{Min: 50, Max: 75, Color: "#f5803e"},
+	{Min: 75, Max: 100, Color: "#e63757"},
+}
+
+// DialValueReportEncoder encodes a dial value report as an SVG line chart.
+// The chart does not require JavaScript so it can be embedded in emails &
+// wikis.
+type DialValueReportEncoder struct {
+	w *bufio.Writer
+
+	// Size of the image, in pixels.
+	Width  int
+	Height int
+
+	// Optional title drawn above the chart.
+	Title string
+
+	// Time zone used for the time axis labels. Defaults to UTC.
+	Location *time.Location
+}
+
+// NewDialValueReportEncoder returns a new instance of DialValueReportEncoder that writes to w.
+func NewDialValueReportEncoder(w io.Writer) *DialValueReportEncoder {
+	return &DialValueReportEncoder{
+		w:        bufio.NewWriter(w),
+		Width:    DefaultChartWidth,
+		Height:   DefaultChartHeight,
+		Location: time.UTC,
+	}
+}
+
+// EncodeDialValueReport writes report to the underlying writer as a complete
+// SVG document. Values are plotted on a fixed 0-100 scale.
+func (enc *DialValueReportEncoder) EncodeDialValueReport(report *wtf.DialValueReport) error {
+	w, h := enc.Width, enc.Height
+	fmt.Fprintf(enc.w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`, w, h, w, h)
+	fmt.Fprintf(enc.w, `<rect width="%d" height="%d" fill="#fff"/>`, w, h)
+	if enc.Title != "" {
+		fmt.Fprintf(enc.w, `<title>%s</title>`, html.EscapeString(enc.Title))
+		fmt.Fprintf(enc.w, `<text x="%d" y="%d" font-size="14" font-weight="bold" fill="#344050">%s</text>`, marginLeft, marginTop-12, html.EscapeString(enc.Title))
+	}
+
+	enc.writeBands()
+	enc.writeYAxis()
+	if len(report.Records) == 0 {
+		fmt.Fprintf(enc.w, `<text x="%g" y="%g" text-anchor="middle" fill="#748194">No data</text>`, enc.x(0, 1), enc.y(50))
+	} else {
+		enc.writeSeries(report.Records)
+		enc.writeXAxis(report.Records)
+	}
+
+	fmt.Fprint(enc.w, `</svg>`)
+	return enc.w.Flush()
+}
+
+// writeBands draws the threshold bands behind the plot area.
+func (enc *DialValueReportEncoder) writeBands() {
+	for _, band := range Bands {
+		fmt.Fprintf(enc.w, `<rect x="%d" y="%g" width="%g" height="%g" fill="%s" fill-opacity="0.08"/>`,
+			marginLeft, enc.y(band.Max), enc.plotWidth(), enc.y(band.Min)-enc.y(band.Max), band.Color)
+	}
+}
+
+// writeYAxis draws a grid line & label at each band boundary.
+func (enc *DialValueReportEncoder) writeYAxis() {
+	for _, v := range []int{0, 25, 50, 75, 100} {
+		y := enc.y(v)
+		fmt.Fprintf(enc.w, `<line x1="%d" y1="%g" x2="%g" y2="%g" stroke="#d8e2ef" stroke-width="1"/>`, marginLeft, y, float64(marginLeft)+enc.plotWidth(), y)
+		fmt.Fprintf(enc.w, `<text x="%d" y="%g" text-anchor="end" fill="#748194">%d</text>`, marginLeft-5, y+4, v)
+	}
+}
+
+// writeSeries draws the shaded area under the values & the value line.
+func (enc *DialValueReportEncoder) writeSeries(records []*wtf.DialValueRecord) {
+	points := make([]string, len(records))
+	for i, record := range records {
+		points[i] = fmt.Sprintf("%g,%g", enc.x(i, len(records)), enc.y(record.Value))
+	}
+
+	// Close the area along the bottom of the plot.
+	area := append([]string{fmt.Sprintf("%g,%g", enc.x(0, len(records)), enc.y(0))}, points...)
+	area = append(area, fmt.Sprintf("%g,%g", enc.x(len(records)-1, len(records)), enc.y(0)))
+
+	fmt.Fprintf(enc.w, `<polygon points="%s" fill="#2c7be5" fill-opacity="0.15"/>`, strings.Join(area, " "))
+	fmt.Fprintf(enc.w, `<polyline points="%s" fill="none" stroke="#2c7be5" stroke-width="2" stroke-linejoin="round"/>`, strings.Join(points, " "))
+}
+
+// writeXAxis draws evenly spaced time labels below the plot area.
+func (enc *DialValueReportEncoder) writeXAxis(records []*wtf.DialValueRecord) {
+	layout := timeLayout(records[0].Timestamp, records[len(records)-1].Timestamp)
+	step := (len(records) + xLabelN - 1) / xLabelN
+	for i := 0; i < len(records); i += step {
+		label := records[i].Timestamp.In(enc.location()).Format(layout)
+		fmt.Fprintf(enc.w, `<text x="%g" y="%d" text-anchor="middle" fill="#748194">%s</text>`, enc.x(i, len(records)), enc.Height-marginBottom+18, label)
+	}
+}
+
+// x returns the horizontal position of the i-th of n points. A single point
+// is centered in the plot area.
+func (enc *DialValueReportEncoder) x(i, n int) float64 {
+	if n <= 1 {
+		return float64(marginLeft) + enc.plotWidth()/2
+	}
+	return float64(marginLeft) + enc.plotWidth()*float64(i)/float64(n-1)
+}
+
+// y returns the vertical position of a WTF value.
+func (enc *DialValueReportEncoder) y(value int) float64 {
+	height := float64(enc.Height - marginTop - marginBottom)
+	return float64(marginTop) + height*float64(100-value)/100
+}
+
+// plotWidth returns the width of the plot area.
+func (enc *DialValueReportEncoder) plotWidth() float64 {
+	return float64(enc.Width - marginLeft - marginRight)
+}
+
+// location returns the time zone for labels.
+func (enc *DialValueReportEncoder) location() *time.Location {
+	if enc.Location == nil {
+		return time.UTC
+	}
+	return enc.Location
+}
+
+// timeLayout returns the label format for a report spanning start to end.
+// Short reports show the time of day & longer reports show the date.
+func timeLayout(start, end time.Time) string {
+	switch span := end.Sub(start); {
+	case span <= 24*time.Hour:
+		return "15:04"
+	case span <= 7*24*time.Hour:
+		return "Jan 2 15:04"
+	case span <= 180*24*time.Hour:
+		return "Jan 2"
+	default:
+		return "Jan 2006"
+	}
+}
@@ -17,6 +17,10 @@ type User struct {
 	// Randomly generated API key for use with the CLI.
 	APIKey string `json:"-"`
 
+	// IANA time zone name, such as "America/New_York". Used to align reports
+	// to the user's calendar days. Blank is treated as UTC.
+	TimeZone string `json:"timeZone"`
+
 	// Timestamps for user creation & last update.
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
@@ -31,10 +35,22 @@ type User struct {
 func (u *User) Validate() error {
 	if u.Name == "" {
 		return Errorf(EINVALID, "User name required.")
+	} else if _, err := time.LoadLocation(u.TimeZone); err != nil {
+		return Errorf(EINVALID, "Invalid time zone.")
 	}
 	return nil
 }
 
+// Location returns the location for the user's time zone. Returns UTC if the
+// time zone is blank or cannot be loaded.
+func (u *User) Location() *time.Location {
+	loc, err := time.LoadLocation(u.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
 // AvatarURL returns a URL to the avatar image for the user.
 // This loops over all auth providers to find the first available avatar.
 // Currently only GitHub is supported. Returns blank string if no avatar URL available.
@@ -69,6 +85,12 @@ type UserService interface {
 	// if current user is not the user being deleted. Returns ENOTFOUND if
 	// user does not exist.
 	DeleteUser(ctx context.Context, id int) error
+
+	// Replaces the user's API key with a newly generated key. Any clients
+	// using the previous key will no longer be authenticated. Returns
+	// EUNAUTHORIZED if current user is not the user being updated. Returns
+	// ENOTFOUND if user does not exist.
+	ResetAPIKey(ctx context.Context, id int) (*User, error)
 }
 
 // UserFilter represents a filter passed to FindUsers().
@@ -81,10 +103,16 @@ type UserFilter struct {
 	// Restrict to subset of results.
 	Offset int `json:"offset"`
 	Limit  int `json:"limit"`
+
+	// Restrict to results after or before an opaque cursor returned from a
+	// previous page. Only one may be set.
+	After  string `json:"after"`
+	Before string `json:"before"`
 }
 
 // UserUpdate represents a set of fields to be updated via UpdateUser().
 type UserUpdate struct {
-	Name  *string `json:"name"`
-	Email *string `json:"email"`
+	Name     *string `json:"name"`
+	Email    *string `json:"email"`
+	TimeZone *string `json:"timeZone"`
 }